@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	collectCount    int
+	collectInterval time.Duration
+	collectFormat   string
+)
+
+var collectCmd = &cobra.Command{
+	Use:   "collect [collector...]",
+	Short: "Run collectors once and print their metrics",
+	Long: `Initialize the named collectors (or every configured collector) from the
+configuration, run them once or --count times, and print the metrics without
+connecting to the backend. Rate metrics need at least two runs.`,
+	RunE: runCollect,
+}
+
+// collectFormatters write one round of metrics in each supported output format
+var collectFormatters = map[string]func(io.Writer, []metrics.Metric) error{
+	"table":      writeMetricsTable,
+	"json":       writeMetricsJSON,
+	"prometheus": writeMetricsPrometheus,
+}
+
+func runCollect(cmd *cobra.Command, args []string) error {
+	format, exists := collectFormatters[collectFormat]
+	if !exists {
+		return fmt.Errorf("unknown output format %q (table, json or prometheus)", collectFormat)
+	}
+	if collectCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+
+	configManager := config.NewManager()
+	if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
+	if err := configManager.Load(); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := configManager.GetConfig()
+	applyFlagOverrides(cfg)
+
+	// Logs go to stderr so stdout only carries metrics; collector startup
+	// chatter is hidden unless --log-level asks for it
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.WarnLevel)
+	logger.AddHook(configManager.LogHook())
+	if logLevel != "" {
+		level, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log level %q: %w", logLevel, err)
+		}
+		logger.SetLevel(level)
+	}
+
+	interval := collectInterval
+	if interval <= 0 {
+		interval = cfg.CollectInterval
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.Collectors
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Events reported between collections are printed with the next round
+	var eventsMutex sync.Mutex
+	var events []metrics.Metric
+	sink := func(metric metrics.Metric) {
+		eventsMutex.Lock()
+		events = append(events, metric)
+		eventsMutex.Unlock()
+	}
+
+	var running []metrics.MetricCollector
+	for _, name := range names {
+		collector, err := agent.NewCollector(name, cfg, logger)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			continue
+		}
+		if source, ok := collector.(metrics.EventSource); ok {
+			source.SetEventSink(sink)
+		}
+		if err := collector.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to start %s collector: %v\n", name, err)
+			continue
+		}
+		running = append(running, collector)
+	}
+	defer func() {
+		for _, collector := range running {
+			collector.Stop()
+		}
+	}()
+	if len(running) == 0 {
+		return fmt.Errorf("no collectors could be started")
+	}
+
+	failed := false
+	for round := 0; round < collectCount; round++ {
+		if round > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+
+		var collected []metrics.Metric
+		for _, collector := range running {
+			start := time.Now()
+			result, err := collector.Collect(ctx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  %s collector failed after %s: %v\n", collector.Name(), time.Since(start).Round(time.Millisecond), err)
+				failed = true
+				continue
+			}
+			collected = append(collected, result...)
+		}
+
+		eventsMutex.Lock()
+		collected = append(collected, events...)
+		events = nil
+		eventsMutex.Unlock()
+
+		if err := format(os.Stdout, collected); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("one or more collectors failed")
+	}
+	return nil
+}
+
+// writeMetricsTable prints metrics as an aligned table with sorted tags
+func writeMetricsTable(w io.Writer, collected []metrics.Metric) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "METRIC\tVALUE\tUNIT\tTYPE\tTAGS")
+	for _, metric := range collected {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n",
+			metric.Name,
+			strconv.FormatFloat(metric.Value, 'g', -1, 64),
+			metric.Unit,
+			metric.Type,
+			formatTags(metric.Tags, "=", ","),
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// writeMetricsJSON prints one JSON object per metric
+func writeMetricsJSON(w io.Writer, collected []metrics.Metric) error {
+	encoder := json.NewEncoder(w)
+	for _, metric := range collected {
+		if err := encoder.Encode(metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMetricsPrometheus prints metrics in the Prometheus text exposition format
+func writeMetricsPrometheus(w io.Writer, collected []metrics.Metric) error {
+	// Samples of one metric must be grouped under a single TYPE line
+	byName := make(map[string][]metrics.Metric)
+	var names []string
+	for _, metric := range collected {
+		name := prometheusName(metric.Name)
+		if _, seen := byName[name]; !seen {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], metric)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		samples := byName[name]
+		metricType := "gauge"
+		if samples[0].Type == metrics.MetricTypeCounter {
+			metricType = "counter"
+		}
+		if samples[0].Unit != "" {
+			fmt.Fprintf(w, "# HELP %s Unit: %s\n", name, samples[0].Unit)
+		}
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+
+		for _, metric := range samples {
+			labels := make(map[string]string, len(metric.Tags))
+			for key, value := range metric.Tags {
+				labels[prometheusName(key)] = prometheusLabelValue(value)
+			}
+			sample := name
+			if len(labels) > 0 {
+				sample += "{" + formatTags(labels, "=\"", "\",") + "\"}"
+			}
+			if _, err := fmt.Fprintf(w, "%s %s %d\n", sample, strconv.FormatFloat(metric.Value, 'g', -1, 64), metric.Timestamp.UnixMilli()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatTags joins tags sorted by key as key<assign>value<separator>...
+func formatTags(tags map[string]string, assign, separator string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + assign + tags[key]
+	}
+	return strings.Join(pairs, separator)
+}
+
+// prometheusName replaces characters not allowed in Prometheus metric and label names
+func prometheusName(name string) string {
+	sanitized := []rune(name)
+	for i, r := range sanitized {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9'
+		if !valid {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
+
+// prometheusLabelValue escapes a label value for the text exposition format
+func prometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
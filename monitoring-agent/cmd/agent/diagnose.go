@@ -0,0 +1,549 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/control"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/transmitter"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diagnoseOutput  string
+	diagnoseTimeout time.Duration
+)
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Write a support bundle for troubleshooting",
+	Long: `Collect everything needed to troubleshoot an agent into a .tar.gz bundle:
+the effective configuration with credentials redacted, the running agent's
+status and recent logs, a one-shot run of every configured collector, the
+host's interfaces, routes and DNS resolver settings, the detected cloud
+location and connectivity tests to the backend. Sections that fail are
+recorded in summary.txt rather than aborting the bundle.`,
+	Args: cobra.NoArgs,
+	RunE: runDiagnose,
+}
+
+// diagnoseBundle writes files into the support bundle under a common directory
+type diagnoseBundle struct {
+	tar     *tar.Writer
+	dir     string
+	created time.Time
+	summary []string
+	// redact removes secrets from everything written into the bundle
+	redact func(string) string
+}
+
+// add writes one file into the bundle with secrets redacted
+func (b *diagnoseBundle) add(name string, content []byte) error {
+	content = []byte(b.redact(string(content)))
+	header := &tar.Header{
+		Name:    b.dir + "/" + name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: b.created,
+	}
+	if err := b.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := b.tar.Write(content)
+	return err
+}
+
+// section runs collect and writes its output as name, recording the outcome
+// in the summary; output gathered before an error is kept
+func (b *diagnoseBundle) section(name string, collect func() ([]byte, error)) error {
+	start := time.Now()
+	content, err := collect()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	outcome := fmt.Sprintf("%-32s ok (%s)", name, elapsed)
+	if err != nil {
+		outcome = b.redact(fmt.Sprintf("%-32s FAILED (%s): %v", name, elapsed, err))
+		content = append(content, []byte(fmt.Sprintf("\nerror: %v\n", err))...)
+	}
+	b.summary = append(b.summary, outcome)
+	fmt.Fprintln(os.Stderr, outcome)
+
+	return b.add(name, content)
+}
+
+func runDiagnose(cmd *cobra.Command, args []string) error {
+	created := time.Now()
+	hostname, _ := os.Hostname()
+	output := diagnoseOutput
+	if output == "" {
+		output = fmt.Sprintf("network-monitor-diagnose-%s-%s.tar.gz", hostname, created.Format("20060102-150405"))
+	}
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer file.Close()
+	configManager := config.NewManager()
+	if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
+	loadErr := configManager.Load()
+
+	compressed := gzip.NewWriter(file)
+	bundle := &diagnoseBundle{
+		tar:     tar.NewWriter(compressed),
+		dir:     strings.TrimSuffix(filepath.Base(output), ".tar.gz"),
+		created: created,
+		// URLs are redacted before secret values are swapped for their
+		// references, which could otherwise break a URL apart
+		redact: func(text string) string {
+			return configManager.RedactString(config.RedactText(text))
+		},
+	}
+	cfg := configManager.GetConfig()
+	if cfg != nil {
+		applyFlagOverrides(cfg)
+	}
+
+	// Collector logs are kept for the bundle
+	var diagnoseLog bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&diagnoseLog)
+	logger.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	logger.SetLevel(logrus.InfoLevel)
+	logger.AddHook(configManager.LogHook())
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sections := []struct {
+		name    string
+		collect func() ([]byte, error)
+	}{
+		{"config/effective.yaml", func() ([]byte, error) { return diagnoseEffectiveConfig(configManager, cfg, loadErr) }},
+		{"config/validation.txt", diagnoseValidation},
+		{"agent/status.json", func() ([]byte, error) { return diagnoseStatus(ctx, cfg) }},
+		{"agent/logs.txt", func() ([]byte, error) { return diagnoseLogs(ctx, cfg) }},
+		{"system/interfaces.txt", diagnoseInterfaces},
+		{"system/routes.txt", func() ([]byte, error) { return diagnoseRoutes(ctx, cfg) }},
+		{"system/resolver.txt", func() ([]byte, error) { return diagnoseResolver(ctx) }},
+		{"system/location.json", func() ([]byte, error) { return diagnoseLocation(cfg, loadErr) }},
+		{"connectivity.txt", func() ([]byte, error) { return diagnoseConnectivity(ctx, configManager, cfg, loadErr) }},
+	}
+	for _, section := range sections {
+		if err := bundle.section(section.name, section.collect); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if cfg != nil {
+		for _, name := range cfg.Collectors {
+			name := name
+			collect := func() ([]byte, error) { return diagnoseCollector(ctx, name, cfg, logger) }
+			if err := bundle.section("collectors/"+name+".txt", collect); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+		}
+	}
+
+	if err := bundle.add("diagnose.log", diagnoseLog.Bytes()); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := bundle.add("summary.txt", diagnoseSummary(bundle, hostname, loadErr)); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if err := bundle.tar.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := compressed.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("📦 Support bundle written to %s\n", output)
+	return nil
+}
+
+// diagnoseSummary describes the host, the agent build and the outcome of every section
+func diagnoseSummary(bundle *diagnoseBundle, hostname string, loadErr error) []byte {
+	var summary bytes.Buffer
+	fmt.Fprintf(&summary, "Network Monitor Agent v%s support bundle\n", version)
+	fmt.Fprintf(&summary, "Created:      %s\n", bundle.created.Format(time.RFC3339))
+	fmt.Fprintf(&summary, "Hostname:     %s\n", hostname)
+	fmt.Fprintf(&summary, "Platform:     %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	if configFile != "" {
+		fmt.Fprintf(&summary, "Config file:  %s\n", configFile)
+	}
+	if loadErr != nil {
+		fmt.Fprintf(&summary, "Config error: %v\n", loadErr)
+	}
+	fmt.Fprintln(&summary)
+	fmt.Fprintln(&summary, "Sections:")
+	for _, line := range bundle.summary {
+		fmt.Fprintf(&summary, "  %s\n", line)
+	}
+	return summary.Bytes()
+}
+
+// diagnoseEffectiveConfig renders the loaded configuration with credentials redacted
+func diagnoseEffectiveConfig(configManager *config.Manager, cfg *metrics.AgentConfig, loadErr error) ([]byte, error) {
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	// Credentials go first, while URLs still hold the values they were
+	// resolved to rather than references that no longer parse
+	redactedConfig, err := config.Redact(cfg)
+	if err != nil {
+		return nil, err
+	}
+	referencedConfig, err := configManager.RedactSecrets(redactedConfig)
+	if err != nil {
+		return nil, err
+	}
+	return config.MarshalConfig(referencedConfig)
+}
+
+// diagnoseValidation runs validate-config on the configuration file
+func diagnoseValidation() ([]byte, error) {
+	configManager := config.NewManager()
+	if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
+	result, err := configManager.Validate(agent.CollectorNames())
+	if err != nil {
+		return nil, err
+	}
+
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "File: %s\n", result.File)
+	if len(result.Problems) == 0 {
+		fmt.Fprintln(&report, "No problems found")
+	}
+	for _, problem := range result.Problems {
+		fmt.Fprintf(&report, "line %d, column %d: %s: %s\n", problem.Line, problem.Column, problem.Path, problem.Message)
+	}
+	return report.Bytes(), nil
+}
+
+// diagnoseControlClient returns a client for the running agent's control API
+func diagnoseControlClient(cfg *metrics.AgentConfig) (*control.Client, error) {
+	address, token := controlAddress, ""
+	if cfg != nil {
+		token = cfg.Control.Token
+		if address == "" {
+			address = cfg.Control.Address
+		}
+	}
+	if address == "" {
+		return nil, fmt.Errorf("no control address, pass --address")
+	}
+	return control.NewClient(address, token, diagnoseTimeout), nil
+}
+
+// diagnoseStatus fetches the live status of the running agent
+func diagnoseStatus(ctx context.Context, cfg *metrics.AgentConfig) ([]byte, error) {
+	client, err := diagnoseControlClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	status, err := client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("agent not reachable: %w", err)
+	}
+	return json.MarshalIndent(status, "", "  ")
+}
+
+// diagnoseLogs fetches the recent log lines of the running agent
+func diagnoseLogs(ctx context.Context, cfg *metrics.AgentConfig) ([]byte, error) {
+	client, err := diagnoseControlClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := client.Logs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("agent not reachable: %w", err)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// diagnoseInterfaces lists the network interfaces with their flags and addresses
+func diagnoseInterfaces() ([]byte, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var report bytes.Buffer
+	for _, iface := range interfaces {
+		fmt.Fprintf(&report, "%d: %s mtu %d flags %s", iface.Index, iface.Name, iface.MTU, iface.Flags)
+		if len(iface.HardwareAddr) > 0 {
+			fmt.Fprintf(&report, " hwaddr %s", iface.HardwareAddr)
+		}
+		fmt.Fprintln(&report)
+
+		addresses, err := iface.Addrs()
+		if err != nil {
+			fmt.Fprintf(&report, "    addresses unavailable: %v\n", err)
+			continue
+		}
+		for _, address := range addresses {
+			fmt.Fprintf(&report, "    %s\n", address)
+		}
+	}
+	return report.Bytes(), nil
+}
+
+// diagnoseRoutes captures the routing tables with the platform's tools,
+// falling back to procfs on Linux hosts without iproute2
+func diagnoseRoutes(ctx context.Context, cfg *metrics.AgentConfig) ([]byte, error) {
+	var commands [][]string
+	switch runtime.GOOS {
+	case "linux":
+		commands = [][]string{{"ip", "-4", "route", "show", "table", "all"}, {"ip", "-6", "route", "show", "table", "all"}, {"ip", "neigh", "show"}}
+	case "windows":
+		commands = [][]string{{"route", "print"}}
+	default:
+		commands = [][]string{{"netstat", "-rn"}}
+	}
+
+	report, err := diagnoseCommands(ctx, commands)
+	if err != nil && runtime.GOOS == "linux" {
+		procRoot := "/proc"
+		if cfg != nil {
+			procRoot = cfg.ProcRoot
+		}
+		return diagnoseFiles(filepath.Join(procRoot, "net/route"), filepath.Join(procRoot, "net/ipv6_route"), filepath.Join(procRoot, "net/arp"))
+	}
+	return report, err
+}
+
+// diagnoseResolver captures the DNS resolver configuration
+func diagnoseResolver(ctx context.Context) ([]byte, error) {
+	if runtime.GOOS == "windows" {
+		return diagnoseCommands(ctx, [][]string{{"ipconfig", "/all"}})
+	}
+	return diagnoseFiles("/etc/resolv.conf", "/etc/hosts", "/etc/nsswitch.conf")
+}
+
+// diagnoseLocation reports the cloud location detected or configured at load time
+func diagnoseLocation(cfg *metrics.AgentConfig, loadErr error) ([]byte, error) {
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return json.MarshalIndent(cfg.Location, "", "  ")
+}
+
+// diagnoseConnectivity tests each step of reaching the backend: name
+// resolution, TCP connection, TLS handshake and an HTTP request
+func diagnoseConnectivity(ctx context.Context, configManager *config.Manager, cfg *metrics.AgentConfig, loadErr error) ([]byte, error) {
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	var report bytes.Buffer
+	backend, err := url.Parse(cfg.BackendURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend URL: %w", err)
+	}
+	fmt.Fprintf(&report, "Backend URL: %s\n", cfg.BackendURL)
+	for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"} {
+		if value := os.Getenv(name); value != "" {
+			fmt.Fprintf(&report, "%s: %s\n", name, value)
+		}
+	}
+
+	secure := backend.Scheme == "wss" || backend.Scheme == "https"
+	host, port := backend.Hostname(), backend.Port()
+	if port == "" {
+		port = "80"
+		if secure {
+			port = "443"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+	defer cancel()
+
+	start := time.Now()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		fmt.Fprintf(&report, "DNS:  %s failed after %s: %v\n", host, time.Since(start).Round(time.Millisecond), err)
+		return report.Bytes(), fmt.Errorf("backend host does not resolve")
+	}
+	fmt.Fprintf(&report, "DNS:  %s -> %s (%s)\n", host, strings.Join(addresses, ", "), time.Since(start).Round(time.Millisecond))
+
+	reachable := false
+	dialer := &net.Dialer{}
+	for _, address := range addresses {
+		target := net.JoinHostPort(address, port)
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			fmt.Fprintf(&report, "TCP:  %s failed after %s: %v\n", target, time.Since(start).Round(time.Millisecond), err)
+			continue
+		}
+		conn.Close()
+		reachable = true
+		fmt.Fprintf(&report, "TCP:  %s connected (%s)\n", target, time.Since(start).Round(time.Millisecond))
+	}
+	if !reachable {
+		return report.Bytes(), fmt.Errorf("backend port %s is not reachable", port)
+	}
+
+	// The handshake and request authenticate the way the agent does
+	httpClient := &http.Client{}
+	if cfg.Backend.Token != "" {
+		fmt.Fprintln(&report, "Auth: bearer token")
+	}
+	if cfg.Backend.CertFile != "" {
+		fmt.Fprintf(&report, "Auth: client certificate %s\n", cfg.Backend.CertFile)
+	}
+	if secure {
+		tlsConfig, err := transmitter.BackendTLSConfig(cfg.Backend, cfg.BackendURL)
+		if err != nil {
+			fmt.Fprintf(&report, "TLS:  %v\n", err)
+			return report.Bytes(), fmt.Errorf("invalid backend TLS configuration")
+		}
+		httpClient.Transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}
+
+		start := time.Now()
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: diagnoseTimeout}, "tcp", net.JoinHostPort(host, port), tlsConfig)
+		if err != nil {
+			fmt.Fprintf(&report, "TLS:  handshake failed after %s: %v\n", time.Since(start).Round(time.Millisecond), err)
+			return report.Bytes(), fmt.Errorf("TLS handshake with the backend failed")
+		}
+		state := conn.ConnectionState()
+		conn.Close()
+		fmt.Fprintf(&report, "TLS:  %s %s (%s)\n", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite), time.Since(start).Round(time.Millisecond))
+		if len(state.PeerCertificates) > 0 {
+			leaf := state.PeerCertificates[0]
+			fmt.Fprintf(&report, "      subject %s, issuer %s, expires %s\n", leaf.Subject, leaf.Issuer, leaf.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	// The websocket endpoint answers a plain request with an HTTP error, which
+	// still shows that the backend itself is responding
+	httpURL := *backend
+	httpURL.Scheme = "http"
+	if secure {
+		httpURL.Scheme = "https"
+	}
+	requestPath := httpURL.Path
+	if requestPath == "" {
+		requestPath = "/"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL.String(), nil)
+	if err != nil {
+		return report.Bytes(), err
+	}
+	request.Header = transmitter.BackendHeader(cfg.Backend)
+	start = time.Now()
+	response, err := httpClient.Do(request)
+	if err != nil {
+		fmt.Fprintf(&report, "HTTP: GET %s failed after %s: %v\n", requestPath, time.Since(start).Round(time.Millisecond), err)
+		return report.Bytes(), fmt.Errorf("backend HTTP request failed")
+	}
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+	response.Body.Close()
+	fmt.Fprintf(&report, "HTTP: GET %s -> %s (%s)\n", requestPath, response.Status, time.Since(start).Round(time.Millisecond))
+
+	return report.Bytes(), nil
+}
+
+// diagnoseCollector runs one collector once and renders its metrics as a table
+func diagnoseCollector(ctx context.Context, name string, cfg *metrics.AgentConfig, logger *logrus.Logger) ([]byte, error) {
+	collector, err := agent.NewCollector(name, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+	defer cancel()
+	if err := collector.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start: %w", err)
+	}
+	defer collector.Stop()
+
+	collected, err := collector.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var report bytes.Buffer
+	if err := writeMetricsTable(&report, collected); err != nil {
+		return nil, err
+	}
+	return report.Bytes(), nil
+}
+
+// diagnoseCommands runs each command and concatenates their output; it only
+// fails when none of them could be run
+func diagnoseCommands(ctx context.Context, commands [][]string) ([]byte, error) {
+	var report bytes.Buffer
+	var lastErr error
+	ran := false
+	for _, command := range commands {
+		commandCtx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+		output, err := exec.CommandContext(commandCtx, command[0], command[1:]...).CombinedOutput()
+		cancel()
+
+		fmt.Fprintf(&report, "$ %s\n", strings.Join(command, " "))
+		report.Write(output)
+		if err != nil {
+			fmt.Fprintf(&report, "(%v)\n", err)
+			lastErr = err
+		} else {
+			ran = true
+		}
+		fmt.Fprintln(&report)
+	}
+	if !ran {
+		return report.Bytes(), lastErr
+	}
+	return report.Bytes(), nil
+}
+
+// diagnoseFiles concatenates the given files, skipping those that do not exist
+func diagnoseFiles(paths ...string) ([]byte, error) {
+	var report bytes.Buffer
+	found := false
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(&report, "# %s: %v\n\n", path, err)
+			continue
+		}
+		found = true
+		fmt.Fprintf(&report, "# %s\n", path)
+		report.Write(content)
+		fmt.Fprintln(&report)
+	}
+	if !found {
+		return report.Bytes(), fmt.Errorf("none of %s could be read", strings.Join(paths, ", "))
+	}
+	return report.Bytes(), nil
+}
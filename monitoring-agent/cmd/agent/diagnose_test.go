@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// readBundle returns the content of every file in a support bundle by name
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+
+	files := make(map[string]string)
+	archive := tar.NewReader(compressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next() error = %v", err)
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			t.Fatalf("tar Read() error = %v", err)
+		}
+		files[header.Name[strings.Index(header.Name, "/")+1:]] = string(content)
+	}
+	return files
+}
+
+func TestDiagnoseBundleRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "control-token")
+	if err := os.WriteFile(tokenFile, []byte("control-test-token-91ab\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NM_TEST_BACKEND_TOKEN", "backend-test-token-7f3e")
+	// A secret that also appears in the backend URL, so swapping it for its
+	// reference first would leave a URL whose query is no longer redacted
+	t.Setenv("NM_TEST_FORWARDED_HOST", "localhost")
+
+	configPath := filepath.Join(dir, "agent.yaml")
+	configContent := `backend_url: "ws://agent:hunter2-pass@localhost:1/connect?api_key=abc123"
+collectors: ["network_interface"]
+backend:
+  token: "${env:NM_TEST_BACKEND_TOKEN}"
+control:
+  address: "unix:` + filepath.Join(dir, "agent.sock") + `"
+  token: "${file:` + tokenFile + `}"
+custom_targets:
+  http_targets:
+    - url: "http://example.com/"
+      headers:
+        X-Forwarded-Host: "${env:NM_TEST_FORWARDED_HOST}"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	previousConfig, previousOutput, previousTimeout := configFile, diagnoseOutput, diagnoseTimeout
+	defer func() { configFile, diagnoseOutput, diagnoseTimeout = previousConfig, previousOutput, previousTimeout }()
+	configFile = configPath
+	diagnoseOutput = filepath.Join(dir, "bundle.tar.gz")
+	diagnoseTimeout = 2 * time.Second
+
+	if err := runDiagnose(&cobra.Command{}, nil); err != nil {
+		t.Fatalf("runDiagnose() error = %v", err)
+	}
+	files := readBundle(t, diagnoseOutput)
+
+	for _, name := range []string{"summary.txt", "config/effective.yaml", "connectivity.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle is missing %s", name)
+		}
+	}
+	for name, content := range files {
+		for _, secret := range []string{"abc123", "hunter2-pass", "backend-test-token-7f3e", "control-test-token-91ab"} {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s contains the secret %q", name, secret)
+			}
+		}
+	}
+	if want := "api_key=REDACTED"; !strings.Contains(files["connectivity.txt"], want) {
+		t.Errorf("connectivity.txt = %q, want the backend URL with %s", files["connectivity.txt"], want)
+	}
+}
@@ -7,19 +7,27 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/control"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
 	"github.com/spf13/cobra"
 )
 
 var (
-	configFile string
-	logLevel   string
-	backendURL string
-	agentID    string
+	configFile     string
+	logLevel       string
+	backendURL     string
+	agentID        string
+	controlAddress string
 )
 
+// version is the agent release reported by the version command and support bundles
+const version = "1.0.0"
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -61,14 +69,14 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Network Monitor Agent v1.0.0")
+		fmt.Printf("Network Monitor Agent v%s\n", version)
 		fmt.Println("Built for multi-cloud network monitoring")
 	},
 }
 
 func init() {
 	// Add commands
-	rootCmd.AddCommand(runCmd, generateConfigCmd, statusCmd, versionCmd)
+	rootCmd.AddCommand(runCmd, generateConfigCmd, statusCmd, collectCmd, probeCmd, validateConfigCmd, diagnoseCmd, versionCmd)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file path")
@@ -78,8 +86,28 @@ func init() {
 	runCmd.Flags().StringVar(&backendURL, "backend-url", "", "backend server URL")
 	runCmd.Flags().StringVar(&agentID, "agent-id", "", "unique agent identifier")
 
+	// Status command flags
+	statusCmd.Flags().StringVar(&controlAddress, "address", "", "control API address of the running agent (defaults to control.address)")
+	
+	// Collect command flags
+	collectCmd.Flags().IntVarP(&collectCount, "count", "n", 1, "number of collection rounds")
+	collectCmd.Flags().DurationVar(&collectInterval, "interval", 0, "time between rounds (defaults to collect_interval)")
+	collectCmd.Flags().StringVarP(&collectFormat, "output", "o", "table", "output format (table, json, prometheus)")
+	
+	// Probe subcommands and flags
+	addProbeCommands()
+	
+	// Validate config command flags
+	validateConfigCmd.Flags().BoolVar(&validatePrintSchema, "schema", false, "print the JSON Schema of the configuration file and exit")
+	validateConfigCmd.Flags().BoolVarP(&validateQuiet, "quiet", "q", false, "do not print the effective configuration")
+	
+	// Diagnose command flags
+	diagnoseCmd.Flags().StringVarP(&diagnoseOutput, "output", "o", "", "bundle file path (defaults to network-monitor-diagnose-<host>-<time>.tar.gz)")
+	diagnoseCmd.Flags().StringVar(&controlAddress, "address", "", "control API address of the running agent (defaults to control.address)")
+	diagnoseCmd.Flags().DurationVar(&diagnoseTimeout, "timeout", 30*time.Second, "time limit for each section")
+	
 	// Generate config command flags
-	generateConfigCmd.Flags().StringVarP(&configFile, "output", "o", "agent-config.yaml", "output file path")
+	generateConfigCmd.Flags().StringP("output", "o", "agent-config.yaml", "output file path")
 }
 
 func runAgent(cmd *cobra.Command, args []string) error {
@@ -90,7 +118,7 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	
 	// Override config file if specified
 	if configFile != "" {
-		// This would require extending the config manager to accept specific file paths
+		configManager.SetConfigFile(configFile)
 		fmt.Printf("📁 Using config file: %s\n", configFile)
 	}
 	
@@ -99,21 +127,11 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	}
 	
 	cfg := configManager.GetConfig()
-	
-	// Override configuration with command line flags
-	if backendURL != "" {
-		cfg.BackendURL = backendURL
-	}
-	if agentID != "" {
-		cfg.AgentID = agentID
-	}
-	if logLevel != "" {
-		cfg.LogLevel = logLevel
-	}
+	applyFlagOverrides(cfg)
 	
 	// Display configuration
 	fmt.Printf("📊 Agent ID: %s\n", cfg.AgentID)
-	fmt.Printf("🌐 Backend URL: %s\n", cfg.BackendURL)
+	fmt.Printf("🌐 Backend URL: %s\n", configManager.RedactString(cfg.BackendURL))
 	fmt.Printf("📍 Location: %s/%s/%s\n", cfg.Location.Provider, cfg.Location.Region, cfg.Location.Zone)
 	fmt.Printf("⏱️  Collection Interval: %s\n", cfg.CollectInterval)
 	fmt.Printf("📦 Batch Size: %d\n", cfg.BatchSize)
@@ -129,26 +147,84 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	
-	// Setup signal handling
+	// Setup signal handling; SIGHUP reloads the configuration
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	
 	// Start agent
 	if err := monitoringAgent.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start agent: %w", err)
 	}
 	
+	// Serve the local control API used by the status command
+	var controlServer *control.Server
+	if cfg.Control.Enabled {
+		controlServer = control.NewServer(cfg.Control.Address, cfg.Control.Token, monitoringAgent, monitoringAgent.Logger())
+		if err := controlServer.Start(); err != nil {
+			fmt.Printf("⚠️  Control API unavailable: %v\n", err)
+			controlServer = nil
+		}
+	}
+	
+	// Orchestrator probes may need /healthz and /readyz on a non-loopback address
+	var healthServer *control.Server
+	if cfg.Health.Address != "" {
+		healthServer = control.NewHealthServer(cfg.Health.Address, monitoringAgent, monitoringAgent.Logger())
+		if err := healthServer.Start(); err != nil {
+			fmt.Printf("⚠️  Health probe endpoints unavailable: %v\n", err)
+			healthServer = nil
+		}
+	}
+	
 	fmt.Println("✅ Agent started successfully!")
 	fmt.Println("📡 Collecting and transmitting network metrics...")
 	fmt.Println("Press Ctrl+C to stop")
 	
-	// Wait for shutdown signal
-	<-sigChan
+	// Reload when a ${file:} secret changes, and optionally when the config file does
+	reloadChan := make(chan struct{}, 1)
+	watch := configManager.WatchSecrets
+	if cfg.WatchConfig {
+		watch = configManager.Watch
+	}
+	err = watch(ctx, func() {
+		select {
+		case reloadChan <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		fmt.Printf("⚠️  File watch unavailable: %v\n", err)
+	} else if cfg.WatchConfig && configManager.ConfigFileUsed() != "" {
+		fmt.Printf("👀 Watching %s for changes\n", configManager.ConfigFileUsed())
+	}
+	
+	// Wait for shutdown signal, reloading the configuration on request
+	for waiting := true; waiting; {
+		select {
+		case sig := <-sigChan:
+			if sig != syscall.SIGHUP {
+				waiting = false
+				continue
+			}
+			reloadConfig(ctx, configManager, monitoringAgent)
+		case <-reloadChan:
+			reloadConfig(ctx, configManager, monitoringAgent)
+		}
+	}
 	fmt.Println("\n🛑 Shutdown signal received, stopping agent...")
 	
 	// Cancel context to stop operations
 	cancel()
 	
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if controlServer != nil {
+		controlServer.Stop(shutdownCtx)
+	}
+	if healthServer != nil {
+		healthServer.Stop(shutdownCtx)
+	}
+	shutdownCancel()
+	
 	// Stop agent gracefully
 	if err := monitoringAgent.Stop(); err != nil {
 		fmt.Printf("⚠️  Error during shutdown: %v\n", err)
@@ -159,6 +235,40 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applyFlagOverrides overrides configuration with command line flags
+func applyFlagOverrides(cfg *metrics.AgentConfig) {
+	if backendURL != "" {
+		cfg.BackendURL = backendURL
+	}
+	if agentID != "" {
+		cfg.AgentID = agentID
+	}
+	if logLevel != "" {
+		cfg.LogLevel = logLevel
+	}
+}
+
+// reloadConfig re-reads the configuration and applies it to the running agent,
+// which keeps its current configuration if the new one is rejected
+func reloadConfig(ctx context.Context, configManager *config.Manager, monitoringAgent *agent.Agent) {
+	logger := monitoringAgent.Logger()
+	logger.Info("Reloading configuration")
+	
+	cfg, err := configManager.Reload()
+	if err != nil {
+		logger.WithError(err).Error("Configuration reload rejected, keeping the current configuration")
+		return
+	}
+	applyFlagOverrides(cfg)
+	
+	if err := monitoringAgent.Reload(ctx, cfg); err != nil {
+		logger.WithError(err).Error("Configuration reload failed, keeping the current configuration")
+		configManager.Discard(cfg)
+		return
+	}
+	configManager.Commit(cfg)
+}
+
 func generateConfig(cmd *cobra.Command, args []string) error {
 	outputFile := "agent-config.yaml"
 	if len(args) > 0 {
@@ -196,8 +306,14 @@ func showStatus(cmd *cobra.Command, args []string) error {
 	
 	// Load configuration to show current settings
 	configManager := config.NewManager()
+	if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
 	if err := configManager.Load(); err != nil {
 		fmt.Printf("⚠️  Failed to load configuration: %v\n", err)
+		if controlAddress != "" {
+			showRuntimeStatus(controlAddress, "")
+		}
 		return nil
 	}
 	
@@ -205,7 +321,7 @@ func showStatus(cmd *cobra.Command, args []string) error {
 	
 	// Display configuration status
 	fmt.Printf("Agent ID:           %s\n", cfg.AgentID)
-	fmt.Printf("Backend URL:        %s\n", cfg.BackendURL)
+	fmt.Printf("Backend URL:        %s\n", configManager.RedactString(cfg.BackendURL))
 	fmt.Printf("Log Level:          %s\n", cfg.LogLevel)
 	fmt.Printf("Collection Interval: %s\n", cfg.CollectInterval)
 	fmt.Printf("Batch Size:         %d\n", cfg.BatchSize)
@@ -237,12 +353,61 @@ func showStatus(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  TCP Ports:        %v\n", cfg.CustomTargets.TCPPorts)
 	fmt.Printf("  HTTP Targets:     %d configured\n", len(cfg.CustomTargets.HTTPTargets))
 	
-	// TODO: If we had a running agent, we could check its live status here
-	// fmt.Println("\n🟢 Runtime Status:")
-	// fmt.Println("  Status:           Running")
-	// fmt.Println("  Connected:        Yes")
-	// fmt.Println("  Last Metric:      2024-01-01 12:00:00")
-	// fmt.Println("  Metrics Sent:     1,234")
+	// Query the running agent
+	address := controlAddress
+	if address == "" {
+		address = cfg.Control.Address
+	}
+	showRuntimeStatus(address, cfg.Control.Token)
 	
 	return nil
+}
+
+// showRuntimeStatus prints the live status reported by a running agent's control API
+func showRuntimeStatus(address, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	
+	status, err := control.NewClient(address, token, 5*time.Second).Status(ctx)
+	if err != nil {
+		fmt.Println("\n🔴 Runtime Status:")
+		fmt.Printf("  Status:           Not reachable at %s (%v)\n", address, err)
+		return
+	}
+	
+	fmt.Println("\n🟢 Runtime Status:")
+	if status.Running {
+		fmt.Println("  Status:           Running")
+	} else {
+		fmt.Println("  Status:           Stopped")
+	}
+	if status.Connected {
+		fmt.Println("  Connected:        Yes")
+	} else {
+		fmt.Println("  Connected:        No")
+	}
+	fmt.Printf("  Agent ID:         %s\n", status.AgentID)
+	fmt.Printf("  Queue:            %d/%d\n", status.QueueDepth, status.QueueCapacity)
+	fmt.Printf("  Metrics Sent:     %d\n", status.MetricsSent)
+	fmt.Printf("  Metrics Dropped:  %d\n", status.MetricsDropped)
+	fmt.Printf("  Batches:          %d sent, %d failed\n", status.BatchesSent, status.BatchesFailed)
+	if status.LastSend != nil {
+		fmt.Printf("  Last Send:        %s\n", status.LastSend.Local().Format(time.RFC3339))
+	}
+	if status.LastSendError != "" {
+		fmt.Printf("  Last Send Error:  %s\n", status.LastSendError)
+	}
+	
+	fmt.Println("\n🔧 Collector Runs:")
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "  NAME\tSTATE\tLAST RUN\tDURATION\tMETRICS\tRUNS\tERRORS\tLAST ERROR")
+	for _, stats := range status.CollectorStats {
+		lastRun := "never"
+		if !stats.LastRun.IsZero() {
+			lastRun = stats.LastRun.Local().Format("15:04:05")
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\t%.1fms\t%d\t%d\t%d\t%s\n",
+			stats.Name, stats.State, lastRun, stats.LastDurationMs, stats.LastMetrics, stats.Runs, stats.Errors, stats.LastError)
+	}
+	writer.Flush()
 } 
\ No newline at end of file
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	probeCount    int
+	probeInterval time.Duration
+	probeTimeout  time.Duration
+
+	probeProxy     string
+	probeSource    string
+	probeInterface string
+	probeFamily    string
+
+	probeMethod       string
+	probeExpectStatus int
+	probeHeaders      []string
+	probeDNSServers   []string
+	probeRecordType   string
+	probeServerName   string
+	probeCAFile       string
+	probeMaxHops      int
+)
+
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "Probe a single target interactively",
+	Long: `Run one of the agent's probes against a single target, printing each result
+as it arrives and a summary at the end. The probe uses the same collector code
+and the same configuration defaults as the running agent; flags override the
+configuration for this run only.`,
+}
+
+// probeKind describes one probe subcommand
+type probeKind struct {
+	collector string // Collector the probe runs
+	success   string // Metric whose value reports whether a probe succeeded
+	usage     string
+	short     string
+	// target points the configuration at the single target from the command line
+	target func(cfg *metrics.AgentConfig, target string) error
+	// proxy reports whether the collector honours egress.proxy_url
+	proxy bool
+}
+
+var probeKinds = []probeKind{
+	{
+		collector: "ping",
+		success:   "ping_success",
+		usage:     "ping <host>",
+		short:     "Send ICMP echo requests to a host",
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			cfg.CustomTargets.PingTargets = []string{target}
+			cfg.CustomTargets.PingEgress = nil
+			return nil
+		},
+	},
+	{
+		collector: "tcp",
+		success:   "tcp_connect_success",
+		usage:     "tcp <host:port>",
+		short:     "Measure TCP connection setup to host:port",
+		proxy:     true,
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			cfg.CustomTargets.TCPTargets = []metrics.TCPTarget{{
+				Address: target,
+				Timeout: probeTimeout,
+			}}
+			return nil
+		},
+	},
+	{
+		collector: "http",
+		success:   "http_success",
+		usage:     "http <url>",
+		short:     "Request a URL and check the response status",
+		proxy:     true,
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			if !strings.Contains(target, "://") {
+				target = "https://" + target
+			}
+			headers := make(map[string]string, len(probeHeaders))
+			for _, header := range probeHeaders {
+				name, value, found := strings.Cut(header, ":")
+				if !found || strings.TrimSpace(name) == "" {
+					return fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+				}
+				headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+			cfg.CustomTargets.HTTPTargets = []metrics.HTTPTarget{{
+				URL:            target,
+				Method:         strings.ToUpper(probeMethod),
+				ExpectedCode:   probeExpectStatus,
+				Timeout:        probeTimeout,
+				Headers:        headers,
+				FollowRedirect: true,
+			}}
+			return nil
+		},
+	},
+	{
+		collector: "dns",
+		success:   "dns_resolution_success",
+		usage:     "dns <name>",
+		short:     "Resolve a name against the configured or given DNS servers",
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			if len(probeDNSServers) > 0 {
+				cfg.CustomTargets.DNSServers = probeDNSServers
+			}
+			cfg.DNS.Names = []string{target}
+			if probeRecordType != "" {
+				cfg.DNS.RecordType = probeRecordType
+			}
+			if probeTimeout > 0 {
+				cfg.DNS.Timeout = probeTimeout
+			}
+			return nil
+		},
+	},
+	{
+		collector: "traceroute",
+		success:   "traceroute_success",
+		usage:     "traceroute <host>",
+		short:     "Trace the path to a host with the system traceroute",
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			cfg.CustomTargets.TracerouteTargets = []string{target}
+			if probeMaxHops > 0 {
+				cfg.Traceroute.MaxHops = probeMaxHops
+			}
+			if probeTimeout > 0 {
+				cfg.Traceroute.Timeout = probeTimeout
+			}
+			return nil
+		},
+	},
+	{
+		collector: "tls",
+		success:   "tls_success",
+		usage:     "tls <host[:port]>",
+		short:     "Perform a TLS handshake and inspect the certificate chain",
+		target: func(cfg *metrics.AgentConfig, target string) error {
+			cfg.CustomTargets.TLSTargets = []metrics.TLSTarget{{
+				Address:    target,
+				ServerName: probeServerName,
+				CAFile:     probeCAFile,
+			}}
+			if probeTimeout > 0 {
+				cfg.TLS.Timeout = probeTimeout
+			}
+			return nil
+		},
+	},
+}
+
+// addProbeCommands registers the probe flags and one subcommand per probe kind
+func addProbeCommands() {
+	probeCmd.PersistentFlags().IntVarP(&probeCount, "count", "n", 5, "number of probes, 0 to run until interrupted")
+	probeCmd.PersistentFlags().DurationVarP(&probeInterval, "interval", "i", time.Second, "time between probes")
+	probeCmd.PersistentFlags().DurationVar(&probeTimeout, "timeout", 0, "probe timeout (defaults to the collector's configured timeout)")
+
+	for _, kind := range probeKinds {
+		kind := kind
+		cmd := &cobra.Command{
+			Use:   kind.usage,
+			Short: kind.short,
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				// Failed probes are not usage errors
+				cmd.SilenceUsage = true
+				return runProbe(kind, args[0])
+			},
+		}
+
+		if kind.collector != "tls" {
+			if kind.proxy {
+				cmd.Flags().StringVar(&probeProxy, "proxy", "", "proxy URL (http://, https:// or socks5://), \"direct\" to bypass egress.proxy_url")
+			}
+			cmd.Flags().StringVar(&probeSource, "source", "", "local IP address to send from")
+			cmd.Flags().StringVar(&probeInterface, "interface", "", "interface to bind to (Linux only)")
+			cmd.Flags().StringVar(&probeFamily, "family", "", "IP family to use (ipv4 or ipv6)")
+		}
+
+		switch kind.collector {
+		case "http":
+			cmd.Flags().StringVarP(&probeMethod, "method", "X", "GET", "request method")
+			cmd.Flags().IntVar(&probeExpectStatus, "expect-status", 200, "expected response status code")
+			cmd.Flags().StringArrayVarP(&probeHeaders, "header", "H", nil, "request header as \"Name: value\", may be repeated")
+		case "dns":
+			cmd.Flags().StringSliceVar(&probeDNSServers, "server", nil, "DNS server to query, may be repeated (defaults to custom_targets.dns_servers)")
+			cmd.Flags().StringVarP(&probeRecordType, "type", "t", "", "record type: A, AAAA, CNAME, MX, NS or TXT (defaults to dns.record_type)")
+		case "traceroute":
+			cmd.Flags().IntVarP(&probeMaxHops, "max-hops", "m", 0, "maximum number of hops (defaults to traceroute.max_hops)")
+		case "tls":
+			cmd.Flags().StringVar(&probeServerName, "server-name", "", "SNI and name to verify (defaults to the host)")
+			cmd.Flags().StringVar(&probeCAFile, "ca-file", "", "PEM bundle to verify against (defaults to tls.ca_file or the system roots)")
+		}
+
+		probeCmd.AddCommand(cmd)
+	}
+}
+
+func runProbe(kind probeKind, target string) error {
+	if probeCount < 0 {
+		return fmt.Errorf("--count cannot be negative")
+	}
+	if probeInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	configManager := config.NewManager()
+	if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
+	if err := configManager.Load(); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := configManager.GetConfig()
+	cfg.CollectInterval = probeInterval
+
+	if probeProxy != "" {
+		cfg.Egress.ProxyURL = probeProxy
+	}
+	if probeSource != "" {
+		cfg.Egress.SourceAddress = probeSource
+	}
+	if probeInterface != "" {
+		cfg.Egress.Interface = probeInterface
+	}
+	if probeFamily != "" {
+		cfg.Egress.IPFamily = probeFamily
+	}
+	if err := kind.target(cfg, target); err != nil {
+		return err
+	}
+	if err := configManager.ValidateTargets(cfg); err != nil {
+		return fmt.Errorf("invalid probe: %w", err)
+	}
+
+	// Collector warnings explain failed probes, so they stay visible on stderr
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.WarnLevel)
+	logger.AddHook(configManager.LogHook())
+	if logLevel != "" {
+		level, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log level %q: %w", logLevel, err)
+		}
+		logger.SetLevel(level)
+	}
+
+	collector, err := agent.NewCollector(kind.collector, cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := collector.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start %s probe: %w", kind.collector, err)
+	}
+	defer collector.Stop()
+
+	fmt.Printf("PROBE %s %s\n", kind.collector, target)
+
+	summary := newProbeSummary(kind.success)
+	for seq := 1; probeCount == 0 || seq <= probeCount; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(probeInterval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		result, err := collector.Collect(ctx)
+		if ctx.Err() != nil {
+			// Interrupted mid-probe; the partial result is not meaningful
+			break
+		}
+		if err != nil {
+			fmt.Printf("seq=%d error after %s: %v\n", seq, time.Since(start).Round(time.Millisecond), err)
+			summary.addFailure()
+			continue
+		}
+		writeProbeResult(os.Stdout, seq, result)
+		summary.add(result)
+	}
+
+	summary.write(os.Stdout, kind.collector, target)
+	if !summary.anySucceeded() {
+		return fmt.Errorf("all probes failed")
+	}
+	return nil
+}
+
+// writeProbeResult prints the metrics of one probe, one per line
+func writeProbeResult(w io.Writer, seq int, result []metrics.Metric) {
+	for _, metric := range result {
+		fmt.Fprintf(w, "seq=%d %s=%s %s", seq, metric.Name, strconv.FormatFloat(metric.Value, 'g', -1, 64), metric.Unit)
+		if len(metric.Tags) > 0 {
+			fmt.Fprintf(w, " {%s}", formatTags(metric.Tags, "=", ","))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// probeSeries accumulates the samples of one metric series across probes
+type probeSeries struct {
+	name      string
+	unit      string
+	tags      map[string]string
+	count     int
+	sum       float64
+	min       float64
+	max       float64
+	succeeded int
+}
+
+// probeSummary aggregates probe results per metric series
+type probeSummary struct {
+	success string
+	probes  int
+	failed  int
+	series  map[string]*probeSeries
+	order   []string
+}
+
+func newProbeSummary(success string) *probeSummary {
+	return &probeSummary{
+		success: success,
+		series:  make(map[string]*probeSeries),
+	}
+}
+
+// addFailure records a probe whose collection returned an error
+func (s *probeSummary) addFailure() {
+	s.probes++
+	s.failed++
+}
+
+// add records the metrics of one probe
+func (s *probeSummary) add(result []metrics.Metric) {
+	s.probes++
+	failed := false
+	for _, metric := range result {
+		// The error tag differs between attempts of the same series
+		tags := make(map[string]string, len(metric.Tags))
+		for key, value := range metric.Tags {
+			if key != "error" {
+				tags[key] = value
+			}
+		}
+		key := metric.Name + "{" + formatTags(tags, "=", ",") + "}"
+
+		series, exists := s.series[key]
+		if !exists {
+			series = &probeSeries{
+				name: metric.Name,
+				unit: metric.Unit,
+				tags: tags,
+				min:  math.Inf(1),
+				max:  math.Inf(-1),
+			}
+			s.series[key] = series
+			s.order = append(s.order, key)
+		}
+		series.count++
+		series.sum += metric.Value
+		series.min = math.Min(series.min, metric.Value)
+		series.max = math.Max(series.max, metric.Value)
+
+		if metric.Name == s.success {
+			if metric.Value > 0 {
+				series.succeeded++
+			} else {
+				failed = true
+			}
+		}
+	}
+	if failed {
+		s.failed++
+	}
+}
+
+// anySucceeded reports whether at least one probe succeeded for some target
+func (s *probeSummary) anySucceeded() bool {
+	for _, series := range s.series {
+		if series.succeeded > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// write prints the per-series statistics in the style of ping's summary
+func (s *probeSummary) write(w io.Writer, collector, target string) {
+	fmt.Fprintf(w, "\n--- %s probe %s statistics ---\n", collector, target)
+	lossPercent := 0.0
+	if s.probes > 0 {
+		lossPercent = float64(s.failed) / float64(s.probes) * 100
+	}
+	fmt.Fprintf(w, "%d probes, %d succeeded, %.1f%% failed\n", s.probes, s.probes-s.failed, lossPercent)
+
+	sort.SliceStable(s.order, func(i, j int) bool {
+		// The success series comes first, the rest keep their first-seen order
+		return s.series[s.order[i]].name == s.success && s.series[s.order[j]].name != s.success
+	})
+	for _, key := range s.order {
+		series := s.series[key]
+		label := series.name
+		if len(series.tags) > 0 {
+			label += " {" + formatTags(series.tags, "=", ",") + "}"
+		}
+		if series.name == s.success {
+			fmt.Fprintf(w, "%s: %d/%d succeeded\n", label, series.succeeded, series.count)
+			continue
+		}
+		fmt.Fprintf(w, "%s: min/avg/max = %s/%s/%s %s (%d samples)\n",
+			label,
+			formatProbeValue(series.min),
+			formatProbeValue(series.sum/float64(series.count)),
+			formatProbeValue(series.max),
+			series.unit,
+			series.count,
+		)
+	}
+}
+
+// formatProbeValue rounds a statistic for display
+func formatProbeValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validatePrintSchema bool
+	validateQuiet       bool
+)
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate-config [file]",
+	Short: "Check a configuration file and print the effective configuration",
+	Long: `Check the configuration file (the argument, --config, or the default search
+path) against the configuration schema and semantic rules, reporting every
+problem with its line and column. A valid file is followed by the fully
+resolved configuration the agent would run with, including defaults and
+NETMON_* environment overrides.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runValidateConfig,
+}
+
+func runValidateConfig(cmd *cobra.Command, args []string) error {
+	if validatePrintSchema {
+		schema, err := json.MarshalIndent(config.GenerateSchema(agent.CollectorNames()), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(schema))
+		return nil
+	}
+
+	configManager := config.NewManager()
+	if len(args) > 0 {
+		configManager.SetConfigFile(args[0])
+	} else if configFile != "" {
+		configManager.SetConfigFile(configFile)
+	}
+
+	// Problems in the file are reported below, not as usage errors
+	cmd.SilenceUsage = true
+	result, err := configManager.Validate(agent.CollectorNames())
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range result.Problems {
+		position := result.File
+		if problem.Line > 0 {
+			position = fmt.Sprintf("%s:%d", position, problem.Line)
+		}
+		if problem.Column > 0 {
+			position = fmt.Sprintf("%s:%d", position, problem.Column)
+		}
+		if problem.Path == "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", position, problem.Message)
+			continue
+		}
+		source := ""
+		if problem.Line == 0 {
+			source = " (default or environment)"
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s%s: %s\n", position, problem.Path, source, problem.Message)
+	}
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%s: %d problem(s) found", result.File, len(result.Problems))
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ %s is valid\n", result.File)
+	if validateQuiet {
+		return nil
+	}
+	effective, err := config.MarshalConfig(result.Effective)
+	if err != nil {
+		return fmt.Errorf("failed to render effective configuration: %w", err)
+	}
+	fmt.Print(string(effective))
+	return nil
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/collectors"
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/config"
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/transmitter"
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
@@ -24,6 +24,23 @@ type Agent struct {
 	wg          sync.WaitGroup
 	running     bool
 	mutex       sync.RWMutex
+	stats       *collectorStatsTable
+	counters    transmitCounters
+	startedAt   time.Time
+	lastCycle   atomic.Int64 // Unix nanoseconds when the last collection cycle completed
+	logs        *logBuffer
+
+	// configManager redacts resolved secrets from status output
+	configManager *config.Manager
+
+	// lifecycleMutex serializes Start, Stop and Reload, which hold mutex only
+	// while they read or swap state so status and health checks are not blocked
+	lifecycleMutex sync.Mutex
+	// reloadMutex is held by the collection and transmission loops while they
+	// use the collectors, transmitter or config; Reload takes it exclusively
+	reloadMutex       sync.RWMutex
+	intervalChan      chan time.Duration
+	transmitterCancel context.CancelFunc
 }
 
 // New creates a new monitoring agent
@@ -40,6 +57,10 @@ func New(configManager *config.Manager) (*Agent, error) {
 		level = logrus.InfoLevel
 	}
 	logger.SetLevel(level)
+	// Resolved secrets are redacted before entries are written or buffered
+	logger.AddHook(configManager.LogHook())
+	logs := newLogBuffer()
+	logger.AddHook(logs)
 
 	// Create metric queue
 	metricQueue := make(chan metrics.Metric, config.BatchSize*10) // Buffer for multiple batches
@@ -47,6 +68,7 @@ func New(configManager *config.Manager) (*Agent, error) {
 	// Create transmitter
 	transmitter := transmitter.NewWebSocketTransmitter(
 		config.BackendURL,
+		config.Backend,
 		config.AgentID,
 		config.Location,
 		logger,
@@ -58,6 +80,12 @@ func New(configManager *config.Manager) (*Agent, error) {
 		transmitter: transmitter,
 		metricQueue: metricQueue,
 		stopChan:    make(chan bool),
+		stats:       newCollectorStatsTable(),
+		logs:        logs,
+
+		configManager: configManager,
+
+		intervalChan: make(chan time.Duration, 1),
 	}
 
 	// Initialize collectors
@@ -70,6 +98,8 @@ func New(configManager *config.Manager) (*Agent, error) {
 
 // Start begins the monitoring agent operation
 func (a *Agent) Start(ctx context.Context) error {
+	a.lifecycleMutex.Lock()
+	defer a.lifecycleMutex.Unlock()
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -88,36 +118,76 @@ func (a *Agent) Start(ctx context.Context) error {
 	}
 
 	// Start reconnection loop
-	a.transmitter.(*transmitter.WebSocketTransmitter).StartReconnectLoop(ctx)
+	transmitterCtx, transmitterCancel := context.WithCancel(ctx)
+	if wst, ok := a.transmitter.(*transmitter.WebSocketTransmitter); ok {
+		wst.StartReconnectLoop(transmitterCtx)
+	}
+	a.transmitterCancel = transmitterCancel
 
-	// Start collectors
+	// Start collectors; those that fail to start are not collected from
+	started := make([]metrics.MetricCollector, 0, len(a.collectors))
 	for _, collector := range a.collectors {
-		if err := collector.Start(ctx); err != nil {
-			a.logger.WithFields(logrus.Fields{
-				"collector": collector.Name(),
-				"error":     err,
-			}).Error("Failed to start collector")
-			continue
+		if a.startCollector(ctx, collector) {
+			started = append(started, collector)
 		}
-		a.logger.WithField("collector", collector.Name()).Info("Started collector")
 	}
+	a.collectors = started
+	a.updateClockOffsetSource()
 
 	// Start metric collection goroutines
 	a.wg.Add(1)
-	go a.metricCollectionLoop(ctx)
+	go a.metricCollectionLoop(ctx, a.config.CollectInterval)
 
 	// Start metric transmission goroutine
 	a.wg.Add(1)
-	go a.metricTransmissionLoop(ctx)
+	go a.metricTransmissionLoop(ctx, a.config.BatchSize)
 
 	a.running = true
+	a.startedAt = time.Now()
 	a.logger.Info("Monitoring agent started successfully")
 
 	return nil
 }
 
+// startCollector starts a collector and records whether it is running
+func (a *Agent) startCollector(ctx context.Context, collector metrics.MetricCollector) bool {
+	// Collectors that report changes as they happen feed the queue directly
+	if source, ok := collector.(metrics.EventSource); ok {
+		source.SetEventSink(a.queueEvent)
+	}
+
+	if err := collector.Start(ctx); err != nil {
+		a.logger.WithFields(logrus.Fields{
+			"collector": collector.Name(),
+			"error":     err,
+		}).Error("Failed to start collector")
+		a.stats.setState(collector.Name(), CollectorStateFailed, err)
+		return false
+	}
+	a.stats.setState(collector.Name(), CollectorStateRunning, nil)
+	a.logger.WithField("collector", collector.Name()).Info("Started collector")
+	return true
+}
+
+// updateClockOffsetSource makes batches carry the host clock offset when a
+// running collector measures it
+func (a *Agent) updateClockOffsetSource() {
+	var source metrics.ClockOffsetSource
+	for _, collector := range a.collectors {
+		if offsetSource, ok := collector.(metrics.ClockOffsetSource); ok {
+			source = offsetSource
+			break
+		}
+	}
+	if wst, ok := a.transmitter.(*transmitter.WebSocketTransmitter); ok {
+		wst.SetClockOffsetSource(source)
+	}
+}
+
 // Stop gracefully shuts down the monitoring agent
 func (a *Agent) Stop() error {
+	a.lifecycleMutex.Lock()
+	defer a.lifecycleMutex.Unlock()
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -144,6 +214,7 @@ func (a *Agent) Stop() error {
 	a.wg.Wait()
 
 	// Disconnect from backend
+	a.transmitterCancel()
 	if err := a.transmitter.Disconnect(); err != nil {
 		a.logger.WithError(err).Error("Error disconnecting from backend")
 	}
@@ -164,6 +235,11 @@ func (a *Agent) IsRunning() bool {
 	return a.running
 }
 
+// Logger returns the agent's logger
+func (a *Agent) Logger() *logrus.Logger {
+	return a.logger
+}
+
 // GetStatus returns the current status of the agent
 func (a *Agent) GetStatus() map[string]interface{} {
 	a.mutex.RLock()
@@ -173,10 +249,25 @@ func (a *Agent) GetStatus() map[string]interface{} {
 		"running":          a.running,
 		"agent_id":         a.config.AgentID,
 		"location":         a.config.Location,
-		"backend_url":      a.config.BackendURL,
+		"backend_url":      a.configManager.RedactString(a.config.BackendURL),
 		"collect_interval": a.config.CollectInterval.String(),
 		"collectors":       make([]string, len(a.collectors)),
 		"connected":        false,
+		"collector_stats":  a.stats.snapshot(),
+		"queue_depth":      len(a.metricQueue),
+		"queue_capacity":   cap(a.metricQueue),
+		"metrics_queued":   a.counters.metricsQueued.Load(),
+		"metrics_sent":     a.counters.metricsSent.Load(),
+		"metrics_dropped":  a.counters.metricsDropped.Load(),
+		"batches_sent":     a.counters.batchesSent.Load(),
+		"batches_failed":   a.counters.batchesFailed.Load(),
+	}
+
+	if lastSend := a.counters.lastSend.Load(); lastSend != 0 {
+		status["last_send"] = time.Unix(0, lastSend)
+	}
+	if lastError, _ := a.counters.lastSendError.Load().(string); lastError != "" {
+		status["last_send_error"] = lastError
 	}
 
 	// Get collector names
@@ -197,45 +288,8 @@ func (a *Agent) initializeCollectors() error {
 	a.collectors = make([]metrics.MetricCollector, 0)
 
 	for _, collectorName := range a.config.Collectors {
-		var collector metrics.MetricCollector
-		var err error
-
-		switch collectorName {
-		case "network_interface":
-			collector = collectors.NewNetworkCollector(a.config.CollectInterval, a.logger)
-
-		case "ping":
-			collector = collectors.NewPingCollector(
-				a.config.CollectInterval,
-				a.config.CustomTargets.PingTargets,
-				a.logger,
-			)
-
-		case "system":
-			// TODO: Implement system metrics collector
-			a.logger.WithField("collector", collectorName).Warn("System collector not yet implemented")
-			continue
-
-		case "http":
-			// TODO: Implement HTTP endpoint monitoring collector
-			a.logger.WithField("collector", collectorName).Warn("HTTP collector not yet implemented")
-			continue
-
-		case "dns":
-			// TODO: Implement DNS resolution monitoring collector
-			a.logger.WithField("collector", collectorName).Warn("DNS collector not yet implemented")
-			continue
-
-		default:
-			a.logger.WithField("collector", collectorName).Error("Unknown collector type")
-			continue
-		}
-
-		if err != nil {
-			a.logger.WithFields(logrus.Fields{
-				"collector": collectorName,
-				"error":     err,
-			}).Error("Failed to create collector")
+		collector := newCollector(collectorName, a.config, a.logger)
+		if collector == nil {
 			continue
 		}
 
@@ -251,13 +305,13 @@ func (a *Agent) initializeCollectors() error {
 }
 
 // metricCollectionLoop runs the main metric collection loop
-func (a *Agent) metricCollectionLoop(ctx context.Context) {
+func (a *Agent) metricCollectionLoop(ctx context.Context, interval time.Duration) {
 	defer a.wg.Done()
 
-	ticker := time.NewTicker(a.config.CollectInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	a.logger.WithField("interval", a.config.CollectInterval).Info("Starting metric collection loop")
+	a.logger.WithField("interval", interval).Info("Starting metric collection loop")
 
 	for {
 		select {
@@ -269,6 +323,10 @@ func (a *Agent) metricCollectionLoop(ctx context.Context) {
 			a.logger.Info("Metric collection loop stopped")
 			return
 
+		case interval := <-a.intervalChan:
+			ticker.Reset(interval)
+			a.logger.WithField("interval", interval).Info("Collection interval changed")
+
 		case <-ticker.C:
 			a.collectMetrics(ctx)
 		}
@@ -277,6 +335,9 @@ func (a *Agent) metricCollectionLoop(ctx context.Context) {
 
 // collectMetrics runs all collectors and queues the metrics
 func (a *Agent) collectMetrics(ctx context.Context) {
+	a.reloadMutex.RLock()
+	defer a.reloadMutex.RUnlock()
+
 	collectStart := time.Now()
 	totalMetrics := 0
 
@@ -284,6 +345,8 @@ func (a *Agent) collectMetrics(ctx context.Context) {
 		collectorStart := time.Now()
 
 		metrics, err := collector.Collect(ctx)
+		collectorDuration := time.Since(collectorStart)
+		a.stats.recordRun(collector.Name(), collectorStart, collectorDuration, len(metrics), err)
 		if err != nil {
 			a.logger.WithFields(logrus.Fields{
 				"collector": collector.Name(),
@@ -296,13 +359,14 @@ func (a *Agent) collectMetrics(ctx context.Context) {
 		for _, metric := range metrics {
 			select {
 			case a.metricQueue <- metric:
+				a.counters.metricsQueued.Add(1)
 				totalMetrics++
 			default:
+				a.counters.metricsDropped.Add(1)
 				a.logger.Warn("Metric queue is full, dropping metric")
 			}
 		}
 
-		collectorDuration := time.Since(collectorStart)
 		a.logger.WithFields(logrus.Fields{
 			"collector": collector.Name(),
 			"metrics":   len(metrics),
@@ -310,6 +374,15 @@ func (a *Agent) collectMetrics(ctx context.Context) {
 		}).Debug("Collected metrics from collector")
 	}
 
+	// Report on the agent itself after the collectors so the stats reflect this cycle
+	if a.config.SelfTelemetry {
+		for _, metric := range a.selfTelemetry(time.Now()) {
+			a.queueEvent(metric)
+		}
+	}
+
+	a.lastCycle.Store(time.Now().UnixNano())
+
 	collectDuration := time.Since(collectStart)
 	a.logger.WithFields(logrus.Fields{
 		"total_metrics": totalMetrics,
@@ -317,15 +390,26 @@ func (a *Agent) collectMetrics(ctx context.Context) {
 	}).Debug("Completed metric collection cycle")
 }
 
+// queueEvent queues a metric reported by a collector outside the collection loop
+func (a *Agent) queueEvent(metric metrics.Metric) {
+	select {
+	case a.metricQueue <- metric:
+		a.counters.metricsQueued.Add(1)
+	default:
+		a.counters.metricsDropped.Add(1)
+		a.logger.WithField("metric", metric.Name).Warn("Metric queue is full, dropping event")
+	}
+}
+
 // metricTransmissionLoop handles batching and transmitting metrics
-func (a *Agent) metricTransmissionLoop(ctx context.Context) {
+func (a *Agent) metricTransmissionLoop(ctx context.Context, batchSize int) {
 	defer a.wg.Done()
 
-	batch := make([]metrics.Metric, 0, a.config.BatchSize)
+	batch := make([]metrics.Metric, 0, batchSize)
 	batchTimer := time.NewTimer(30 * time.Second) // Maximum batch time
 	defer batchTimer.Stop()
 
-	a.logger.WithField("batch_size", a.config.BatchSize).Info("Starting metric transmission loop")
+	a.logger.WithField("batch_size", batchSize).Info("Starting metric transmission loop")
 
 	for {
 		select {
@@ -357,7 +441,7 @@ func (a *Agent) metricTransmissionLoop(ctx context.Context) {
 			batch = append(batch, metric)
 
 			// Send batch if it's full
-			if len(batch) >= a.config.BatchSize {
+			if len(batch) >= batchSize {
 				a.sendBatch(ctx, batch)
 				batch = batch[:0] // Reset batch
 				batchTimer.Reset(30 * time.Second)
@@ -380,10 +464,16 @@ func (a *Agent) sendBatch(ctx context.Context, batch []metrics.Metric) {
 		return
 	}
 
+	a.reloadMutex.RLock()
+	defer a.reloadMutex.RUnlock()
+
 	sendStart := time.Now()
 
 	err := a.transmitter.Send(ctx, batch)
 	if err != nil {
+		a.counters.batchesFailed.Add(1)
+		a.counters.metricsDropped.Add(uint64(len(batch)))
+		a.counters.lastSendError.Store(err.Error())
 		a.logger.WithFields(logrus.Fields{
 			"batch_size": len(batch),
 			"error":      err,
@@ -391,7 +481,13 @@ func (a *Agent) sendBatch(ctx context.Context, batch []metrics.Metric) {
 		return
 	}
 
+	a.counters.batchesSent.Add(1)
+	a.counters.metricsSent.Add(uint64(len(batch)))
+	a.counters.lastSend.Store(time.Now().UnixNano())
+	a.counters.lastSendError.Store("")
+
 	sendDuration := time.Since(sendStart)
+	a.counters.lastSendLatency.Store(int64(sendDuration))
 	a.logger.WithFields(logrus.Fields{
 		"batch_size": len(batch),
 		"duration":   sendDuration.String(),
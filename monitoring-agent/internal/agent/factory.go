@@ -0,0 +1,244 @@
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/collectors"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// collectorFactory builds one kind of collector from the agent configuration
+type collectorFactory struct {
+	// settings returns the configuration the collector is built from; a reload
+	// restarts the collector when it changes. The collection interval is only
+	// included where the collector uses it, as the collection loop applies it.
+	settings func(config *metrics.AgentConfig) interface{}
+	// build creates the collector; nil for collectors that are not implemented yet
+	build func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector
+}
+
+// collectorFactories maps the names accepted in the collectors list to their factories
+var collectorFactories = map[string]collectorFactory{
+	"network_interface": {
+		settings: func(config *metrics.AgentConfig) interface{} { return config.ProcRoot },
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewNetworkCollector(config.CollectInterval, config.ProcRoot, logger)
+		},
+	},
+	"ping": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.PingTargets, config.Egress, config.CustomTargets.PingEgress}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewPingCollector(
+				config.CollectInterval,
+				config.CustomTargets.PingTargets,
+				config.Egress,
+				config.CustomTargets.PingEgress,
+				logger,
+			)
+		},
+	},
+	"stamp": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.STAMPTargets, config.STAMP}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewSTAMPCollector(
+				config.CollectInterval,
+				config.CustomTargets.STAMPTargets,
+				config.STAMP,
+				logger,
+			)
+		},
+	},
+	"stamp_reflector": {
+		// Sessions expire after two collection intervals
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.STAMP, config.CollectInterval}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewSTAMPReflector(config.CollectInterval, config.STAMP, logger)
+		},
+	},
+	"tcp_stats": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.TCPStats, config.ProcRoot}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewTCPStatsCollector(config.CollectInterval, config.TCPStats, config.ProcRoot, logger)
+		},
+	},
+	"netstat": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.Netstat, config.ProcRoot}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewNetstatCollector(
+				config.CollectInterval,
+				config.Netstat.Protocols,
+				config.ProcRoot,
+				logger,
+			)
+		},
+	},
+	"conntrack": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.Conntrack, config.ProcRoot}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewConntrackCollector(
+				config.CollectInterval,
+				config.Conntrack,
+				config.ProcRoot,
+				logger,
+			)
+		},
+	},
+	"softnet": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.Softnet, config.ProcRoot}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewSoftnetCollector(
+				config.CollectInterval,
+				config.Softnet,
+				config.ProcRoot,
+				logger,
+			)
+		},
+	},
+	"routes": {
+		settings: func(config *metrics.AgentConfig) interface{} { return config.Routes },
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewRoutesCollector(config.CollectInterval, config.Routes, logger)
+		},
+	},
+	"tls": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.TLSTargets, config.TLS}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewTLSCollector(config.CollectInterval, config.CustomTargets.TLSTargets, config.TLS, logger)
+		},
+	},
+	"udp": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.UDPTargets, config.UDP}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewUDPCollector(config.CollectInterval, config.CustomTargets.UDPTargets, config.UDP, logger)
+		},
+	},
+	"ntp": {
+		settings: func(config *metrics.AgentConfig) interface{} { return config.NTP },
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewNTPCollector(config.CollectInterval, config.NTP, logger)
+		},
+	},
+	"grpc": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.GRPCTargets, config.GRPC}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewGRPCCollector(config.CollectInterval, config.CustomTargets.GRPCTargets, config.GRPC, logger)
+		},
+	},
+	"http_transaction": {
+		settings: func(config *metrics.AgentConfig) interface{} { return config.CustomTargets.HTTPTransactions },
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewHTTPTransactionCollector(config.CollectInterval, config.CustomTargets.HTTPTransactions, logger)
+		},
+	},
+	"http": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.HTTPTargets, config.Egress}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewHTTPCollector(config.CollectInterval, config.CustomTargets.HTTPTargets, config.Egress, logger)
+		},
+	},
+	"tcp": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.TCPTargets, config.Egress}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewTCPCollector(config.CollectInterval, config.CustomTargets.TCPTargets, config.Egress, logger)
+		},
+	},
+	"dns": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.DNSServers, config.DNS, config.Egress}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewDNSCollector(config.CollectInterval, config.CustomTargets.DNSServers, config.DNS, config.Egress, logger)
+		},
+	},
+	"traceroute": {
+		settings: func(config *metrics.AgentConfig) interface{} {
+			return []interface{}{config.CustomTargets.TracerouteTargets, config.Traceroute, config.Egress}
+		},
+		build: func(config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+			return collectors.NewTracerouteCollector(
+				config.CollectInterval,
+				config.CustomTargets.TracerouteTargets,
+				config.Traceroute,
+				config.Egress,
+				logger,
+			)
+		},
+	},
+
+	// TODO: Implement system metrics collector
+	"system": {},
+}
+
+// CollectorNames returns the sorted names of the collectors that can be enabled
+func CollectorNames() []string {
+	var names []string
+	for name, factory := range collectorFactories {
+		if factory.build != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewCollector builds the named collector from config without starting it
+func NewCollector(name string, config *metrics.AgentConfig, logger *logrus.Logger) (metrics.MetricCollector, error) {
+	factory, exists := collectorFactories[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown collector type %q", name)
+	}
+	if factory.build == nil {
+		return nil, fmt.Errorf("collector %q is not yet implemented", name)
+	}
+	return factory.build(config, logger), nil
+}
+
+// newCollector builds the named collector, logging and returning nil if it is
+// unknown or not implemented
+func newCollector(name string, config *metrics.AgentConfig, logger *logrus.Logger) metrics.MetricCollector {
+	collector, err := NewCollector(name, config, logger)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"collector": name,
+			"error":     err,
+		}).Error("Failed to create collector")
+		return nil
+	}
+	return collector
+}
+
+// collectorChanged reports whether the named collector must be rebuilt to apply newConfig
+func collectorChanged(name string, oldConfig, newConfig *metrics.AgentConfig) bool {
+	factory, exists := collectorFactories[name]
+	if !exists || factory.settings == nil {
+		return true
+	}
+	return !reflect.DeepEqual(factory.settings(oldConfig), factory.settings(newConfig))
+}
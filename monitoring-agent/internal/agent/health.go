@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// Health report statuses
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFailing = "failing"
+)
+
+// HealthCheck is the outcome of one liveness or readiness check
+type HealthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// HealthReport is the result of a liveness or readiness probe
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// OK reports whether every check passed
+func (r HealthReport) OK() bool {
+	return r.Status == HealthStatusOK
+}
+
+// newHealthReport builds a report that fails when any check fails
+func newHealthReport(checks ...HealthCheck) HealthReport {
+	report := HealthReport{Status: HealthStatusOK, Checks: checks}
+	for _, check := range checks {
+		if !check.OK {
+			report.Status = HealthStatusFailing
+		}
+	}
+	return report
+}
+
+// Liveness reports whether the agent process is alive and its collection loop
+// is still ticking
+func (a *Agent) Liveness() HealthReport {
+	a.mutex.RLock()
+	running, startedAt := a.running, a.startedAt
+	maxCollectAge := a.config.Health.MaxCollectAge
+	a.mutex.RUnlock()
+
+	if !running {
+		return newHealthReport(HealthCheck{Name: "running", Detail: "agent is not running"})
+	}
+
+	return newHealthReport(
+		HealthCheck{Name: "running", OK: true, Detail: fmt.Sprintf("running since %s", startedAt.Format(time.RFC3339))},
+		a.collectionCheck(startedAt, maxCollectAge),
+	)
+}
+
+// Readiness reports whether the agent is doing useful work: its configuration
+// is valid, at least one collector started and metrics are reaching the backend
+func (a *Agent) Readiness() HealthReport {
+	a.mutex.RLock()
+	running, startedAt := a.running, a.startedAt
+	collectorCount := len(a.collectors)
+	transmitter, maxSendAge := a.transmitter, a.config.Health.MaxSendAge
+	a.mutex.RUnlock()
+
+	// The agent cannot be created from an invalid configuration
+	checks := []HealthCheck{{Name: "config", OK: true, Detail: "configuration is valid"}}
+	if !running {
+		checks = append(checks, HealthCheck{Name: "running", Detail: "agent is not running"})
+		return newHealthReport(checks...)
+	}
+
+	collectors := HealthCheck{Name: "collectors", OK: collectorCount > 0}
+	failed := 0
+	for _, stats := range a.stats.snapshot() {
+		if stats.State == CollectorStateFailed {
+			failed++
+		}
+	}
+	collectors.Detail = fmt.Sprintf("%d started, %d failed to start", collectorCount, failed)
+
+	return newHealthReport(append(checks, collectors, transmitterCheck(transmitter, &a.counters, startedAt, maxSendAge))...)
+}
+
+// collectionCheck fails when no collection cycle completed within max_collect_age
+func (a *Agent) collectionCheck(startedAt time.Time, maxAge time.Duration) HealthCheck {
+	check := HealthCheck{Name: "collection"}
+
+	lastCycle := startedAt
+	if last := a.lastCycle.Load(); last != 0 {
+		lastCycle = time.Unix(0, last)
+		check.Detail = fmt.Sprintf("last cycle completed %s ago", roundAge(time.Since(lastCycle)))
+	} else {
+		check.Detail = fmt.Sprintf("no cycle completed in %s", roundAge(time.Since(startedAt)))
+	}
+	check.OK = maxAge <= 0 || time.Since(lastCycle) <= maxAge
+	if !check.OK {
+		check.Detail += fmt.Sprintf(" (limit %s)", maxAge)
+	}
+	return check
+}
+
+// transmitterCheck passes while the backend is connected, or while the last
+// successful batch is within max_send_age so a brief reconnect is tolerated
+func transmitterCheck(transmitter metrics.MetricTransmitter, counters *transmitCounters, startedAt time.Time, maxAge time.Duration) HealthCheck {
+	check := HealthCheck{Name: "transmitter"}
+	if transmitter.IsConnected() {
+		check.OK = true
+		check.Detail = "connected to backend"
+		return check
+	}
+
+	lastSend := startedAt
+	if last := counters.lastSend.Load(); last != 0 {
+		lastSend = time.Unix(0, last)
+		check.Detail = fmt.Sprintf("disconnected, last batch sent %s ago", roundAge(time.Since(lastSend)))
+	} else {
+		check.Detail = "disconnected, no batch sent yet"
+	}
+	check.OK = maxAge > 0 && time.Since(lastSend) <= maxAge
+	if !check.OK {
+		check.Detail += fmt.Sprintf(" (limit %s)", maxAge)
+	}
+	return check
+}
+
+// roundAge rounds a duration for display in check details
+func roundAge(age time.Duration) time.Duration {
+	return age.Round(100 * time.Millisecond)
+}
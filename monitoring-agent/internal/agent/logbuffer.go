@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logBufferSize is the number of recent log lines kept for diagnostics
+const logBufferSize = 1000
+
+// logBuffer is a logrus hook keeping the most recent log lines in memory, so
+// that the diagnose command can include them without access to the agent's
+// stdout or journal
+type logBuffer struct {
+	mutex     sync.Mutex
+	lines     []string
+	next      int // Index the next line is written to once the buffer is full
+	formatter logrus.Formatter
+}
+
+func newLogBuffer() *logBuffer {
+	return &logBuffer{
+		lines: make([]string, 0, logBufferSize),
+		formatter: &logrus.TextFormatter{
+			DisableColors: true,
+			FullTimestamp: true,
+		},
+	}
+}
+
+// Levels returns the levels the hook fires for; the logger's own level still applies
+func (b *logBuffer) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire stores a formatted entry, replacing the oldest once the buffer is full
+func (b *logBuffer) Fire(entry *logrus.Entry) error {
+	formatted, err := b.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	line := strings.TrimSuffix(string(formatted), "\n")
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(b.lines) < logBufferSize {
+		b.lines = append(b.lines, line)
+		return nil
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % logBufferSize
+	return nil
+}
+
+// snapshot returns the buffered lines, oldest first
+func (b *logBuffer) snapshot() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.next:]...)
+	return append(lines, b.lines[:b.next]...)
+}
+
+// RecentLogs returns the agent's most recent log lines, oldest first
+func (a *Agent) RecentLogs() []string {
+	return a.logs.snapshot()
+}
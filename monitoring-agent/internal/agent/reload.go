@@ -0,0 +1,274 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/transmitter"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Reload applies a new configuration to the running agent. Collectors whose
+// settings are unchanged keep running, and with them their rate baselines;
+// the transmitter is only replaced when the backend or agent identity
+// changes. If the new configuration cannot be applied the agent keeps
+// running with the old one.
+func (a *Agent) Reload(ctx context.Context, newConfig *metrics.AgentConfig) error {
+	a.lifecycleMutex.Lock()
+	defer a.lifecycleMutex.Unlock()
+
+	// Planning and connecting can take a while, so only the swap below holds
+	// mutex; lifecycleMutex keeps Stop from running in between
+	a.mutex.RLock()
+	running, oldConfig := a.running, a.config
+	current := make(map[string]metrics.MetricCollector, len(a.collectors))
+	for _, collector := range a.collectors {
+		current[collector.Name()] = collector
+	}
+	a.mutex.RUnlock()
+
+	if !running {
+		return fmt.Errorf("agent is not running")
+	}
+
+	// Settings only read at startup keep their current values
+	pinned := pinStartupSettings(oldConfig, newConfig)
+	if len(pinned) > 0 {
+		a.logger.WithField("settings", pinned).Warn("Configuration changes require an agent restart to take effect")
+	}
+
+	changes := diffConfig(oldConfig, newConfig)
+	if len(changes) == 0 {
+		a.logger.Info("Configuration unchanged, nothing to reload")
+		return nil
+	}
+
+	// Plan the collectors before changing anything
+	next := make([]metrics.MetricCollector, 0, len(newConfig.Collectors))
+	var kept, replaced []metrics.MetricCollector
+	for _, name := range newConfig.Collectors {
+		if existing, running := current[name]; running && !collectorChanged(name, oldConfig, newConfig) {
+			next = append(next, existing)
+			kept = append(kept, existing)
+			continue
+		}
+		if collector := newCollector(name, newConfig, a.logger); collector != nil {
+			next = append(next, collector)
+			replaced = append(replaced, collector)
+		}
+	}
+	if len(next) == 0 {
+		return fmt.Errorf("no collectors were successfully initialized")
+	}
+
+	// Connect the new transmitter first so a failure leaves the old one in place
+	var newTransmitter *transmitter.WebSocketTransmitter
+	if transmitterChanged(oldConfig, newConfig) {
+		newTransmitter = transmitter.NewWebSocketTransmitter(
+			newConfig.BackendURL,
+			newConfig.Backend,
+			newConfig.AgentID,
+			newConfig.Location,
+			a.logger,
+		)
+		if err := newTransmitter.Connect(); err != nil {
+			return fmt.Errorf("failed to connect to backend: %w", err)
+		}
+	}
+
+	// Wait for an in-flight collection cycle or batch before swapping
+	a.reloadMutex.Lock()
+	defer a.reloadMutex.Unlock()
+
+	// The STAMP reflector's successor binds the same port, so the old
+	// reflector is stopped first; every other collector keeps running until
+	// its successor has started
+	stoppedFirst := make(map[string]metrics.MetricCollector)
+	for name, collector := range current {
+		if name == "stamp_reflector" && !containsCollector(kept, collector) {
+			a.stopCollector(collector)
+			stoppedFirst[name] = collector
+		}
+	}
+
+	if err := a.startReplacements(ctx, replaced); err != nil {
+		// Bring back what was stopped so the agent runs as before
+		for name, collector := range stoppedFirst {
+			a.startCollector(ctx, collector)
+			a.logger.WithField("collector", name).Info("Restarted collector after failed reload")
+		}
+		if newTransmitter != nil {
+			if err := newTransmitter.Disconnect(); err != nil {
+				a.logger.WithError(err).Error("Error disconnecting from backend")
+			}
+		}
+		return err
+	}
+
+	var stopped []string
+	for name, collector := range current {
+		if containsCollector(kept, collector) {
+			continue
+		}
+		if _, done := stoppedFirst[name]; !done {
+			a.stopCollector(collector)
+		}
+		a.stats.remove(name)
+		stopped = append(stopped, name)
+	}
+
+	collectors := kept
+	var started []string
+	for _, collector := range replaced {
+		a.stats.setState(collector.Name(), CollectorStateRunning, nil)
+		collectors = append(collectors, collector)
+		started = append(started, collector.Name())
+	}
+
+	a.mutex.Lock()
+	oldTransmitter := a.transmitter
+	oldTransmitterCancel := a.transmitterCancel
+	a.collectors = collectors
+	if newTransmitter != nil {
+		transmitterCtx, transmitterCancel := context.WithCancel(ctx)
+		newTransmitter.StartReconnectLoop(transmitterCtx)
+		a.transmitter = newTransmitter
+		a.transmitterCancel = transmitterCancel
+	}
+	a.config = newConfig
+	a.mutex.Unlock()
+
+	if newTransmitter != nil {
+		oldTransmitterCancel()
+		if err := oldTransmitter.Disconnect(); err != nil {
+			a.logger.WithError(err).Error("Error disconnecting from backend")
+		}
+	}
+	a.updateClockOffsetSource()
+
+	if newConfig.CollectInterval != oldConfig.CollectInterval {
+		// Replace an interval the collection loop has not picked up yet
+		select {
+		case <-a.intervalChan:
+		default:
+		}
+		a.intervalChan <- newConfig.CollectInterval
+	}
+
+	if level, err := logrus.ParseLevel(newConfig.LogLevel); err == nil {
+		a.logger.SetLevel(level)
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"changes":             changes,
+		"collectors_stopped":  stopped,
+		"collectors_started":  started,
+		"transmitter_changed": newTransmitter != nil,
+	}).Info("Configuration reloaded")
+
+	return nil
+}
+
+// startReplacements starts the collectors a reload adds or replaces. If one
+// fails, those already started are stopped again and its error is returned.
+func (a *Agent) startReplacements(ctx context.Context, replaced []metrics.MetricCollector) error {
+	for i, collector := range replaced {
+		if source, ok := collector.(metrics.EventSource); ok {
+			source.SetEventSink(a.queueEvent)
+		}
+		if err := collector.Start(ctx); err != nil {
+			for _, startedCollector := range replaced[:i] {
+				a.stopCollector(startedCollector)
+			}
+			return fmt.Errorf("failed to start collector %s: %w", collector.Name(), err)
+		}
+		a.logger.WithField("collector", collector.Name()).Info("Started collector")
+	}
+	return nil
+}
+
+// stopCollector stops a collector, logging rather than returning an error
+func (a *Agent) stopCollector(collector metrics.MetricCollector) {
+	if err := collector.Stop(); err != nil {
+		a.logger.WithFields(logrus.Fields{
+			"collector": collector.Name(),
+			"error":     err,
+		}).Error("Error stopping collector")
+	}
+}
+
+// pinStartupSettings copies settings that cannot change while the agent runs
+// from oldConfig into newConfig and returns the names of those that differed
+func pinStartupSettings(oldConfig, newConfig *metrics.AgentConfig) []string {
+	var pinned []string
+	if newConfig.BatchSize != oldConfig.BatchSize {
+		newConfig.BatchSize = oldConfig.BatchSize
+		pinned = append(pinned, "batch_size")
+	}
+	if newConfig.Control != oldConfig.Control {
+		newConfig.Control = oldConfig.Control
+		pinned = append(pinned, "control")
+	}
+	if newConfig.Health.Address != oldConfig.Health.Address {
+		newConfig.Health.Address = oldConfig.Health.Address
+		pinned = append(pinned, "health.address")
+	}
+	if newConfig.WatchConfig != oldConfig.WatchConfig {
+		newConfig.WatchConfig = oldConfig.WatchConfig
+		pinned = append(pinned, "watch_config")
+	}
+	return pinned
+}
+
+// transmitterChanged reports whether the backend connection must be re-established
+func transmitterChanged(oldConfig, newConfig *metrics.AgentConfig) bool {
+	return oldConfig.BackendURL != newConfig.BackendURL ||
+		!reflect.DeepEqual(oldConfig.Backend, newConfig.Backend) ||
+		oldConfig.AgentID != newConfig.AgentID ||
+		oldConfig.Location != newConfig.Location
+}
+
+// containsCollector reports whether collector is one of collectors
+func containsCollector(collectors []metrics.MetricCollector, collector metrics.MetricCollector) bool {
+	for _, candidate := range collectors {
+		if candidate == collector {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfig returns the yaml keys of the settings that differ between two
+// configurations, descending into nested sections
+func diffConfig(oldConfig, newConfig *metrics.AgentConfig) []string {
+	return diffValues("", reflect.ValueOf(*oldConfig), reflect.ValueOf(*newConfig))
+}
+
+// diffValues compares two values of the same struct type field by field
+func diffValues(prefix string, oldValue, newValue reflect.Value) []string {
+	var changes []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			key = field.Name
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		oldField, newField := oldValue.Field(i), newValue.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			changes = append(changes, diffValues(key, oldField, newField)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			changes = append(changes, key)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Collector states reported in CollectorStats
+const (
+	CollectorStateRunning = "running"
+	CollectorStateFailed  = "failed_to_start"
+)
+
+// CollectorStats describes the most recent runs of one collector
+type CollectorStats struct {
+	Name           string    `json:"name"`
+	State          string    `json:"state"`
+	LastRun        time.Time `json:"last_run"`
+	LastDurationMs float64   `json:"last_duration_ms"`
+	LastMetrics    int       `json:"last_metrics"`
+	LastError      string    `json:"last_error,omitempty"`
+	Runs           uint64    `json:"runs"`
+	Errors         uint64    `json:"errors"`
+}
+
+// collectorStatsTable tracks per-collector stats for the status API
+type collectorStatsTable struct {
+	mutex sync.RWMutex
+	stats map[string]*CollectorStats
+}
+
+// newCollectorStatsTable creates an empty stats table
+func newCollectorStatsTable() *collectorStatsTable {
+	return &collectorStatsTable{
+		stats: make(map[string]*CollectorStats),
+	}
+}
+
+// setState records whether a collector started, along with the start error
+func (t *collectorStatsTable) setState(name, state string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats := t.entry(name)
+	stats.State = state
+	stats.LastError = ""
+	if err != nil {
+		stats.LastError = err.Error()
+	}
+}
+
+// recordRun records the outcome of one Collect call
+func (t *collectorStatsTable) recordRun(name string, start time.Time, duration time.Duration, metricCount int, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats := t.entry(name)
+	stats.LastRun = start
+	stats.LastDurationMs = float64(duration.Nanoseconds()) / 1e6
+	stats.LastMetrics = metricCount
+	stats.LastError = ""
+	stats.Runs++
+	if err != nil {
+		stats.LastError = err.Error()
+		stats.Errors++
+	}
+}
+
+// remove forgets a collector that is no longer configured
+func (t *collectorStatsTable) remove(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.stats, name)
+}
+
+// snapshot returns a copy of every collector's stats ordered by name
+func (t *collectorStatsTable) snapshot() []CollectorStats {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	snapshot := make([]CollectorStats, 0, len(t.stats))
+	for _, stats := range t.stats {
+		snapshot = append(snapshot, *stats)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Name < snapshot[j].Name
+	})
+	return snapshot
+}
+
+// entry returns the stats for a collector, creating them if needed; callers hold the lock
+func (t *collectorStatsTable) entry(name string) *CollectorStats {
+	stats, exists := t.stats[name]
+	if !exists {
+		stats = &CollectorStats{Name: name}
+		t.stats[name] = stats
+	}
+	return stats
+}
+
+// transmitCounters counts metrics through the queue and out to the backend
+type transmitCounters struct {
+	metricsQueued   atomic.Uint64
+	metricsSent     atomic.Uint64
+	metricsDropped  atomic.Uint64 // Dropped because the queue was full or a batch failed to send
+	batchesSent     atomic.Uint64
+	batchesFailed   atomic.Uint64
+	lastSend        atomic.Int64 // Unix nanoseconds of the last successful batch
+	lastSendLatency atomic.Int64 // Nanoseconds taken by the last successful batch
+	lastSendError   atomic.Value // string, empty after a successful batch
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/transmitter"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// selfTelemetry reports the health of the agent itself: collector runs, the
+// metric queue, transmission to the backend and the Go runtime. Every metric
+// is tagged component=agent.
+func (a *Agent) selfTelemetry(timestamp time.Time) []metrics.Metric {
+	var telemetry []metrics.Metric
+	add := func(name string, value float64, unit string, metricType metrics.MetricType, tags map[string]string) {
+		merged := map[string]string{"component": "agent"}
+		for key, tag := range tags {
+			merged[key] = tag
+		}
+		telemetry = append(telemetry, metrics.Metric{
+			Name:      name,
+			Value:     value,
+			Unit:      unit,
+			Timestamp: timestamp,
+			Tags:      merged,
+			Type:      metricType,
+		})
+	}
+	boolValue := func(value bool) float64 {
+		if value {
+			return 1
+		}
+		return 0
+	}
+
+	// Collectors
+	for _, stats := range a.stats.snapshot() {
+		tags := map[string]string{"collector": stats.Name}
+		add("agent_collector_up", boolValue(stats.State == CollectorStateRunning), "boolean", metrics.MetricTypeGauge, tags)
+		if stats.Runs == 0 {
+			continue
+		}
+		add("agent_collector_duration_ms", stats.LastDurationMs, "ms", metrics.MetricTypeGauge, tags)
+		add("agent_collector_metrics", float64(stats.LastMetrics), "metrics", metrics.MetricTypeGauge, tags)
+		add("agent_collector_runs_total", float64(stats.Runs), "runs", metrics.MetricTypeCounter, tags)
+		add("agent_collector_errors_total", float64(stats.Errors), "errors", metrics.MetricTypeCounter, tags)
+	}
+
+	// Metric queue
+	add("agent_queue_length", float64(len(a.metricQueue)), "metrics", metrics.MetricTypeGauge, nil)
+	add("agent_queue_capacity", float64(cap(a.metricQueue)), "metrics", metrics.MetricTypeGauge, nil)
+	add("agent_metrics_dropped_total", float64(a.counters.metricsDropped.Load()), "metrics", metrics.MetricTypeCounter, nil)
+
+	// Transmission
+	add("agent_metrics_sent_total", float64(a.counters.metricsSent.Load()), "metrics", metrics.MetricTypeCounter, nil)
+	add("agent_batches_sent_total", float64(a.counters.batchesSent.Load()), "batches", metrics.MetricTypeCounter, nil)
+	add("agent_batches_failed_total", float64(a.counters.batchesFailed.Load()), "batches", metrics.MetricTypeCounter, nil)
+	if latency := a.counters.lastSendLatency.Load(); latency > 0 {
+		add("agent_send_latency_ms", float64(latency)/float64(time.Millisecond), "ms", metrics.MetricTypeGauge, nil)
+	}
+	add("agent_backend_connected", boolValue(a.transmitter.IsConnected()), "boolean", metrics.MetricTypeGauge, nil)
+	if wst, ok := a.transmitter.(*transmitter.WebSocketTransmitter); ok {
+		reconnects, failures := wst.ReconnectStats()
+		add("agent_reconnects_total", float64(reconnects), "reconnects", metrics.MetricTypeCounter, nil)
+		add("agent_reconnect_failures_total", float64(failures), "reconnects", metrics.MetricTypeCounter, nil)
+	}
+
+	// Go runtime
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	add("agent_goroutines", float64(runtime.NumGoroutine()), "goroutines", metrics.MetricTypeGauge, nil)
+	add("agent_heap_alloc_bytes", float64(memStats.HeapAlloc), "bytes", metrics.MetricTypeGauge, nil)
+	add("agent_heap_sys_bytes", float64(memStats.HeapSys), "bytes", metrics.MetricTypeGauge, nil)
+	add("agent_heap_objects", float64(memStats.HeapObjects), "objects", metrics.MetricTypeGauge, nil)
+	add("agent_gc_runs_total", float64(memStats.NumGC), "runs", metrics.MetricTypeCounter, nil)
+	add("agent_gc_pause_total_ms", float64(memStats.PauseTotalNs)/float64(time.Millisecond), "ms", metrics.MetricTypeCounter, nil)
+	if memStats.NumGC > 0 {
+		lastPause := memStats.PauseNs[(memStats.NumGC+255)%256]
+		add("agent_gc_last_pause_ms", float64(lastPause)/float64(time.Millisecond), "ms", metrics.MetricTypeGauge, nil)
+	}
+
+	return telemetry
+}
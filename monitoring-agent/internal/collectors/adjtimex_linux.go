@@ -0,0 +1,35 @@
+package collectors
+
+import (
+	"syscall"
+	"time"
+)
+
+// Kernel clock discipline constants from linux/timex.h
+const (
+	timexStatusUnsync = 0x0040 // STA_UNSYNC
+	timexStatusNano   = 0x2000 // STA_NANO, offset is in nanoseconds rather than microseconds
+	timexStateError   = 5      // TIME_ERROR
+)
+
+// readKernelClock reads the kernel clock discipline state with a read-only adjtimex call
+func readKernelClock() (*kernelClock, error) {
+	var timex syscall.Timex
+	state, err := syscall.Adjtimex(&timex)
+	if err != nil {
+		return nil, err
+	}
+
+	offsetUnit := time.Microsecond
+	if timex.Status&timexStatusNano != 0 {
+		offsetUnit = time.Nanosecond
+	}
+
+	return &kernelClock{
+		synced:       state != timexStateError && timex.Status&timexStatusUnsync == 0,
+		offset:       time.Duration(int64(timex.Offset)) * offsetUnit,
+		maxError:     time.Duration(int64(timex.Maxerror)) * time.Microsecond,
+		estError:     time.Duration(int64(timex.Esterror)) * time.Microsecond,
+		frequencyPPM: float64(int64(timex.Freq)) / 65536,
+	}, nil
+}
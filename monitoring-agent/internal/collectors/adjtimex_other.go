@@ -0,0 +1,8 @@
+//go:build !linux
+
+package collectors
+
+// readKernelClock is only implemented on Linux
+func readKernelClock() (*kernelClock, error) {
+	return nil, errKernelClockUnsupported
+}
@@ -0,0 +1,393 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// conntrackPerCPUStats are the per-CPU statistics that indicate the table is under pressure
+var conntrackPerCPUStats = map[string]bool{
+	"insert_failed":  true,
+	"drop":           true,
+	"early_drop":     true,
+	"search_restart": true,
+}
+
+// errConntrackNetlinkUnsupported is returned where ctnetlink is not available
+var errConntrackNetlinkUnsupported = errors.New("conntrack netlink is not supported on this platform")
+
+// ConntrackCollector collects netfilter connection tracking table usage,
+// per-CPU statistics and the busiest flows by protocol and destination
+type ConntrackCollector struct {
+	interval    time.Duration
+	config      metrics.ConntrackConfig
+	procRoot    string
+	rates       *counterRates
+	flowsWarned bool
+	logger      *logrus.Logger
+}
+
+// conntrackFlow identifies a tracked connection by protocol and original destination
+type conntrackFlow struct {
+	protocol    string
+	destination string
+	port        string
+}
+
+// NewConntrackCollector creates a new connection tracking collector
+func NewConntrackCollector(interval time.Duration, config metrics.ConntrackConfig, procRoot string, logger *logrus.Logger) *ConntrackCollector {
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+
+	return &ConntrackCollector{
+		interval: interval,
+		config:   config,
+		procRoot: procRoot,
+		rates:    newCounterRates(),
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (cc *ConntrackCollector) Name() string {
+	return "conntrack"
+}
+
+// Interval returns the collection interval
+func (cc *ConntrackCollector) Interval() time.Duration {
+	return cc.interval
+}
+
+// Start initializes the collector
+func (cc *ConntrackCollector) Start(ctx context.Context) error {
+	cc.logger.Info("Starting conntrack collector")
+
+	if _, err := cc.readSysctl("nf_conntrack_max"); err != nil {
+		return fmt.Errorf("nf_conntrack is not available: %w", err)
+	}
+
+	// Initialize with first measurement
+	perCPU, _, err := cc.readStats()
+	if err != nil {
+		return fmt.Errorf("failed to get initial conntrack statistics: %w", err)
+	}
+	cc.observePerCPU(perCPU, time.Now())
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (cc *ConntrackCollector) Stop() error {
+	cc.logger.Info("Stopping conntrack collector")
+	return nil
+}
+
+// Collect gathers connection tracking metrics
+func (cc *ConntrackCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	count, err := cc.readSysctl("nf_conntrack_count")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conntrack count: %w", err)
+	}
+	max, err := cc.readSysctl("nf_conntrack_max")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conntrack max: %w", err)
+	}
+
+	fillRatio := 0.0
+	if max > 0 {
+		fillRatio = float64(count) / float64(max) * 100
+	}
+
+	tags := map[string]string{}
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "conntrack_entries",
+			Value:     float64(count),
+			Unit:      "entries",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "conntrack_entries_max",
+			Value:     float64(max),
+			Unit:      "entries",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "conntrack_fill_ratio_percent",
+			Value:     fillRatio,
+			Unit:      "percent",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	// Per-CPU statistics
+	perCPU, totals, err := cc.readStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conntrack statistics: %w", err)
+	}
+	for cpu, stats := range perCPU {
+		cpuTags := map[string]string{
+			"cpu": strconv.Itoa(cpu),
+		}
+		for field, value := range stats {
+			if !conntrackPerCPUStats[field] {
+				continue
+			}
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "conntrack_cpu_" + field + "_total",
+				Value:     float64(value),
+				Unit:      "count",
+				Timestamp: currentTime,
+				Tags:      cpuTags,
+				Type:      metrics.MetricTypeCounter,
+			})
+		}
+	}
+	rates := cc.observePerCPU(perCPU, currentTime)
+	for field, value := range totals {
+		// "entries" is a gauge already covered by nf_conntrack_count
+		if field == "entries" {
+			continue
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "conntrack_" + field + "_total",
+			Value:     float64(value),
+			Unit:      "count",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeCounter,
+		})
+
+		if rate, ok := rates[field]; ok {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "conntrack_" + field + "_per_sec",
+				Value:     rate,
+				Unit:      "count/sec",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+	collectedMetrics = append(collectedMetrics, cc.rates.resetMetric("conntrack_counter_resets_total", currentTime))
+
+	// Busiest flows are best effort: listing the table needs procfs support or CAP_NET_ADMIN
+	if cc.config.TopFlows > 0 {
+		flows, err := cc.readFlows()
+		if err != nil {
+			if !cc.flowsWarned {
+				cc.logger.WithError(err).Warn("Conntrack flow listing unavailable")
+				cc.flowsWarned = true
+			}
+		} else {
+			collectedMetrics = append(collectedMetrics, cc.flowMetrics(flows, currentTime)...)
+		}
+	}
+
+	cc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected conntrack metrics")
+	return collectedMetrics, nil
+}
+
+// observePerCPU records the pressure statistics of every CPU as separate
+// 32-bit counters, since each CPU's counter wraps on its own, and returns their
+// per-second rates summed across CPUs. A statistic has no rate while any CPU
+// lacks one, such as after a reset.
+func (cc *ConntrackCollector) observePerCPU(perCPU []map[string]int64, timestamp time.Time) map[string]float64 {
+	rates := make(map[string]float64)
+	incomplete := make(map[string]bool)
+
+	for cpu, stats := range perCPU {
+		for field, value := range stats {
+			if !conntrackPerCPUStats[field] {
+				continue
+			}
+			_, rate, ok := cc.rates.observe(field+"|"+strconv.Itoa(cpu), uint64(value), counter32, timestamp)
+			if !ok {
+				incomplete[field] = true
+				continue
+			}
+			rates[field] += rate
+		}
+	}
+	cc.rates.sweep()
+
+	for field := range incomplete {
+		delete(rates, field)
+	}
+	return rates
+}
+
+// readSysctl reads an integer from /proc/sys/net/netfilter
+func (cc *ConntrackCollector) readSysctl(name string) (int64, error) {
+	data, err := os.ReadFile(procPath(cc.procRoot, "sys", "net", "netfilter", name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readStats parses /proc/net/stat/nf_conntrack, a header of field names
+// followed by one line of hexadecimal values per CPU, and returns the
+// per-CPU values along with their sums
+func (cc *ConntrackCollector) readStats() ([]map[string]int64, map[string]int64, error) {
+	file, err := os.Open(procPath(cc.procRoot, "net", "stat", "nf_conntrack"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil, nil, fmt.Errorf("empty conntrack statistics")
+	}
+	header := strings.Fields(scanner.Text())
+
+	var perCPU []map[string]int64
+	totals := make(map[string]int64)
+
+	for scanner.Scan() {
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(header) {
+			continue
+		}
+
+		stats := make(map[string]int64, len(header))
+		for i, field := range header {
+			value, err := strconv.ParseUint(values[i], 16, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid value for %s: %w", field, err)
+			}
+			stats[field] = int64(value)
+			totals[field] += int64(value)
+		}
+		perCPU = append(perCPU, stats)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return perCPU, totals, nil
+}
+
+// readFlows counts tracked connections by flow, preferring /proc/net/nf_conntrack
+// and falling back to a ctnetlink dump where the procfs listing is not compiled in
+func (cc *ConntrackCollector) readFlows() (map[conntrackFlow]int, error) {
+	flows, err := cc.readProcFlows()
+	if err == nil {
+		return flows, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return readConntrackNetlinkFlows()
+}
+
+// readProcFlows parses /proc/net/nf_conntrack, e.g.
+// "ipv4 2 tcp 6 431999 ESTABLISHED src=10.0.0.1 dst=10.0.0.2 sport=51000 dport=443 ..."
+func (cc *ConntrackCollector) readProcFlows() (map[conntrackFlow]int, error) {
+	file, err := os.Open(procPath(cc.procRoot, "net", "nf_conntrack"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	flows := make(map[conntrackFlow]int)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		flow := conntrackFlow{protocol: fields[2]}
+		// The first dst/dport pair belongs to the original direction
+		for _, field := range fields[5:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			if key == "dst" && flow.destination == "" {
+				flow.destination = value
+			}
+			if key == "dport" && flow.port == "" {
+				flow.port = value
+			}
+		}
+
+		flows[flow]++
+	}
+
+	return flows, scanner.Err()
+}
+
+// flowMetrics reports connection counts per protocol and for the busiest destinations
+func (cc *ConntrackCollector) flowMetrics(flows map[conntrackFlow]int, timestamp time.Time) []metrics.Metric {
+	var collectedMetrics []metrics.Metric
+
+	byProtocol := make(map[string]int)
+	sortedFlows := make([]conntrackFlow, 0, len(flows))
+	for flow, count := range flows {
+		byProtocol[flow.protocol] += count
+		sortedFlows = append(sortedFlows, flow)
+	}
+
+	for protocol, count := range byProtocol {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "conntrack_protocol_entries",
+			Value:     float64(count),
+			Unit:      "entries",
+			Timestamp: timestamp,
+			Tags: map[string]string{
+				"protocol": protocol,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	sort.Slice(sortedFlows, func(i, j int) bool {
+		return flows[sortedFlows[i]] > flows[sortedFlows[j]]
+	})
+	if len(sortedFlows) > cc.config.TopFlows {
+		sortedFlows = sortedFlows[:cc.config.TopFlows]
+	}
+
+	for _, flow := range sortedFlows {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "conntrack_top_flow_entries",
+			Value:     float64(flows[flow]),
+			Unit:      "entries",
+			Timestamp: timestamp,
+			Tags: map[string]string{
+				"protocol":    flow.protocol,
+				"destination": flow.destination,
+				"port":        flow.port,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	return collectedMetrics
+}
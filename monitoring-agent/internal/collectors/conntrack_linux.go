@@ -0,0 +1,79 @@
+package collectors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// ctnetlink constants from linux/netfilter/nfnetlink.h and nfnetlink_conntrack.h
+const (
+	netlinkNetfilter    = 12
+	ctnetlinkGetMessage = 1<<8 | 1 // NFNL_SUBSYS_CTNETLINK << 8 | IPCTNL_MSG_CT_GET
+	nfgenmsgSize        = 4
+	ctaTupleOrig        = 1
+	ctaTupleIP          = 1
+	ctaTupleProto       = 2
+	ctaIPv4Dst          = 2
+	ctaIPv6Dst          = 4
+	ctaProtoNum         = 1
+	ctaProtoDstPort     = 3
+)
+
+// conntrackProtocolNames matches the protocol names used by /proc/net/nf_conntrack
+var conntrackProtocolNames = map[uint8]string{
+	1:   "icmp",
+	6:   "tcp",
+	17:  "udp",
+	33:  "dccp",
+	47:  "gre",
+	58:  "icmpv6",
+	132: "sctp",
+	136: "udplite",
+}
+
+// readConntrackNetlinkFlows dumps the conntrack table via ctnetlink (requires CAP_NET_ADMIN)
+func readConntrackNetlinkFlows() (map[conntrackFlow]int, error) {
+	// AF_UNSPEC dumps entries of every address family
+	request := make([]byte, nfgenmsgSize)
+	request[0] = syscall.AF_UNSPEC
+
+	messages, err := netlinkDump(netlinkNetfilter, ctnetlinkGetMessage, request)
+	if err != nil {
+		return nil, fmt.Errorf("ctnetlink dump failed: %w", err)
+	}
+
+	flows := make(map[conntrackFlow]int)
+	for _, message := range messages {
+		if len(message.Data) < nfgenmsgSize {
+			continue
+		}
+
+		attrs := parseNetlinkAttrs(message.Data[nfgenmsgSize:])
+		tuple := parseNetlinkAttrs(attrs[ctaTupleOrig])
+		ip := parseNetlinkAttrs(tuple[ctaTupleIP])
+		proto := parseNetlinkAttrs(tuple[ctaTupleProto])
+
+		var flow conntrackFlow
+		if dst := ip[ctaIPv4Dst]; len(dst) == net.IPv4len {
+			flow.destination = net.IP(dst).String()
+		} else if dst := ip[ctaIPv6Dst]; len(dst) == net.IPv6len {
+			flow.destination = net.IP(dst).String()
+		}
+		if num := proto[ctaProtoNum]; len(num) == 1 {
+			flow.protocol = conntrackProtocolNames[num[0]]
+			if flow.protocol == "" {
+				flow.protocol = strconv.Itoa(int(num[0]))
+			}
+		}
+		if port := proto[ctaProtoDstPort]; len(port) == 2 {
+			flow.port = strconv.Itoa(int(binary.BigEndian.Uint16(port)))
+		}
+
+		flows[flow]++
+	}
+
+	return flows, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package collectors
+
+// readConntrackNetlinkFlows is only implemented on Linux
+func readConntrackNetlinkFlows() (map[conntrackFlow]int, error) {
+	return nil, errConntrackNetlinkUnsupported
+}
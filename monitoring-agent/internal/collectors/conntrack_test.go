@@ -0,0 +1,94 @@
+package collectors
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestConntrackCollector(procRoot string) *ConntrackCollector {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewConntrackCollector(0, metrics.ConntrackConfig{}, procRoot, logger)
+}
+
+func TestConntrackReadStats(t *testing.T) {
+	collector := newTestConntrackCollector("testdata/proc")
+
+	perCPU, totals, err := collector.readStats()
+	if err != nil {
+		t.Fatalf("readStats() error = %v", err)
+	}
+	if len(perCPU) != 2 {
+		t.Fatalf("readStats() = %d CPUs, want 2 without the truncated line", len(perCPU))
+	}
+
+	tests := []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"cpu0 insert_failed", perCPU[0]["insert_failed"], 2},
+		{"cpu0 drop", perCPU[0]["drop"], 0x20},
+		{"cpu1 drop", perCPU[1]["drop"], 0xfffffff0},
+		{"cpu1 search_restart", perCPU[1]["search_restart"], 0x100},
+		{"total invalid", totals["invalid"], 0x1f + 4},
+		{"total drop", totals["drop"], 0x20 + 0xfffffff0},
+		{"total entries", totals["entries"], 2 * 0x2a},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestConntrackReadStatsMissing(t *testing.T) {
+	collector := newTestConntrackCollector("testdata/proc/missing")
+	if _, _, err := collector.readStats(); err == nil {
+		t.Error("readStats() without nf_conntrack succeeded, want an error")
+	}
+}
+
+func TestConntrackObservePerCPU(t *testing.T) {
+	collector := newTestConntrackCollector("testdata/proc")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stats := func(drop0, drop1 int64) []map[string]int64 {
+		return []map[string]int64{
+			{"drop": drop0, "insert_failed": 2, "found": 3},
+			{"drop": drop1, "insert_failed": 0, "found": 0},
+		}
+	}
+
+	if rates := collector.observePerCPU(stats(0x20, 0xfffffff0), start); len(rates) != 0 {
+		t.Errorf("observePerCPU() first reading = %v, want no rates", rates)
+	}
+
+	// CPU 1 wraps while the sum across CPUs is beyond 32 bits
+	rates := collector.observePerCPU(stats(0x25, 0x10), start.Add(10*time.Second))
+	if got, want := rates["drop"], float64(5+0x20)/10; got != want {
+		t.Errorf("drop rate = %v, want %v", got, want)
+	}
+	if got := rates["insert_failed"]; got != 0 {
+		t.Errorf("insert_failed rate = %v, want 0", got)
+	}
+	if _, ok := rates["found"]; ok {
+		t.Error("observePerCPU() reported a rate for a statistic outside the pressure set")
+	}
+	if resets := collector.rates.resetCount(); resets != 0 {
+		t.Errorf("resetCount() = %d after a wrap, want 0", resets)
+	}
+
+	// A reset on one CPU withholds the summed rate
+	rates = collector.observePerCPU(stats(0x30, 0x08), start.Add(20*time.Second))
+	if _, ok := rates["drop"]; ok {
+		t.Errorf("drop rate after a reset = %v, want none", rates["drop"])
+	}
+	if resets := collector.rates.resetCount(); resets != 1 {
+		t.Errorf("resetCount() = %d after a reset, want 1", resets)
+	}
+}
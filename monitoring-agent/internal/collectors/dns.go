@@ -0,0 +1,292 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSCollector measures resolution time and success for a set of names
+// against each configured DNS server
+type DNSCollector struct {
+	interval time.Duration
+	servers  []string
+	config   metrics.DNSConfig
+	egress   metrics.EgressConfig
+	path     *egressPath
+	logger   *logrus.Logger
+}
+
+// NewDNSCollector creates a new DNS resolution collector
+func NewDNSCollector(interval time.Duration, servers []string, config metrics.DNSConfig, egress metrics.EgressConfig, logger *logrus.Logger) *DNSCollector {
+	return &DNSCollector{
+		interval: interval,
+		servers:  servers,
+		config:   config,
+		egress:   egress,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (dc *DNSCollector) Name() string {
+	return "dns"
+}
+
+// Interval returns the collection interval
+func (dc *DNSCollector) Interval() time.Duration {
+	return dc.interval
+}
+
+// Start initializes the collector
+func (dc *DNSCollector) Start(ctx context.Context) error {
+	dc.logger.WithFields(logrus.Fields{
+		"servers": dc.servers,
+		"names":   dc.config.Names,
+	}).Info("Starting DNS collector")
+
+	if len(dc.servers) == 0 {
+		return fmt.Errorf("no DNS servers configured")
+	}
+	if len(dc.config.Names) == 0 {
+		return fmt.Errorf("no DNS names configured")
+	}
+
+	// Queries go straight to the server, so only the source, interface and family apply
+	global := dc.egress
+	global.ProxyURL = ""
+	path, err := newEgressPath(global, metrics.EgressConfig{})
+	if err != nil {
+		return fmt.Errorf("invalid egress for DNS: %w", err)
+	}
+	dc.path = path
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (dc *DNSCollector) Stop() error {
+	dc.logger.Info("Stopping DNS collector")
+	return nil
+}
+
+// Collect resolves every name against every server once
+func (dc *DNSCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for _, server := range dc.servers {
+		address := dnsServerAddress(server)
+		for _, name := range dc.config.Names {
+			tags := dc.path.tagged(map[string]string{
+				"server":      server,
+				"name":        name,
+				"record_type": dc.config.RecordType,
+			})
+
+			answers, resolveTime, err := dc.resolve(ctx, address, name)
+			successTags := mergeTags(tags, map[string]string{"error": "none"})
+			if err != nil {
+				successTags["error"] = dnsErrorCategory(err)
+				dc.logger.WithFields(logrus.Fields{
+					"server": server,
+					"name":   name,
+					"error":  err,
+				}).Warn("DNS resolution failed")
+			}
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "dns_resolution_success",
+				Value:     boolToFloat(err == nil),
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      successTags,
+				Type:      metrics.MetricTypeGauge,
+			})
+			if err != nil {
+				continue
+			}
+			collectedMetrics = append(collectedMetrics,
+				metrics.Metric{
+					Name:      "dns_resolution_time_ms",
+					Value:     durationToMs(resolveTime),
+					Unit:      "ms",
+					Timestamp: currentTime,
+					Tags:      tags,
+					Type:      metrics.MetricTypeGauge,
+				},
+				metrics.Metric{
+					Name:      "dns_answer_count",
+					Value:     float64(answers),
+					Unit:      "records",
+					Timestamp: currentTime,
+					Tags:      tags,
+					Type:      metrics.MetricTypeGauge,
+				},
+			)
+		}
+	}
+
+	dc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected DNS metrics")
+	return collectedMetrics, nil
+}
+
+// resolve queries the server at address for name, returning the number of
+// answers of the configured record type
+func (dc *DNSCollector) resolve(ctx context.Context, address, name string) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, dc.config.Timeout)
+	defer cancel()
+
+	// Queries are sent directly rather than through net.Resolver, which would
+	// answer from /etc/hosts and hide the server's rcode
+	fqdn, err := dnsmessage.NewName(dnsFQDN(name))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+	queryType := dnsRecordTypes[dc.config.RecordType]
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.Intn(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  fqdn,
+			Type:  queryType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	start := time.Now()
+	response, err := dc.exchange(ctx, "udp", address, packet, query.ID)
+	if err == nil && response.Truncated {
+		response, err = dc.exchange(ctx, "tcp", address, packet, query.ID)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	resolveTime := time.Since(start)
+
+	if response.RCode != dnsmessage.RCodeSuccess {
+		return 0, 0, &dnsRCodeError{rcode: response.RCode}
+	}
+	answers := 0
+	for _, answer := range response.Answers {
+		if answer.Header.Type == queryType {
+			answers++
+		}
+	}
+	return answers, resolveTime, nil
+}
+
+// exchange sends a packed query over network and returns the matching response
+func (dc *DNSCollector) exchange(ctx context.Context, network, address string, packet []byte, id uint16) (*dnsmessage.Message, error) {
+	conn, err := dc.path.dialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// DNS over TCP prefixes every message with its length
+	if network == "tcp" {
+		packet = append([]byte{byte(len(packet) >> 8), byte(len(packet))}, packet...)
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, 65535)
+	for {
+		var n int
+		if network == "tcp" {
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return nil, err
+			}
+			n = int(length[0])<<8 | int(length[1])
+			if _, err := io.ReadFull(conn, buffer[:n]); err != nil {
+				return nil, err
+			}
+		} else if n, err = conn.Read(buffer); err != nil {
+			return nil, err
+		}
+
+		var response dnsmessage.Message
+		if err := response.Unpack(buffer[:n]); err != nil || !response.Response || response.ID != id {
+			// Ignore stray or malformed datagrams until the deadline
+			if network == "tcp" {
+				return nil, fmt.Errorf("invalid response from %s", address)
+			}
+			continue
+		}
+		return &response, nil
+	}
+}
+
+// dnsRecordTypes maps the configured record types to their query types
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+// dnsRCodeError reports a response whose rcode is not NOERROR
+type dnsRCodeError struct {
+	rcode dnsmessage.RCode
+}
+
+func (e *dnsRCodeError) Error() string {
+	return "server responded with rcode " + strings.TrimPrefix(e.rcode.String(), "RCode")
+}
+
+// dnsFQDN adds the trailing dot of a fully qualified name
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// dnsServerAddress adds the DNS port to a server given without one
+func dnsServerAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "53")
+}
+
+// dnsErrorCategory classifies a resolution failure for the error tag
+func dnsErrorCategory(err error) string {
+	var rcodeErr *dnsRCodeError
+	if errors.As(err, &rcodeErr) {
+		switch rcodeErr.rcode {
+		case dnsmessage.RCodeNameError:
+			return "nxdomain"
+		case dnsmessage.RCodeServerFailure:
+			return "servfail"
+		case dnsmessage.RCodeRefused:
+			return "refused"
+		}
+		return "other"
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return "other"
+}
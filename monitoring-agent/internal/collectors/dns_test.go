@@ -0,0 +1,116 @@
+package collectors
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsTestResponse answers query with two A records, or with none and the
+// truncated bit set so the client retries over TCP
+func dnsTestResponse(t *testing.T, query []byte, truncated bool) []byte {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		t.Errorf("server: invalid query: %v", err)
+		return nil
+	}
+	response := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: request.ID, Response: true, Truncated: truncated},
+		Questions: request.Questions,
+	}
+	if !truncated {
+		for _, ip := range [][4]byte{{192, 0, 2, 1}, {192, 0, 2, 2}} {
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: request.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: ip},
+			})
+		}
+	}
+	packed, err := response.Pack()
+	if err != nil {
+		t.Errorf("server: failed to pack response: %v", err)
+	}
+	return packed
+}
+
+// startTestDNSServer serves truncated responses over UDP and full ones over
+// TCP on the same loopback port and returns its address
+func startTestDNSServer(t *testing.T) string {
+	udp, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { udp.Close() })
+	tcp, err := net.Listen("tcp4", udp.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { tcp.Close() })
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, peer, err := udp.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			udp.WriteTo(dnsTestResponse(t, buffer[:n], true), peer)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err == nil {
+				query := make([]byte, int(length[0])<<8|int(length[1]))
+				if _, err := io.ReadFull(conn, query); err == nil {
+					response := dnsTestResponse(t, query, false)
+					conn.Write(append([]byte{byte(len(response) >> 8), byte(len(response))}, response...))
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	return udp.LocalAddr().String()
+}
+
+func TestDNSCollectorFamilyRestrictedEgress(t *testing.T) {
+	address := startTestDNSServer(t)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	collector := NewDNSCollector(time.Minute, []string{address},
+		metrics.DNSConfig{Names: []string{"example.com"}, RecordType: "A", Timeout: 2 * time.Second},
+		metrics.EgressConfig{SourceAddress: "127.0.0.1", IPFamily: "ipv4"},
+		logger)
+	if err := collector.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	collected, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	success := findMetric(collected, "dns_resolution_success")
+	if success == nil || success.Value != 1 {
+		t.Fatalf("dns_resolution_success = %+v, want 1", success)
+	}
+	if want := "source=127.0.0.1,family=ipv4"; success.Tags["egress"] != want {
+		t.Errorf("dns_resolution_success egress = %q, want %q", success.Tags["egress"], want)
+	}
+	// The UDP response is truncated, so the answers arrive over TCP
+	if answers := findMetric(collected, "dns_answer_count"); answers == nil || answers.Value != 2 {
+		t.Errorf("dns_answer_count = %+v, want 2", answers)
+	}
+}
@@ -0,0 +1,252 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"golang.org/x/net/proxy"
+)
+
+// egressDirect in a target's proxy_url bypasses the global proxy
+const egressDirect = "direct"
+
+// errBindToDeviceUnsupported is returned when an interface is configured off Linux
+var errBindToDeviceUnsupported = errors.New("interface binding is only supported on Linux")
+
+// egressPath is the network path a probe leaves the host on: an optional proxy,
+// source address, bound interface and IP family
+type egressPath struct {
+	metrics.EgressConfig
+	proxy *url.URL
+}
+
+// newEgressPath merges a target's egress settings over the global ones; each
+// setting the target leaves empty keeps its global value
+func newEgressPath(global, target metrics.EgressConfig) (*egressPath, error) {
+	merged := global
+	if target.ProxyURL != "" {
+		merged.ProxyURL = target.ProxyURL
+	}
+	if target.SourceAddress != "" {
+		merged.SourceAddress = target.SourceAddress
+	}
+	if target.Interface != "" {
+		merged.Interface = target.Interface
+	}
+	if target.IPFamily != "" {
+		merged.IPFamily = target.IPFamily
+	}
+	if merged.ProxyURL == egressDirect {
+		merged.ProxyURL = ""
+	}
+
+	path := &egressPath{EgressConfig: merged}
+	if merged.ProxyURL != "" {
+		proxyURL, err := url.Parse(merged.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		path.proxy = proxyURL
+	}
+	if merged.SourceAddress != "" && net.ParseIP(merged.SourceAddress) == nil {
+		return nil, fmt.Errorf("invalid source_address %q", merged.SourceAddress)
+	}
+	if merged.Interface != "" && !bindToDeviceSupported {
+		return nil, errBindToDeviceUnsupported
+	}
+
+	return path, nil
+}
+
+// tag describes the path for the egress metric tag, empty for the default route.
+// Proxy credentials are left out.
+func (e *egressPath) tag() string {
+	var parts []string
+	if e.proxy != nil {
+		parts = append(parts, "proxy="+e.proxy.Scheme+"://"+e.proxy.Host)
+	}
+	if e.Interface != "" {
+		parts = append(parts, "interface="+e.Interface)
+	}
+	if e.SourceAddress != "" {
+		parts = append(parts, "source="+e.SourceAddress)
+	}
+	if e.IPFamily != "" {
+		parts = append(parts, "family="+e.IPFamily)
+	}
+	return strings.Join(parts, ",")
+}
+
+// tagged adds the egress tag to tags when a non-default path is configured
+func (e *egressPath) tagged(tags map[string]string) map[string]string {
+	if egress := e.tag(); egress != "" {
+		tags["egress"] = egress
+	}
+	return tags
+}
+
+// network narrows "tcp", "udp" or "ip" to the preferred IP family
+func (e *egressPath) network(network string) string {
+	switch e.IPFamily {
+	case "ipv4":
+		return network + "4"
+	case "ipv6":
+		return network + "6"
+	}
+	return network
+}
+
+// dialContext connects directly from the configured source address and interface
+func (e *egressPath) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	if e.SourceAddress != "" {
+		ip := net.ParseIP(e.SourceAddress)
+		if strings.HasPrefix(network, "udp") {
+			dialer.LocalAddr = &net.UDPAddr{IP: ip}
+		} else {
+			dialer.LocalAddr = &net.TCPAddr{IP: ip}
+		}
+	}
+	if e.Interface != "" {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			var bindErr error
+			if err := conn.Control(func(fd uintptr) {
+				bindErr = bindToDevice(fd, e.Interface)
+			}); err != nil {
+				return err
+			}
+			return bindErr
+		}
+	}
+	return dialer.DialContext(ctx, e.network(network), address)
+}
+
+// Dial implements proxy.Dialer so the path can carry SOCKS5 connections
+func (e *egressPath) Dial(network, address string) (net.Conn, error) {
+	return e.dialContext(context.Background(), network, address)
+}
+
+// DialContext implements proxy.ContextDialer
+func (e *egressPath) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return e.dialContext(ctx, network, address)
+}
+
+// dialTCP opens a TCP connection to address, through the proxy when one is configured
+func (e *egressPath) dialTCP(ctx context.Context, address string) (net.Conn, error) {
+	if e.proxy == nil {
+		return e.dialContext(ctx, "tcp", address)
+	}
+
+	switch e.proxy.Scheme {
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(e.proxy, e)
+		if err != nil {
+			return nil, err
+		}
+		contextDialer, ok := dialer.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New("SOCKS5 dialer does not support contexts")
+		}
+		return contextDialer.DialContext(ctx, "tcp", address)
+
+	case "http", "https":
+		return e.dialHTTPConnect(ctx, address)
+	}
+
+	return nil, fmt.Errorf("unsupported proxy scheme %q", e.proxy.Scheme)
+}
+
+// dialHTTPConnect opens a tunnel to address with an HTTP CONNECT request
+func (e *egressPath) dialHTTPConnect(ctx context.Context, address string) (net.Conn, error) {
+	proxyAddress := e.proxy.Host
+	if e.proxy.Port() == "" {
+		port := "80"
+		if e.proxy.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(e.proxy.Hostname(), port)
+	}
+
+	conn, err := e.dialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if e.proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: e.proxy.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("proxy TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	// Abort the exchange if the context ends while waiting on the proxy
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := e.proxy.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT: %s", response.Status)
+	}
+
+	// Keep anything the target sent right after the response, such as an SSH banner
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn is a connection whose first bytes were read ahead into a buffer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read drains the buffer before reading from the connection
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// httpTransport returns a transport that dials over the path; HTTP, HTTPS and
+// SOCKS5 proxies are handled by net/http
+func (e *egressPath) httpTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if e.proxy != nil {
+		transport.Proxy = http.ProxyURL(e.proxy)
+	}
+	transport.DialContext = e.dialContext
+	return transport
+}
@@ -0,0 +1,11 @@
+package collectors
+
+import "syscall"
+
+// bindToDeviceSupported reports whether probes can be bound to an interface
+const bindToDeviceSupported = true
+
+// bindToDevice restricts a socket to one interface with SO_BINDTODEVICE
+func bindToDevice(fd uintptr, device string) error {
+	return syscall.BindToDevice(int(fd), device)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package collectors
+
+// bindToDeviceSupported reports whether probes can be bound to an interface
+const bindToDeviceSupported = false
+
+// bindToDevice is only implemented on Linux
+func bindToDevice(fd uintptr, device string) error {
+	return errBindToDeviceUnsupported
+}
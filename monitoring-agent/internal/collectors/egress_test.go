@@ -0,0 +1,86 @@
+package collectors
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewEgressPathMerge(t *testing.T) {
+	global := metrics.EgressConfig{
+		ProxyURL:      "http://proxy.example:3128",
+		SourceAddress: "10.0.0.5",
+		IPFamily:      "ipv4",
+	}
+
+	tests := []struct {
+		name   string
+		target metrics.EgressConfig
+		want   metrics.EgressConfig
+	}{
+		{
+			name:   "inherits everything",
+			target: metrics.EgressConfig{},
+			want:   global,
+		},
+		{
+			name:   "interface keeps the global source address",
+			target: metrics.EgressConfig{Interface: "lo"},
+			want:   metrics.EgressConfig{ProxyURL: global.ProxyURL, SourceAddress: "10.0.0.5", Interface: "lo", IPFamily: "ipv4"},
+		},
+		{
+			name:   "source address and family override",
+			target: metrics.EgressConfig{SourceAddress: "fd00::5", IPFamily: "ipv6"},
+			want:   metrics.EgressConfig{ProxyURL: global.ProxyURL, SourceAddress: "fd00::5", IPFamily: "ipv6"},
+		},
+		{
+			name:   "direct bypasses the global proxy",
+			target: metrics.EgressConfig{ProxyURL: egressDirect},
+			want:   metrics.EgressConfig{SourceAddress: "10.0.0.5", IPFamily: "ipv4"},
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.target.Interface != "" && !bindToDeviceSupported {
+			continue
+		}
+		path, err := newEgressPath(global, tt.target)
+		if err != nil {
+			t.Errorf("%s: newEgressPath() error = %v", tt.name, err)
+			continue
+		}
+		if path.EgressConfig != tt.want {
+			t.Errorf("%s: newEgressPath() = %+v, want %+v", tt.name, path.EgressConfig, tt.want)
+		}
+	}
+
+	if _, err := newEgressPath(global, metrics.EgressConfig{SourceAddress: "not-an-ip"}); err == nil {
+		t.Error("newEgressPath() with an invalid source address succeeded")
+	}
+}
+
+func TestPingRejectsSourceAddressWithInterface(t *testing.T) {
+	if !bindToDeviceSupported {
+		t.Skip("interface binding is not supported on this platform")
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	collector := NewPingCollector(time.Minute, []string{"127.0.0.1"},
+		metrics.EgressConfig{SourceAddress: "127.0.0.1"},
+		[]metrics.PingEgress{{Target: "127.0.0.1", Egress: metrics.EgressConfig{Interface: "lo"}}},
+		logger)
+
+	err := collector.Start(context.Background())
+	if err == nil {
+		t.Fatal("Start() with a source address and an interface succeeded, want an error")
+	}
+	if !strings.Contains(err.Error(), "source_address and interface") {
+		t.Errorf("Start() error = %v, want the source_address and interface conflict", err)
+	}
+}
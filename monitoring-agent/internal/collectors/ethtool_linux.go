@@ -0,0 +1,115 @@
+package collectors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// ethtool ioctl constants from linux/sockios.h and linux/ethtool.h
+const (
+	siocEthtool        = 0x8946
+	ethtoolGDrvInfo    = 0x03
+	ethtoolGRingParam  = 0x10
+	ethtoolGStrings    = 0x1b
+	ethtoolGStats      = 0x1d
+	ethSSStats         = 1
+	ethGStringLen      = 32
+	ethtoolDrvInfoSize = 196
+	ethtoolRingSize    = 36
+)
+
+// ethtoolIfreq mirrors struct ifreq with the ifr_data member of the union
+type ethtoolIfreq struct {
+	name [syscall.IFNAMSIZ]byte
+	data uintptr
+	_    [16]byte
+}
+
+// readEthtool queries the driver name, ring sizes and statistics of an interface
+func readEthtool(iface string) (*ethtoolInfo, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ethtool socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	// Driver information, including the number of statistics
+	drvinfo := make([]byte, ethtoolDrvInfoSize)
+	binary.NativeEndian.PutUint32(drvinfo[0:4], ethtoolGDrvInfo)
+	if err := ethtoolIoctl(fd, iface, drvinfo); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GDRVINFO failed: %w", err)
+	}
+
+	info := &ethtoolInfo{
+		driver: cString(drvinfo[4:36]),
+		stats:  make(map[string]uint64),
+	}
+	statCount := int(binary.NativeEndian.Uint32(drvinfo[180:184]))
+
+	// Ring buffer sizes are not supported by every driver
+	ring := make([]byte, ethtoolRingSize)
+	binary.NativeEndian.PutUint32(ring[0:4], ethtoolGRingParam)
+	if err := ethtoolIoctl(fd, iface, ring); err == nil {
+		info.ring = &ethtoolRing{
+			rxMax:     binary.NativeEndian.Uint32(ring[4:8]),
+			txMax:     binary.NativeEndian.Uint32(ring[16:20]),
+			rxPending: binary.NativeEndian.Uint32(ring[20:24]),
+			txPending: binary.NativeEndian.Uint32(ring[32:36]),
+		}
+	}
+
+	if statCount == 0 {
+		return info, nil
+	}
+
+	// Statistic names
+	names := make([]byte, 12+statCount*ethGStringLen)
+	binary.NativeEndian.PutUint32(names[0:4], ethtoolGStrings)
+	binary.NativeEndian.PutUint32(names[4:8], ethSSStats)
+	binary.NativeEndian.PutUint32(names[8:12], uint32(statCount))
+	if err := ethtoolIoctl(fd, iface, names); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GSTRINGS failed: %w", err)
+	}
+
+	// Statistic values, in the same order as the names
+	values := make([]byte, 8+statCount*8)
+	binary.NativeEndian.PutUint32(values[0:4], ethtoolGStats)
+	binary.NativeEndian.PutUint32(values[4:8], uint32(statCount))
+	if err := ethtoolIoctl(fd, iface, values); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GSTATS failed: %w", err)
+	}
+
+	for i := 0; i < statCount; i++ {
+		name := cString(names[12+i*ethGStringLen : 12+(i+1)*ethGStringLen])
+		info.stats[name] = binary.NativeEndian.Uint64(values[8+i*8:])
+	}
+
+	return info, nil
+}
+
+// ethtoolIoctl issues a SIOCETHTOOL request with buf as the command structure
+func ethtoolIoctl(fd int, iface string, buf []byte) error {
+	var ifr ethtoolIfreq
+	copy(ifr.name[:syscall.IFNAMSIZ-1], iface)
+	ifr.data = uintptr(unsafe.Pointer(&buf[0]))
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&ifr)))
+	runtime.KeepAlive(buf)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// cString converts a NUL-terminated byte array to a string
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
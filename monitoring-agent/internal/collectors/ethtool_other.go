@@ -0,0 +1,8 @@
+//go:build !linux
+
+package collectors
+
+// readEthtool is only implemented on Linux
+func readEthtool(iface string) (*ethtoolInfo, error) {
+	return nil, errEthtoolUnsupported
+}
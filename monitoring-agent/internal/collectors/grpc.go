@@ -0,0 +1,364 @@
+package collectors
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCCollector probes gRPC servers with the standard health checking
+// protocol (grpc.health.v1.Health) and optional unary calls resolved via
+// server reflection, recording connection time, RPC latency and status codes
+type GRPCCollector struct {
+	interval time.Duration
+	targets  []metrics.GRPCTarget
+	config   metrics.GRPCConfig
+	creds    []credentials.TransportCredentials
+	methods  map[string]protoreflect.MethodDescriptor
+	logger   *logrus.Logger
+}
+
+// NewGRPCCollector creates a new gRPC health-check probe collector
+func NewGRPCCollector(interval time.Duration, targets []metrics.GRPCTarget, config metrics.GRPCConfig, logger *logrus.Logger) *GRPCCollector {
+	return &GRPCCollector{
+		interval: interval,
+		targets:  targets,
+		config:   config,
+		methods:  make(map[string]protoreflect.MethodDescriptor),
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (gc *GRPCCollector) Name() string {
+	return "grpc"
+}
+
+// Interval returns the collection interval
+func (gc *GRPCCollector) Interval() time.Duration {
+	return gc.interval
+}
+
+// Start initializes the collector
+func (gc *GRPCCollector) Start(ctx context.Context) error {
+	gc.logger.WithField("targets", len(gc.targets)).Info("Starting gRPC collector")
+
+	if len(gc.targets) == 0 {
+		return fmt.Errorf("no gRPC targets configured")
+	}
+
+	// Load certificates up front so bad paths fail at startup
+	gc.creds = nil
+	for _, target := range gc.targets {
+		creds, err := grpcCredentials(target)
+		if err != nil {
+			return fmt.Errorf("invalid gRPC target %s: %w", target.Address, err)
+		}
+		gc.creds = append(gc.creds, creds)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (gc *GRPCCollector) Stop() error {
+	gc.logger.Info("Stopping gRPC collector")
+	return nil
+}
+
+// Collect probes every target over a fresh connection
+func (gc *GRPCCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for i, target := range gc.targets {
+		tags := map[string]string{
+			"target": target.Address,
+		}
+
+		probeMetrics, err := gc.probeTarget(ctx, target, gc.creds[i], tags, currentTime)
+		if err != nil {
+			gc.logger.WithFields(logrus.Fields{
+				"target": target.Address,
+				"error":  err,
+			}).Warn("gRPC probe failed")
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "grpc_success",
+			Value:     boolToFloat(err == nil),
+			Unit:      "boolean",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+		collectedMetrics = append(collectedMetrics, probeMetrics...)
+	}
+
+	gc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected gRPC metrics")
+	return collectedMetrics, nil
+}
+
+// probeTarget connects to a target, checks its health and calls its configured
+// methods. The error reports why the target is not healthy; metrics gathered
+// up to that point are still returned.
+func (gc *GRPCCollector) probeTarget(ctx context.Context, target metrics.GRPCTarget, creds credentials.TransportCredentials, tags map[string]string, timestamp time.Time) ([]metrics.Metric, error) {
+	conn, err := grpc.NewClient(target.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	defer conn.Close()
+
+	connectTime, err := gc.connect(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "grpc_connect_time_ms",
+			Value:     durationToMs(connectTime),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	healthMetrics, healthErr := gc.checkHealth(ctx, conn, target, tags, timestamp)
+	collectedMetrics = append(collectedMetrics, healthMetrics...)
+
+	for _, method := range target.Methods {
+		collectedMetrics = append(collectedMetrics, gc.callMethod(ctx, conn, target, method, tags, timestamp)...)
+	}
+
+	return collectedMetrics, healthErr
+}
+
+// connect waits until the connection is ready, failing on the first transient failure
+func (gc *GRPCCollector) connect(ctx context.Context, conn *grpc.ClientConn) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, gc.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return time.Since(start), nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return 0, fmt.Errorf("connection failed: %s", state)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return 0, fmt.Errorf("connection timed out in state %s", state)
+		}
+	}
+}
+
+// checkHealth calls Health/Check, or reads the first update of Health/Watch
+func (gc *GRPCCollector) checkHealth(ctx context.Context, conn *grpc.ClientConn, target metrics.GRPCTarget, tags map[string]string, timestamp time.Time) ([]metrics.Metric, error) {
+	ctx, cancel := context.WithTimeout(ctx, gc.config.Timeout)
+	defer cancel()
+
+	client := healthpb.NewHealthClient(conn)
+	request := &healthpb.HealthCheckRequest{Service: target.Service}
+
+	rpc := "check"
+	start := time.Now()
+	var response *healthpb.HealthCheckResponse
+	var err error
+	if target.Watch {
+		rpc = "watch"
+		var stream healthpb.Health_WatchClient
+		if stream, err = client.Watch(ctx, request); err == nil {
+			response, err = stream.Recv()
+		}
+	} else {
+		response, err = client.Check(ctx, request)
+	}
+	latency := time.Since(start)
+
+	healthTags := mergeTags(tags, map[string]string{
+		"service": target.Service,
+		"rpc":     rpc,
+		"code":    status.Code(err).String(),
+	})
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "grpc_health_latency_ms",
+			Value:     durationToMs(latency),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      healthTags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+	if err != nil {
+		return collectedMetrics, fmt.Errorf("health %s failed: %w", rpc, err)
+	}
+
+	serving := response.GetStatus() == healthpb.HealthCheckResponse_SERVING
+	collectedMetrics = append(collectedMetrics,
+		metrics.Metric{
+			Name:      "grpc_health_serving",
+			Value:     boolToFloat(serving),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      healthTags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "grpc_health_status",
+			Value:     1,
+			Unit:      "info",
+			Timestamp: timestamp,
+			Tags: mergeTags(healthTags, map[string]string{
+				"status": response.GetStatus().String(),
+			}),
+			Type: metrics.MetricTypeGauge,
+		},
+	)
+	if !serving {
+		return collectedMetrics, fmt.Errorf("service is %s", response.GetStatus())
+	}
+
+	return collectedMetrics, nil
+}
+
+// callMethod invokes a unary method with its JSON request and reports latency and status code
+func (gc *GRPCCollector) callMethod(ctx context.Context, conn *grpc.ClientConn, target metrics.GRPCTarget, method metrics.GRPCMethod, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	ctx, cancel := context.WithTimeout(ctx, gc.config.Timeout)
+	defer cancel()
+
+	methodTags := mergeTags(tags, map[string]string{
+		"method": method.Method,
+	})
+
+	// Descriptors are cached until a call fails, so schema changes are picked up
+	cacheKey := target.Address + "|" + method.Method
+	descriptor, exists := gc.methods[cacheKey]
+	if !exists {
+		var err error
+		descriptor, err = resolveGRPCMethod(ctx, conn, method.Method)
+		if err != nil {
+			gc.logger.WithFields(logrus.Fields{
+				"target": target.Address,
+				"method": method.Method,
+				"error":  err,
+			}).Warn("Failed to resolve gRPC method")
+			return gc.methodMetrics(codes.Unknown, 0, methodTags, timestamp)
+		}
+		gc.methods[cacheKey] = descriptor
+	}
+
+	request := dynamicpb.NewMessage(descriptor.Input())
+	if err := protojson.Unmarshal([]byte(method.Request), request); err != nil {
+		gc.logger.WithFields(logrus.Fields{
+			"target": target.Address,
+			"method": method.Method,
+			"error":  err,
+		}).Warn("Invalid gRPC request message")
+		return gc.methodMetrics(codes.InvalidArgument, 0, methodTags, timestamp)
+	}
+	response := dynamicpb.NewMessage(descriptor.Output())
+
+	start := time.Now()
+	err := conn.Invoke(ctx, "/"+method.Method, request, response)
+	latency := time.Since(start)
+
+	code := status.Code(err)
+	if err != nil {
+		delete(gc.methods, cacheKey)
+		gc.logger.WithFields(logrus.Fields{
+			"target": target.Address,
+			"method": method.Method,
+			"error":  err,
+		}).Debug("gRPC method call failed")
+	}
+
+	return gc.methodMetrics(code, latency, methodTags, timestamp)
+}
+
+// methodMetrics reports the outcome of a method call; latency is zero when no call was made
+func (gc *GRPCCollector) methodMetrics(code codes.Code, latency time.Duration, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	tags = mergeTags(tags, map[string]string{
+		"code": code.String(),
+	})
+
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "grpc_method_success",
+			Value:     boolToFloat(code == codes.OK),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+	if latency > 0 {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "grpc_method_latency_ms",
+			Value:     durationToMs(latency),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+	}
+	return collectedMetrics
+}
+
+// grpcCredentials builds plaintext, TLS or mutual TLS transport credentials for a target
+func grpcCredentials(target metrics.GRPCTarget) (credentials.TransportCredentials, error) {
+	if !target.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	serverName := target.ServerName
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(target.Address)
+		if err != nil {
+			return nil, fmt.Errorf("address must be host:port: %w", err)
+		}
+		serverName = host
+	}
+
+	config := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: target.InsecureSkipVerify,
+	}
+
+	if target.CAFile != "" {
+		pool, err := loadCAPool(target.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if target.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(target.CertFile, target.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return credentials.NewTLS(config), nil
+}
@@ -0,0 +1,145 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// resolveGRPCMethod looks up a method descriptor through server reflection.
+// method is "package.Service/Method".
+func resolveGRPCMethod(ctx context.Context, conn *grpc.ClientConn, method string) (protoreflect.MethodDescriptor, error) {
+	service, name, _ := strings.Cut(method, "/")
+
+	rawFiles, err := reflectFileContainingSymbol(ctx, conn, service)
+	if err != nil {
+		return nil, fmt.Errorf("reflection lookup of %s failed: %w", service, err)
+	}
+
+	files, err := buildFileRegistry(rawFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", service, err)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(name))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("method %s not found", method)
+	}
+	if methodDescriptor.IsStreamingClient() || methodDescriptor.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is not unary", method)
+	}
+	return methodDescriptor, nil
+}
+
+// reflectFileContainingSymbol asks the server for the file defining symbol and
+// its dependencies, falling back to the v1alpha protocol for older servers
+func reflectFileContainingSymbol(ctx context.Context, conn *grpc.ClientConn, symbol string) ([][]byte, error) {
+	files, err := reflectV1(ctx, conn, symbol)
+	if status.Code(err) == codes.Unimplemented {
+		return reflectV1Alpha(ctx, conn, symbol)
+	}
+	return files, err
+}
+
+// reflectV1 performs a file_containing_symbol request with grpc.reflection.v1
+func reflectV1(ctx context.Context, conn *grpc.ClientConn, symbol string) ([][]byte, error) {
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	if err := stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}); err != nil {
+		return nil, err
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+		return nil, fmt.Errorf("%s", errorResponse.GetErrorMessage())
+	}
+	return response.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
+
+// reflectV1Alpha performs a file_containing_symbol request with grpc.reflection.v1alpha
+func reflectV1Alpha(ctx context.Context, conn *grpc.ClientConn, symbol string) ([][]byte, error) {
+	stream, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	if err := stream.Send(&reflectionv1alpha.ServerReflectionRequest{
+		MessageRequest: &reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}); err != nil {
+		return nil, err
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+		return nil, fmt.Errorf("%s", errorResponse.GetErrorMessage())
+	}
+	return response.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+}
+
+// buildFileRegistry links serialized file descriptors, taking dependencies the
+// server did not send (such as the well-known types) from the compiled-in registry
+func buildFileRegistry(rawFiles [][]byte) (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	present := make(map[string]bool)
+	for _, raw := range rawFiles {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(raw, file); err != nil {
+			return nil, fmt.Errorf("invalid file descriptor: %w", err)
+		}
+		if !present[file.GetName()] {
+			set.File = append(set.File, file)
+			present[file.GetName()] = true
+		}
+	}
+
+	for i := 0; i < len(set.File); i++ {
+		for _, dependency := range set.File[i].GetDependency() {
+			if present[dependency] {
+				continue
+			}
+			known, err := protoregistry.GlobalFiles.FindFileByPath(dependency)
+			if err != nil {
+				return nil, fmt.Errorf("missing dependency %s", dependency)
+			}
+			set.File = append(set.File, protodesc.ToFileDescriptorProto(known))
+			present[dependency] = true
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link file descriptors: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,232 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPCollector monitors HTTP endpoints, separating endpoints that are down
+// (no response) from endpoints that respond with the wrong content
+type HTTPCollector struct {
+	interval   time.Duration
+	targets    []metrics.HTTPTarget
+	egress     metrics.EgressConfig
+	assertions []*httpAssertions
+	paths      []*egressPath
+	clients    []*http.Client
+	logger     *logrus.Logger
+}
+
+// NewHTTPCollector creates a new HTTP endpoint collector
+func NewHTTPCollector(interval time.Duration, targets []metrics.HTTPTarget, egress metrics.EgressConfig, logger *logrus.Logger) *HTTPCollector {
+	return &HTTPCollector{
+		interval: interval,
+		targets:  targets,
+		egress:   egress,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (hc *HTTPCollector) Name() string {
+	return "http"
+}
+
+// Interval returns the collection interval
+func (hc *HTTPCollector) Interval() time.Duration {
+	return hc.interval
+}
+
+// Start initializes the collector
+func (hc *HTTPCollector) Start(ctx context.Context) error {
+	hc.logger.WithField("targets", len(hc.targets)).Info("Starting HTTP collector")
+
+	if len(hc.targets) == 0 {
+		return fmt.Errorf("no HTTP targets configured")
+	}
+
+	hc.assertions = nil
+	hc.paths = nil
+	hc.clients = nil
+	for _, target := range hc.targets {
+		assert := target.Assert
+		if assert.Status == 0 {
+			assert.Status = target.ExpectedCode
+		}
+
+		assertions, err := compileHTTPAssertions(assert)
+		if err != nil {
+			return fmt.Errorf("invalid HTTP target %s: %w", target.URL, err)
+		}
+		hc.assertions = append(hc.assertions, assertions)
+
+		path, err := newEgressPath(hc.egress, target.Egress)
+		if err != nil {
+			return fmt.Errorf("invalid HTTP target %s: %w", target.URL, err)
+		}
+		hc.paths = append(hc.paths, path)
+
+		client := &http.Client{Transport: path.httpTransport()}
+		if !target.FollowRedirect {
+			client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+		}
+		hc.clients = append(hc.clients, client)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (hc *HTTPCollector) Stop() error {
+	hc.logger.Info("Stopping HTTP collector")
+	return nil
+}
+
+// Collect requests every target once and checks its assertions
+func (hc *HTTPCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for i, target := range hc.targets {
+		tags := hc.paths[i].tagged(map[string]string{
+			"target": target.URL,
+			"method": target.Method,
+		})
+		collectedMetrics = append(collectedMetrics, hc.checkTarget(ctx, hc.clients[i], target, hc.assertions[i], tags, currentTime)...)
+	}
+
+	hc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected HTTP metrics")
+	return collectedMetrics, nil
+}
+
+// checkTarget sends one request to a target and reports its availability and
+// one failure metric per failing assertion
+func (hc *HTTPCollector) checkTarget(ctx context.Context, client *http.Client, target metrics.HTTPTarget, assertions *httpAssertions, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	response, err := hc.request(ctx, client, target)
+	if err != nil {
+		hc.logger.WithFields(logrus.Fields{
+			"target": target.URL,
+			"error":  err,
+		}).Warn("HTTP request failed")
+
+		return []metrics.Metric{
+			{
+				Name:      "http_up",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "http_success",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}
+	}
+
+	failures := assertions.check(response)
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "http_up",
+			Value:     1,
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "http_success",
+			Value:     boolToFloat(len(failures) == 0),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "http_response_time_ms",
+			Value:     durationToMs(response.latency),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "http_status_code",
+			Value:     float64(response.statusCode),
+			Unit:      "code",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "http_response_size_bytes",
+			Value:     float64(response.size),
+			Unit:      "bytes",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "http_response_truncated",
+			Value:     boolToFloat(response.truncated),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	failed := make(map[string]bool)
+	for _, failure := range failures {
+		hc.logger.WithFields(logrus.Fields{
+			"target":    target.URL,
+			"assertion": failure.reason,
+			"error":     failure.message,
+		}).Warn("HTTP assertion failed")
+		failed[failure.reason] = true
+	}
+
+	// Passing assertions report 0, so a recovered assertion clears its alert
+	for _, reason := range assertions.reasons() {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "http_assertion_failure",
+			Value:     boolToFloat(failed[reason]),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"assertion": reason,
+			}),
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	return collectedMetrics
+}
+
+// request sends the configured request for a target
+func (hc *HTTPCollector) request(ctx context.Context, client *http.Client, target metrics.HTTPTarget) (*httpResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, target.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, target.Method, target.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range target.Headers {
+		request.Header.Set(key, value)
+	}
+
+	return doHTTPRequest(client, request)
+}
@@ -0,0 +1,290 @@
+package collectors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// httpBodyLimit is the most of a response body read; the rest of a larger body
+// is left unread and the response is marked truncated
+const httpBodyLimit = 10 << 20
+
+// httpResponse is a response read in full for assertions and extraction
+type httpResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	truncated  bool   // The body is larger than httpBodyLimit
+	size       int64  // Bytes read, at most httpBodyLimit
+	bodyHash   []byte // Nil when truncated
+	tls        *tls.ConnectionState
+	latency    time.Duration
+	json       interface{}
+	jsonErr    error
+	jsonParsed bool
+}
+
+// assertionFailure is a failed check; reason is a stable value used as a metric tag
+type assertionFailure struct {
+	reason  string
+	message string
+}
+
+// httpAssertions holds assertions with their patterns compiled
+type httpAssertions struct {
+	metrics.HTTPAssertions
+	bodyRegex *regexp.Regexp
+}
+
+// doHTTPRequest sends a request and reads up to httpBodyLimit of the body,
+// timing the exchange until the body has been read
+func doHTTPRequest(client *http.Client, request *http.Request) (*httpResponse, error) {
+	start := time.Now()
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// One byte over the limit tells a truncated body from one of exactly the limit
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpBodyLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	latency := time.Since(start)
+
+	truncated := len(body) > httpBodyLimit
+	var bodyHash []byte
+	if truncated {
+		body = body[:httpBodyLimit]
+	} else {
+		sum := sha256.Sum256(body)
+		bodyHash = sum[:]
+	}
+
+	return &httpResponse{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       body,
+		truncated:  truncated,
+		size:       int64(len(body)),
+		bodyHash:   bodyHash,
+		tls:        resp.TLS,
+		latency:    latency,
+	}, nil
+}
+
+// jsonBody decodes the body as JSON once, keeping numbers in their original form
+func (r *httpResponse) jsonBody() (interface{}, error) {
+	if !r.jsonParsed {
+		decoder := json.NewDecoder(bytes.NewReader(r.body))
+		decoder.UseNumber()
+		r.jsonErr = decoder.Decode(&r.json)
+		r.jsonParsed = true
+	}
+	return r.json, r.jsonErr
+}
+
+// compileHTTPAssertions prepares assertions for repeated use
+func compileHTTPAssertions(assertions metrics.HTTPAssertions) (*httpAssertions, error) {
+	compiled := &httpAssertions{HTTPAssertions: assertions}
+	compiled.BodySHA256 = normalizeFingerprint(assertions.BodySHA256)
+	compiled.CertSHA256 = normalizeFingerprint(assertions.CertSHA256)
+	if assertions.BodyRegex != "" {
+		pattern, err := regexp.Compile(assertions.BodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body_regex: %w", err)
+		}
+		compiled.bodyRegex = pattern
+	}
+	return compiled, nil
+}
+
+// reasons returns the failure reasons of the configured assertions, in the
+// order check evaluates them. The status assertion is always configured.
+func (a *httpAssertions) reasons() []string {
+	reasons := []string{"status"}
+	for _, assertion := range []struct {
+		reason     string
+		configured bool
+	}{
+		{"body_contains", a.BodyContains != ""},
+		{"body_regex", a.bodyRegex != nil},
+		{"json_path", len(a.JSON) > 0},
+		{"latency", a.MaxLatency > 0},
+		{"header_present", len(a.HeadersPresent) > 0},
+		{"body_size", a.MaxBodyBytes > 0},
+		{"body_hash", a.BodySHA256 != ""},
+		{"cert_fingerprint", a.CertSHA256 != ""},
+	} {
+		if assertion.configured {
+			reasons = append(reasons, assertion.reason)
+		}
+	}
+	return reasons
+}
+
+// check evaluates every assertion against a response and returns the failures
+func (a *httpAssertions) check(response *httpResponse) []assertionFailure {
+	var failures []assertionFailure
+
+	switch {
+	case a.Status != 0 && response.statusCode != a.Status:
+		failures = append(failures, assertionFailure{"status", fmt.Sprintf("status %d, expected %d", response.statusCode, a.Status)})
+	case a.Status == 0 && response.statusCode >= 400:
+		failures = append(failures, assertionFailure{"status", fmt.Sprintf("status %d", response.statusCode)})
+	}
+
+	if a.BodyContains != "" && !bytes.Contains(response.body, []byte(a.BodyContains)) {
+		failures = append(failures, assertionFailure{"body_contains", fmt.Sprintf("body does not contain %q", a.BodyContains)})
+	}
+
+	if a.bodyRegex != nil && !a.bodyRegex.Match(response.body) {
+		failures = append(failures, assertionFailure{"body_regex", fmt.Sprintf("body does not match %q", a.BodyRegex)})
+	}
+
+	for _, assertion := range a.JSON {
+		value, err := lookupJSONPath(response, assertion.Path)
+		if err != nil {
+			failures = append(failures, assertionFailure{"json_path", err.Error()})
+			continue
+		}
+		if value != assertion.Equals {
+			failures = append(failures, assertionFailure{"json_path", fmt.Sprintf("%s is %q, expected %q", assertion.Path, value, assertion.Equals)})
+		}
+	}
+
+	if a.MaxLatency > 0 && response.latency > a.MaxLatency {
+		failures = append(failures, assertionFailure{"latency", fmt.Sprintf("took %v, limit %v", response.latency, a.MaxLatency)})
+	}
+
+	for _, header := range a.HeadersPresent {
+		if _, exists := response.header[http.CanonicalHeaderKey(header)]; !exists {
+			failures = append(failures, assertionFailure{"header_present", fmt.Sprintf("header %s not present", header)})
+		}
+	}
+
+	switch {
+	case a.MaxBodyBytes > 0 && response.size > a.MaxBodyBytes:
+		failures = append(failures, assertionFailure{"body_size", fmt.Sprintf("body is %d bytes, limit %d", response.size, a.MaxBodyBytes)})
+	case a.MaxBodyBytes > 0 && response.truncated:
+		failures = append(failures, assertionFailure{"body_size", fmt.Sprintf("body is over %d bytes, limit %d", httpBodyLimit, a.MaxBodyBytes)})
+	}
+
+	if a.BodySHA256 != "" {
+		switch {
+		case response.truncated:
+			failures = append(failures, assertionFailure{"body_hash", fmt.Sprintf("body is over %d bytes and was not hashed", httpBodyLimit)})
+		default:
+			if hash := hex.EncodeToString(response.bodyHash); hash != a.BodySHA256 {
+				failures = append(failures, assertionFailure{"body_hash", fmt.Sprintf("body SHA-256 is %s", hash)})
+			}
+		}
+	}
+
+	if a.CertSHA256 != "" {
+		switch {
+		case response.tls == nil || len(response.tls.PeerCertificates) == 0:
+			failures = append(failures, assertionFailure{"cert_fingerprint", "no server certificate"})
+		default:
+			fingerprint := sha256.Sum256(response.tls.PeerCertificates[0].Raw)
+			if hash := hex.EncodeToString(fingerprint[:]); hash != a.CertSHA256 {
+				failures = append(failures, assertionFailure{"cert_fingerprint", fmt.Sprintf("certificate SHA-256 is %s", hash)})
+			}
+		}
+	}
+
+	return failures
+}
+
+// normalizeFingerprint lowercases a hex digest and drops colon separators
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// extractVariable reads a value out of a response for use in later requests
+func extractVariable(response *httpResponse, extract metrics.HTTPExtract, pattern *regexp.Regexp) (string, error) {
+	switch extract.From {
+	case "json":
+		return lookupJSONPath(response, extract.Path)
+
+	case "header":
+		value := response.header.Get(extract.Path)
+		if value == "" {
+			return "", fmt.Errorf("header %s not present", extract.Path)
+		}
+		return value, nil
+
+	case "regex":
+		matches := pattern.FindSubmatch(response.body)
+		if matches == nil {
+			return "", fmt.Errorf("body does not match %q", extract.Path)
+		}
+		if len(matches) > 1 {
+			return string(matches[1]), nil
+		}
+		return string(matches[0]), nil
+	}
+
+	return "", fmt.Errorf("unsupported extract source %q", extract.From)
+}
+
+// lookupJSONPath returns the value at a path such as $.data.items[0].id,
+// rendered as text: strings unquoted, numbers as sent, objects and arrays as JSON
+func lookupJSONPath(response *httpResponse, path string) (string, error) {
+	value, err := response.jsonBody()
+	if err != nil {
+		return "", fmt.Errorf("body is not valid JSON: %w", err)
+	}
+
+	normalized := strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimPrefix(path, "$"))
+	for _, segment := range strings.Split(normalized, ".") {
+		if segment == "" {
+			continue
+		}
+
+		switch node := value.(type) {
+		case map[string]interface{}:
+			child, exists := node[segment]
+			if !exists {
+				return "", fmt.Errorf("%s not found", path)
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", fmt.Errorf("%s not found", path)
+			}
+			value = node[index]
+		default:
+			return "", fmt.Errorf("%s not found", path)
+		}
+	}
+
+	switch node := value.(type) {
+	case string:
+		return node, nil
+	case json.Number:
+		return node.String(), nil
+	case nil:
+		return "null", nil
+	default:
+		encoded, err := json.Marshal(node)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,99 @@
+package collectors
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+func TestDoHTTPRequestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		wantSize      int64
+		wantTruncated bool
+	}{
+		{"small body", 1024, 1024, false},
+		{"body at the limit", httpBodyLimit, httpBodyLimit, false},
+		{"body over the limit", httpBodyLimit + 1, httpBodyLimit, true},
+		{"body far over the limit", 3 * httpBodyLimit, httpBodyLimit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(bytes.Repeat([]byte("a"), tt.size))
+			}))
+			defer server.Close()
+
+			request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := doHTTPRequest(server.Client(), request)
+			if err != nil {
+				t.Fatalf("doHTTPRequest() error = %v", err)
+			}
+
+			if response.size != tt.wantSize || int64(len(response.body)) != tt.wantSize || response.truncated != tt.wantTruncated {
+				t.Errorf("got size=%d body=%d truncated=%v, want size=%d truncated=%v",
+					response.size, len(response.body), response.truncated, tt.wantSize, tt.wantTruncated)
+			}
+			if (response.bodyHash == nil) != tt.wantTruncated {
+				t.Errorf("bodyHash = %x, want a hash only for complete bodies", response.bodyHash)
+			}
+		})
+	}
+}
+
+func TestHTTPAssertionsTruncatedBody(t *testing.T) {
+	assertions, err := compileHTTPAssertions(metrics.HTTPAssertions{
+		MaxBodyBytes: httpBodyLimit,
+		BodySHA256:   strings.Repeat("0", 64),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := &httpResponse{statusCode: 200, size: httpBodyLimit, truncated: true}
+	reasons := make(map[string]bool)
+	for _, failure := range assertions.check(response) {
+		reasons[failure.reason] = true
+	}
+	for _, reason := range []string{"body_size", "body_hash"} {
+		if !reasons[reason] {
+			t.Errorf("check() of a truncated body did not fail %s", reason)
+		}
+	}
+}
+
+func TestHTTPAssertionsReasons(t *testing.T) {
+	assertions, err := compileHTTPAssertions(metrics.HTTPAssertions{
+		BodyContains:   "ok",
+		HeadersPresent: []string{"X-Request-Id"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(assertions.reasons(), ",")
+	if want := "status,body_contains,header_present"; got != want {
+		t.Errorf("reasons() = %s, want %s", got, want)
+	}
+
+	// Every failure check reports must be one of the configured reasons
+	response := &httpResponse{statusCode: 500, header: http.Header{}}
+	configured := make(map[string]bool)
+	for _, reason := range assertions.reasons() {
+		configured[reason] = true
+	}
+	for _, failure := range assertions.check(response) {
+		if !configured[failure.reason] {
+			t.Errorf("check() failed %s, which reasons() does not list", failure.reason)
+		}
+	}
+}
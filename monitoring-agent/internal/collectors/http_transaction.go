@@ -0,0 +1,316 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// httpVariablePattern matches ${name} references to extracted variables
+var httpVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// HTTPTransactionCollector runs scripted multi-step HTTP checks, such as a
+// login flow, carrying cookies and extracted variables from step to step
+type HTTPTransactionCollector struct {
+	interval     time.Duration
+	transactions []metrics.HTTPTransaction
+	steps        [][]*httpStepScript // Compiled steps, by transaction position
+	logger       *logrus.Logger
+}
+
+// httpStepScript is a transaction step with its patterns compiled
+type httpStepScript struct {
+	metrics.HTTPStep
+	assertions      *httpAssertions
+	extractPatterns []*regexp.Regexp
+	usesVariables   bool
+}
+
+// NewHTTPTransactionCollector creates a new scripted HTTP transaction collector
+func NewHTTPTransactionCollector(interval time.Duration, transactions []metrics.HTTPTransaction, logger *logrus.Logger) *HTTPTransactionCollector {
+	return &HTTPTransactionCollector{
+		interval:     interval,
+		transactions: transactions,
+		logger:       logger,
+	}
+}
+
+// Name returns the collector name
+func (hc *HTTPTransactionCollector) Name() string {
+	return "http_transaction"
+}
+
+// Interval returns the collection interval
+func (hc *HTTPTransactionCollector) Interval() time.Duration {
+	return hc.interval
+}
+
+// Start initializes the collector
+func (hc *HTTPTransactionCollector) Start(ctx context.Context) error {
+	hc.logger.WithField("transactions", len(hc.transactions)).Info("Starting HTTP transaction collector")
+
+	if len(hc.transactions) == 0 {
+		return fmt.Errorf("no HTTP transactions configured")
+	}
+
+	hc.steps = make([][]*httpStepScript, len(hc.transactions))
+	for i, transaction := range hc.transactions {
+		var scripts []*httpStepScript
+		for _, step := range transaction.Steps {
+			script, err := newHTTPStepScript(step)
+			if err != nil {
+				return fmt.Errorf("transaction %s step %s: %w", transaction.Name, step.Name, err)
+			}
+			scripts = append(scripts, script)
+		}
+		hc.steps[i] = scripts
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (hc *HTTPTransactionCollector) Stop() error {
+	hc.logger.Info("Stopping HTTP transaction collector")
+	return nil
+}
+
+// Collect runs every transaction once
+func (hc *HTTPTransactionCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for i, transaction := range hc.transactions {
+		collectedMetrics = append(collectedMetrics, hc.runTransaction(ctx, transaction.Name, hc.steps[i], currentTime)...)
+	}
+
+	hc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected HTTP transaction metrics")
+	return collectedMetrics, nil
+}
+
+// runTransaction executes the steps of a transaction in order, stopping at the first failing step
+func (hc *HTTPTransactionCollector) runTransaction(ctx context.Context, name string, steps []*httpStepScript, timestamp time.Time) []metrics.Metric {
+	var collectedMetrics []metrics.Metric
+	tags := map[string]string{
+		"transaction": name,
+	}
+
+	// Each run starts logged out with no variables
+	jar, _ := cookiejar.New(nil)
+	variables := make(map[string]string)
+
+	completed := 0
+	start := time.Now()
+
+	for i, step := range steps {
+		stepTags := mergeTags(tags, map[string]string{
+			"step":       step.Name,
+			"step_index": strconv.Itoa(i + 1),
+		})
+
+		response, failures := hc.runStep(ctx, jar, step, variables)
+		if response != nil {
+			collectedMetrics = append(collectedMetrics,
+				metrics.Metric{
+					Name:      "http_transaction_step_duration_ms",
+					Value:     durationToMs(response.latency),
+					Unit:      "ms",
+					Timestamp: timestamp,
+					Tags:      stepTags,
+					Type:      metrics.MetricTypeGauge,
+				},
+				metrics.Metric{
+					Name:      "http_transaction_step_status_code",
+					Value:     float64(response.statusCode),
+					Unit:      "code",
+					Timestamp: timestamp,
+					Tags:      stepTags,
+					Type:      metrics.MetricTypeGauge,
+				},
+			)
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "http_transaction_step_success",
+			Value:     boolToFloat(len(failures) == 0),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      stepTags,
+			Type:      metrics.MetricTypeGauge,
+		})
+
+		failed := make(map[string]bool)
+		for _, failure := range failures {
+			hc.logger.WithFields(logrus.Fields{
+				"transaction": name,
+				"step":        step.Name,
+				"reason":      failure.reason,
+				"error":       failure.message,
+			}).Warn("HTTP transaction step failed")
+			failed[failure.reason] = true
+		}
+
+		// Reasons that passed report 0, so a recovered step clears its alert
+		for _, reason := range step.reasons() {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "http_transaction_failure",
+				Value:     boolToFloat(failed[reason]),
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags: mergeTags(stepTags, map[string]string{
+					"reason": reason,
+				}),
+				Type: metrics.MetricTypeGauge,
+			})
+		}
+
+		if len(failures) > 0 {
+			break
+		}
+		completed++
+	}
+
+	return append(collectedMetrics,
+		metrics.Metric{
+			Name:      "http_transaction_success",
+			Value:     boolToFloat(completed == len(steps)),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "http_transaction_duration_ms",
+			Value:     durationToMs(time.Since(start)),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "http_transaction_steps_completed",
+			Value:     float64(completed),
+			Unit:      "steps",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	)
+}
+
+// runStep sends one request, checks its assertions and stores extracted variables.
+// The response is nil when no response was received.
+func (hc *HTTPTransactionCollector) runStep(ctx context.Context, jar http.CookieJar, step *httpStepScript, variables map[string]string) (*httpResponse, []assertionFailure) {
+	var missing []string
+	expand := func(text string) string {
+		return httpVariablePattern.ReplaceAllStringFunc(text, func(reference string) string {
+			name := httpVariablePattern.FindStringSubmatch(reference)[1]
+			value, exists := variables[name]
+			if !exists {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+
+	url := expand(step.URL)
+	body := expand(step.Body)
+	headers := make(map[string]string, len(step.Headers))
+	for key, value := range step.Headers {
+		headers[key] = expand(value)
+	}
+	if len(missing) > 0 {
+		return nil, []assertionFailure{{"variable", "undefined variables: " + strings.Join(missing, ", ")}}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, step.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, step.Method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, []assertionFailure{{"request", err.Error()}}
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	client := &http.Client{Jar: jar}
+	if !step.FollowRedirect {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	response, err := doHTTPRequest(client, request)
+	if err != nil {
+		return nil, []assertionFailure{{"request", err.Error()}}
+	}
+
+	if failures := step.assertions.check(response); len(failures) > 0 {
+		return response, failures
+	}
+
+	for i, extract := range step.Extract {
+		value, err := extractVariable(response, extract, step.extractPatterns[i])
+		if err != nil {
+			return response, []assertionFailure{{"extract", fmt.Sprintf("%s: %v", extract.Variable, err)}}
+		}
+		variables[extract.Variable] = value
+	}
+
+	return response, nil
+}
+
+// reasons lists the failure reasons a step reports: the request, its
+// assertions and, where the step uses them, variables and extraction
+func (s *httpStepScript) reasons() []string {
+	reasons := []string{"request"}
+	if s.usesVariables {
+		reasons = append(reasons, "variable")
+	}
+	reasons = append(reasons, s.assertions.reasons()...)
+	if len(s.Extract) > 0 {
+		reasons = append(reasons, "extract")
+	}
+	return reasons
+}
+
+// newHTTPStepScript compiles the assertions and extraction patterns of a step
+func newHTTPStepScript(step metrics.HTTPStep) (*httpStepScript, error) {
+	assertions, err := compileHTTPAssertions(step.Assert)
+	if err != nil {
+		return nil, err
+	}
+
+	script := &httpStepScript{
+		HTTPStep:        step,
+		assertions:      assertions,
+		extractPatterns: make([]*regexp.Regexp, len(step.Extract)),
+		usesVariables:   httpVariablePattern.MatchString(step.URL) || httpVariablePattern.MatchString(step.Body),
+	}
+	for _, value := range step.Headers {
+		if httpVariablePattern.MatchString(value) {
+			script.usesVariables = true
+		}
+	}
+	for i, extract := range step.Extract {
+		if extract.From != "regex" {
+			continue
+		}
+		pattern, err := regexp.Compile(extract.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extract regex: %w", err)
+		}
+		script.extractPatterns[i] = pattern
+	}
+
+	return script, nil
+}
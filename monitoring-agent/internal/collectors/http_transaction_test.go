@@ -0,0 +1,148 @@
+package collectors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHTTPTransactionFailureClears(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Write([]byte(`{"token":"abc"}`))
+		case "/data":
+			if !healthy.Load() || r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	transactions := []metrics.HTTPTransaction{{
+		Name: "login",
+		Steps: []metrics.HTTPStep{
+			{
+				Name:    "login",
+				Method:  http.MethodGet,
+				URL:     server.URL + "/login",
+				Timeout: time.Second,
+				Extract: []metrics.HTTPExtract{{Variable: "token", From: "json", Path: "token"}},
+			},
+			{
+				Name:    "data",
+				Method:  http.MethodGet,
+				URL:     server.URL + "/data",
+				Headers: map[string]string{"Authorization": "Bearer ${token}"},
+				Timeout: time.Second,
+				Assert:  metrics.HTTPAssertions{BodyContains: "ok"},
+			},
+		},
+	}}
+	collector := NewHTTPTransactionCollector(time.Minute, transactions, logger)
+	if err := collector.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	failures := func() map[string]float64 {
+		collected, err := collector.Collect(context.Background())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		values := make(map[string]float64)
+		for _, metric := range collected {
+			if metric.Name == "http_transaction_failure" {
+				values[metric.Tags["step"]+"/"+metric.Tags["reason"]] = metric.Value
+			}
+		}
+		return values
+	}
+
+	got := failures()
+	want := map[string]float64{
+		"login/request":      0,
+		"login/status":       0,
+		"login/extract":      0,
+		"data/request":       0,
+		"data/variable":      0,
+		"data/status":        1,
+		"data/body_contains": 1,
+	}
+	assertFailureValues(t, "failing", got, want)
+
+	healthy.Store(true)
+	got = failures()
+	want["data/status"] = 0
+	want["data/body_contains"] = 0
+	assertFailureValues(t, "recovered", got, want)
+}
+
+func TestHTTPTransactionDuplicateNames(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/second" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	step := func(path string) []metrics.HTTPStep {
+		return []metrics.HTTPStep{{Name: "get", Method: http.MethodGet, URL: server.URL + path, Timeout: time.Second}}
+	}
+	collector := NewHTTPTransactionCollector(time.Minute, []metrics.HTTPTransaction{
+		{Steps: step("/first")},
+		{Steps: step("/second")},
+	}, logger)
+	if err := collector.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	collected, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// Each transaction runs its own steps, even without distinct names
+	var successes []float64
+	for _, metric := range collected {
+		if metric.Name == "http_transaction_success" {
+			successes = append(successes, metric.Value)
+		}
+	}
+	if len(successes) != 2 || successes[0] != 1 || successes[1] != 0 {
+		t.Errorf("http_transaction_success = %v, want [1 0]", successes)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+// assertFailureValues compares http_transaction_failure values by step/reason
+func assertFailureValues(t *testing.T, name string, got, want map[string]float64) {
+	t.Helper()
+	for key, value := range want {
+		if actual, ok := got[key]; !ok || actual != value {
+			t.Errorf("%s: http_transaction_failure %s = %v (present %v), want %v", name, key, actual, ok, value)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			t.Errorf("%s: unexpected http_transaction_failure %s", name, key)
+		}
+	}
+}
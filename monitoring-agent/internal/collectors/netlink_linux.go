@@ -0,0 +1,156 @@
+package collectors
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// netlinkReceiveBufferSize is large enough for a full batch of dump responses
+const netlinkReceiveBufferSize = 64 * 1024
+
+// netlinkDump sends a dump request on the given netlink protocol and returns
+// every message of the multi-part response
+func netlinkDump(protocol int, msgType uint16, payload []byte) ([]syscall.NetlinkMessage, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, protocol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+
+	seq := uint32(os.Getpid())
+	request := make([]byte, syscall.NLMSG_HDRLEN+len(payload))
+	binary.LittleEndian.PutUint32(request[0:4], uint32(len(request)))
+	binary.LittleEndian.PutUint16(request[4:6], msgType)
+	binary.LittleEndian.PutUint16(request[6:8], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	binary.LittleEndian.PutUint32(request[8:12], seq)
+	copy(request[syscall.NLMSG_HDRLEN:], payload)
+
+	if err := syscall.Sendto(fd, request, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("failed to send netlink request: %w", err)
+	}
+
+	var messages []syscall.NetlinkMessage
+	buf := make([]byte, netlinkReceiveBufferSize)
+
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive netlink response: %w", err)
+		}
+
+		received, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse netlink response: %w", err)
+		}
+
+		for _, message := range received {
+			if message.Header.Seq != seq {
+				continue
+			}
+
+			switch message.Header.Type {
+			case syscall.NLMSG_DONE:
+				return messages, nil
+			case syscall.NLMSG_ERROR:
+				if len(message.Data) >= 4 {
+					if errno := int32(binary.LittleEndian.Uint32(message.Data[0:4])); errno != 0 {
+						return nil, fmt.Errorf("netlink request failed: %w", syscall.Errno(-errno))
+					}
+				}
+				return messages, nil
+			default:
+				messages = append(messages, message)
+			}
+		}
+	}
+}
+
+// netlinkAttrTypeMask strips the NLA_F_NESTED and NLA_F_NET_BYTEORDER flags from an attribute type
+const netlinkAttrTypeMask = 0x3fff
+
+// parseNetlinkAttrs decodes a sequence of netlink attributes into a map keyed by attribute type
+func parseNetlinkAttrs(data []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+
+	for len(data) >= syscall.SizeofRtAttr {
+		attrLen := int(binary.LittleEndian.Uint16(data[0:2]))
+		attrType := binary.LittleEndian.Uint16(data[2:4]) & netlinkAttrTypeMask
+		if attrLen < syscall.SizeofRtAttr || attrLen > len(data) {
+			break
+		}
+
+		attrs[attrType] = data[syscall.SizeofRtAttr:attrLen]
+
+		next := (attrLen + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if next > len(data) {
+			break
+		}
+		data = data[next:]
+	}
+
+	return attrs
+}
+
+// rtnetlinkPollInterval bounds how long a subscription blocks before checking for cancellation
+const rtnetlinkPollInterval = time.Second
+
+// rtnetlink multicast groups from linux/rtnetlink.h missing from the syscall package
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4IfAddr = 0x10
+	rtmgrpIPv4Route  = 0x40
+	rtmgrpIPv6IfAddr = 0x100
+	rtmgrpIPv6Route  = 0x400
+)
+
+// watchRtnetlink subscribes to the given rtnetlink multicast groups and calls
+// notify after each batch of notifications until ctx is cancelled
+func watchRtnetlink(ctx context.Context, groups uint32, notify func()) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: groups}); err != nil {
+		return fmt.Errorf("failed to subscribe to netlink groups: %w", err)
+	}
+
+	timeout := syscall.NsecToTimeval(rtnetlinkPollInterval.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout); err != nil {
+		return fmt.Errorf("failed to set netlink receive timeout: %w", err)
+	}
+
+	buf := make([]byte, netlinkReceiveBufferSize)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			switch err {
+			case syscall.EAGAIN, syscall.EINTR:
+				continue
+			case syscall.ENOBUFS:
+				// Notifications were dropped; re-read the full state
+				notify()
+				continue
+			}
+			return fmt.Errorf("failed to receive netlink notification: %w", err)
+		}
+
+		// A single change often produces several messages; handle them together
+		if messages, err := syscall.ParseNetlinkMessage(buf[:n]); err == nil && len(messages) > 0 {
+			notify()
+		}
+	}
+}
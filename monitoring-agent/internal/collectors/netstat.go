@@ -0,0 +1,241 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// netstatSource locates a protocol section inside the /proc/net counter files
+type netstatSource struct {
+	file    string
+	section string
+}
+
+// netstatSources maps protocol names to their counter section
+var netstatSources = map[string]netstatSource{
+	"ip":       {"snmp", "Ip"},
+	"icmp":     {"snmp", "Icmp"},
+	"icmpmsg":  {"snmp", "IcmpMsg"},
+	"tcp":      {"snmp", "Tcp"},
+	"udp":      {"snmp", "Udp"},
+	"udplite":  {"snmp", "UdpLite"},
+	"tcpext":   {"netstat", "TcpExt"},
+	"ipext":    {"netstat", "IpExt"},
+	"mptcpext": {"netstat", "MPTcpExt"},
+	"ip6":      {"snmp6", "Ip6"},
+	"icmp6":    {"snmp6", "Icmp6"},
+	"udp6":     {"snmp6", "Udp6"},
+	"udplite6": {"snmp6", "UdpLite6"},
+}
+
+// netstatSNMP6Prefixes are the name prefixes used in /proc/net/snmp6
+var netstatSNMP6Prefixes = []string{"Ip6", "Icmp6", "Udp6", "UdpLite6"}
+
+// netstatGauges are values that describe current state or settings rather than event counts
+var netstatGauges = map[string]bool{
+	"Ip.Forwarding":    true,
+	"Ip.DefaultTTL":    true,
+	"Tcp.RtoAlgorithm": true,
+	"Tcp.RtoMin":       true,
+	"Tcp.RtoMax":       true,
+	"Tcp.MaxConn":      true,
+	"Tcp.CurrEstab":    true,
+}
+
+// NetstatCollector collects kernel protocol counters from /proc/net/snmp,
+// /proc/net/netstat and /proc/net/snmp6
+type NetstatCollector struct {
+	interval  time.Duration
+	protocols []string
+	procRoot  string
+	rates     *counterRates
+	logger    *logrus.Logger
+}
+
+// NewNetstatCollector creates a new kernel protocol counters collector
+func NewNetstatCollector(interval time.Duration, protocols []string, procRoot string, logger *logrus.Logger) *NetstatCollector {
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+
+	return &NetstatCollector{
+		interval:  interval,
+		protocols: protocols,
+		procRoot:  procRoot,
+		rates:     newCounterRates(),
+		logger:    logger,
+	}
+}
+
+// Name returns the collector name
+func (nc *NetstatCollector) Name() string {
+	return "netstat"
+}
+
+// Interval returns the collection interval
+func (nc *NetstatCollector) Interval() time.Duration {
+	return nc.interval
+}
+
+// Start initializes the collector
+func (nc *NetstatCollector) Start(ctx context.Context) error {
+	nc.logger.WithFields(logrus.Fields{
+		"protocols": nc.protocols,
+		"proc_root": nc.procRoot,
+	}).Info("Starting netstat collector")
+
+	for _, protocol := range nc.protocols {
+		if _, ok := netstatSources[protocol]; !ok {
+			return fmt.Errorf("unknown netstat protocol: %s", protocol)
+		}
+	}
+
+	// Initialize with first measurement
+	counters, err := nc.readCounters()
+	if err != nil {
+		return fmt.Errorf("failed to get initial protocol counters: %w", err)
+	}
+	currentTime := time.Now()
+	for key, value := range counters {
+		if !netstatGauges[key] {
+			nc.rates.observe(key, uint64(value), counterNative, currentTime)
+		}
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (nc *NetstatCollector) Stop() error {
+	nc.logger.Info("Stopping netstat collector")
+	return nil
+}
+
+// Collect gathers kernel protocol counters and their per-second rates
+func (nc *NetstatCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	counters, err := nc.readCounters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protocol counters: %w", err)
+	}
+
+	var collectedMetrics []metrics.Metric
+
+	for _, protocol := range nc.protocols {
+		source := netstatSources[protocol]
+		tags := map[string]string{
+			"protocol": protocol,
+		}
+
+		for key, value := range counters {
+			field, ok := strings.CutPrefix(key, source.section+".")
+			if !ok {
+				continue
+			}
+			name := "netstat_" + snakeCase(field)
+
+			if netstatGauges[key] {
+				collectedMetrics = append(collectedMetrics, metrics.Metric{
+					Name:      name,
+					Value:     float64(value),
+					Unit:      "count",
+					Timestamp: currentTime,
+					Tags:      tags,
+					Type:      metrics.MetricTypeGauge,
+				})
+				continue
+			}
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      name + "_total",
+				Value:     float64(value),
+				Unit:      "count",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeCounter,
+			})
+
+			if _, rate, ok := nc.rates.observe(key, uint64(value), counterNative, currentTime); ok {
+				collectedMetrics = append(collectedMetrics, metrics.Metric{
+					Name:      name + "_per_sec",
+					Value:     rate,
+					Unit:      "count/sec",
+					Timestamp: currentTime,
+					Tags:      tags,
+					Type:      metrics.MetricTypeGauge,
+				})
+			}
+		}
+	}
+
+	nc.rates.sweep()
+	collectedMetrics = append(collectedMetrics, nc.rates.resetMetric("netstat_counter_resets_total", currentTime))
+
+	nc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected netstat metrics")
+	return collectedMetrics, nil
+}
+
+// readCounters reads every counter file needed by the configured protocols,
+// keyed as "Section.Field"
+func (nc *NetstatCollector) readCounters() (map[string]int64, error) {
+	files := make(map[string]bool)
+	for _, protocol := range nc.protocols {
+		files[netstatSources[protocol].file] = true
+	}
+
+	counters := make(map[string]int64)
+	for file := range files {
+		path := procPath(nc.procRoot, "net", file)
+
+		var sections map[string]map[string]int64
+		var err error
+		if file == "snmp6" {
+			sections, err = readProcNetSNMP6(path, netstatSNMP6Prefixes)
+		} else {
+			sections, err = readProcNetSNMP(path)
+		}
+		if err != nil {
+			// snmp6 is missing when IPv6 is disabled
+			if os.IsNotExist(err) && file == "snmp6" {
+				continue
+			}
+			return nil, err
+		}
+
+		for section, fields := range sections {
+			for field, value := range fields {
+				counters[section+"."+field] = value
+			}
+		}
+	}
+
+	return counters, nil
+}
+
+// snakeCase converts kernel counter names such as "RcvbufErrors" or
+// "TCPTimeouts" to "rcvbuf_errors" and "tcp_timeouts"
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			acronymEnd := unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
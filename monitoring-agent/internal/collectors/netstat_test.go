@@ -0,0 +1,107 @@
+package collectors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNetstatReadCounters(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	protocols := make([]string, 0, len(netstatSources))
+	for protocol := range netstatSources {
+		protocols = append(protocols, protocol)
+	}
+	collector := NewNetstatCollector(0, protocols, "testdata/proc", logger)
+
+	counters, err := collector.readCounters()
+	if err != nil {
+		t.Fatalf("readCounters() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want int64
+	}{
+		{"Ip.Forwarding", 2},
+		{"Ip.InReceives", 19344},
+		{"Ip.OutTransmits", 19592},
+		{"Icmp.InDestUnreachs", 6},
+		{"Icmp.OutMsgs", 5},
+		{"IcmpMsg.InType3", 6},
+		{"Tcp.MaxConn", -1},
+		{"Tcp.ActiveOpens", 195},
+		{"Tcp.CurrEstab", 2},
+		{"Tcp.OutRsts", 44},
+		{"Udp.InDatagrams", 111},
+		{"Udp.NoPorts", 5},
+		{"Udp.OutDatagrams", 117},
+		{"UdpLite.InDatagrams", 42},
+		{"UdpLite.NoPorts", 3},
+		{"UdpLite.OutDatagrams", 40},
+		{"TcpExt.DelayedACKs", 40},
+		{"TcpExt.TCPTimeouts", 0},
+		{"Ip6.InReceives", 3},
+		{"Icmp6.OutMsgs", 6},
+		{"Udp6.InDatagrams", 0},
+		{"UdpLite6.InDatagrams", 0},
+	}
+
+	for _, tt := range tests {
+		got, ok := counters[tt.key]
+		if !ok {
+			t.Errorf("counter %s missing", tt.key)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("counter %s = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNetstatReadCountersWithoutSNMP6(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// snmp6 is absent when IPv6 is disabled, which is not an error
+	collector := NewNetstatCollector(0, []string{"tcp", "udp6"}, "testdata/proc/missing", logger)
+	if _, err := collector.readCounters(); err == nil {
+		t.Fatal("readCounters() without snmp succeeded, want an error")
+	}
+
+	collector = NewNetstatCollector(0, []string{"ip6"}, "testdata/proc/missing", logger)
+	counters, err := collector.readCounters()
+	if err != nil {
+		t.Fatalf("readCounters() without snmp6 error = %v", err)
+	}
+	if len(counters) != 0 {
+		t.Errorf("readCounters() without snmp6 = %v, want no counters", counters)
+	}
+}
+
+func TestReadProcNetSNMPMalformed(t *testing.T) {
+	if _, err := readProcNetSNMP("testdata/proc/net/snmp6"); err == nil {
+		t.Error("readProcNetSNMP() of a file in the snmp6 format succeeded, want an error")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"InReceives", "in_receives"},
+		{"RcvbufErrors", "rcvbuf_errors"},
+		{"TCPTimeouts", "tcp_timeouts"},
+		{"InType3", "in_type3"},
+	}
+
+	for _, tt := range tests {
+		if got := snakeCase(tt.name); got != tt.want {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package collectors
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
@@ -10,20 +11,32 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// NetworkCollector collects network interface metrics
+// NetworkCollector collects network interface metrics, link state and address
+// inventory, and reports interface changes as they happen
 type NetworkCollector struct {
 	interval        time.Duration
-	lastStats       map[string]net.IOCountersStat
-	lastTimestamp   time.Time
+	sysRoot         string
+	rates           *counterRates
+	lastStates      map[string]interfaceState
+	eventSink       func(metrics.Metric)
+	stateMutex      sync.Mutex
+	watchCancel     context.CancelFunc
+	watchWg         sync.WaitGroup
 	logger          *logrus.Logger
 }
 
-// NewNetworkCollector creates a new network interface collector
-func NewNetworkCollector(interval time.Duration, logger *logrus.Logger) *NetworkCollector {
+// NewNetworkCollector creates a new network interface collector that reads
+// link details from the sysfs beside procRoot
+func NewNetworkCollector(interval time.Duration, procRoot string, logger *logrus.Logger) *NetworkCollector {
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+	
 	return &NetworkCollector{
 		interval:      interval,
-		lastStats:     make(map[string]net.IOCountersStat),
-		lastTimestamp: time.Now(),
+		sysRoot:       sysRoot(procRoot),
+		rates:         newCounterRates(),
+		lastStates:    make(map[string]interfaceState),
 		logger:        logger,
 	}
 }
@@ -47,10 +60,29 @@ func (nc *NetworkCollector) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to get initial network stats: %w", err)
 	}
 	
+	currentTime := time.Now()
 	for _, stat := range stats {
-		nc.lastStats[stat.Name] = stat
+		for _, field := range networkRateFields(stat) {
+			nc.rates.observe(stat.Name+"|"+field.name, field.value, counter64, currentTime)
+		}
+	}
+
+	states, err := readInterfaceStates(nc.sysRoot)
+	if err != nil {
+		return fmt.Errorf("failed to get initial interface state: %w", err)
 	}
-	nc.lastTimestamp = time.Now()
+	nc.lastStates = states
+
+	// Subscribe to link and address changes
+	watchCtx, cancel := context.WithCancel(ctx)
+	nc.watchCancel = cancel
+	nc.watchWg.Add(1)
+	go func() {
+		defer nc.watchWg.Done()
+		if err := watchLinkChanges(watchCtx, nc.handleLinkChange); err != nil {
+			nc.logger.WithError(err).Warn("Interface change notifications unavailable, reporting changes on the collection interval")
+		}
+	}()
 	
 	return nil
 }
@@ -58,13 +90,53 @@ func (nc *NetworkCollector) Start(ctx context.Context) error {
 // Stop shuts down the collector
 func (nc *NetworkCollector) Stop() error {
 	nc.logger.Info("Stopping network interface collector")
+
+	if nc.watchCancel != nil {
+		nc.watchCancel()
+		nc.watchWg.Wait()
+	}
 	return nil
 }
 
+// SetEventSink sets where interface change events are sent between collections
+func (nc *NetworkCollector) SetEventSink(sink func(metrics.Metric)) {
+	nc.stateMutex.Lock()
+	defer nc.stateMutex.Unlock()
+	nc.eventSink = sink
+}
+
+// handleLinkChange re-reads interface state after a netlink notification and
+// emits the resulting change events immediately
+func (nc *NetworkCollector) handleLinkChange() {
+	nc.stateMutex.Lock()
+	defer nc.stateMutex.Unlock()
+
+	// Without a sink, leave the changes for the next collection to report
+	if nc.eventSink == nil {
+		return
+	}
+
+	states, err := readInterfaceStates(nc.sysRoot)
+	if err != nil {
+		nc.logger.WithError(err).Warn("Failed to read interface state")
+		return
+	}
+
+	events := diffInterfaceStates(nc.lastStates, states, time.Now())
+	nc.lastStates = states
+
+	for _, event := range events {
+		nc.logger.WithFields(logrus.Fields{
+			"interface": event.Tags["interface"],
+			"event":     event.Tags["event"],
+		}).Info("Network interface changed")
+		nc.eventSink(event)
+	}
+}
+
 // Collect gathers network interface metrics
 func (nc *NetworkCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
 	currentTime := time.Now()
-	timeDelta := currentTime.Sub(nc.lastTimestamp).Seconds()
 	
 	stats, err := net.IOCounters(true)
 	if err != nil {
@@ -76,8 +148,8 @@ func (nc *NetworkCollector) Collect(ctx context.Context) ([]metrics.Metric, erro
 	for _, currentStat := range stats {
 		interfaceName := currentStat.Name
 		
-		// Skip loopback and inactive interfaces
-		if interfaceName == "lo" || currentStat.BytesRecv == 0 && currentStat.BytesSent == 0 {
+		// Skip loopback interface
+		if interfaceName == "lo" {
 			continue
 		}
 		
@@ -86,88 +158,17 @@ func (nc *NetworkCollector) Collect(ctx context.Context) ([]metrics.Metric, erro
 		}
 		
 		// Calculate rates if we have previous data
-		if lastStat, exists := nc.lastStats[interfaceName]; exists && timeDelta > 0 {
-			// Bytes per second
-			rxBytesPerSec := float64(currentStat.BytesRecv-lastStat.BytesRecv) / timeDelta
-			txBytesPerSec := float64(currentStat.BytesSent-lastStat.BytesSent) / timeDelta
-			
-			// Packets per second
-			rxPacketsPerSec := float64(currentStat.PacketsRecv-lastStat.PacketsRecv) / timeDelta
-			txPacketsPerSec := float64(currentStat.PacketsSent-lastStat.PacketsSent) / timeDelta
-			
-			// Errors and drops per second
-			rxErrorsPerSec := float64(currentStat.Errin-lastStat.Errin) / timeDelta
-			txErrorsPerSec := float64(currentStat.Errout-lastStat.Errout) / timeDelta
-			rxDropsPerSec := float64(currentStat.Dropin-lastStat.Dropin) / timeDelta
-			txDropsPerSec := float64(currentStat.Dropout-lastStat.Dropout) / timeDelta
-			
-			// Rate metrics
-			collectedMetrics = append(collectedMetrics, []metrics.Metric{
-				{
-					Name:      "network_interface_rx_bytes_per_sec",
-					Value:     rxBytesPerSec,
-					Unit:      "bytes/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_tx_bytes_per_sec",
-					Value:     txBytesPerSec,
-					Unit:      "bytes/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_rx_packets_per_sec",
-					Value:     rxPacketsPerSec,
-					Unit:      "packets/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_tx_packets_per_sec",
-					Value:     txPacketsPerSec,
-					Unit:      "packets/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_rx_errors_per_sec",
-					Value:     rxErrorsPerSec,
-					Unit:      "errors/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_tx_errors_per_sec",
-					Value:     txErrorsPerSec,
-					Unit:      "errors/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_rx_drops_per_sec",
-					Value:     rxDropsPerSec,
-					Unit:      "drops/sec",
+		for _, field := range networkRateFields(currentStat) {
+			if _, rate, ok := nc.rates.observe(interfaceName+"|"+field.name, field.value, counter64, currentTime); ok {
+				collectedMetrics = append(collectedMetrics, metrics.Metric{
+					Name:      field.name,
+					Value:     rate,
+					Unit:      field.unit,
 					Timestamp: currentTime,
 					Tags:      tags,
 					Type:      metrics.MetricTypeGauge,
-				},
-				{
-					Name:      "network_interface_tx_drops_per_sec",
-					Value:     txDropsPerSec,
-					Unit:      "drops/sec",
-					Timestamp: currentTime,
-					Tags:      tags,
-					Type:      metrics.MetricTypeGauge,
-				},
-			}...)
+				})
+			}
 		}
 		
 		// Cumulative counters
@@ -205,13 +206,46 @@ func (nc *NetworkCollector) Collect(ctx context.Context) ([]metrics.Metric, erro
 				Type:      metrics.MetricTypeCounter,
 			},
 		}...)
-		
-		// Update last stats
-		nc.lastStats[interfaceName] = currentStat
 	}
 	
-	nc.lastTimestamp = currentTime
+	// Interfaces that disappeared start from a fresh baseline if they return
+	nc.rates.sweep()
+	collectedMetrics = append(collectedMetrics, nc.rates.resetMetric("network_interface_counter_resets_total", currentTime))
+
+	// Link state and address inventory, plus any changes not already reported
+	nc.stateMutex.Lock()
+	states, err := readInterfaceStates(nc.sysRoot)
+	if err != nil {
+		nc.stateMutex.Unlock()
+		return nil, fmt.Errorf("failed to get interface state: %w", err)
+	}
+	collectedMetrics = append(collectedMetrics, diffInterfaceStates(nc.lastStates, states, currentTime)...)
+	nc.lastStates = states
+	nc.stateMutex.Unlock()
+
+	collectedMetrics = append(collectedMetrics, interfaceStateMetrics(states, currentTime)...)
 	
 	nc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected network interface metrics")
 	return collectedMetrics, nil
-} 
\ No newline at end of file
+} 
+
+// networkRateField is an interface counter reported as a per-second rate
+type networkRateField struct {
+	name  string
+	unit  string
+	value uint64
+}
+
+// networkRateFields lists the interface counters reported as per-second rates
+func networkRateFields(stat net.IOCountersStat) []networkRateField {
+	return []networkRateField{
+		{"network_interface_rx_bytes_per_sec", "bytes/sec", stat.BytesRecv},
+		{"network_interface_tx_bytes_per_sec", "bytes/sec", stat.BytesSent},
+		{"network_interface_rx_packets_per_sec", "packets/sec", stat.PacketsRecv},
+		{"network_interface_tx_packets_per_sec", "packets/sec", stat.PacketsSent},
+		{"network_interface_rx_errors_per_sec", "errors/sec", stat.Errin},
+		{"network_interface_tx_errors_per_sec", "errors/sec", stat.Errout},
+		{"network_interface_rx_drops_per_sec", "drops/sec", stat.Dropin},
+		{"network_interface_tx_drops_per_sec", "drops/sec", stat.Dropout},
+	}
+}
@@ -0,0 +1,8 @@
+package collectors
+
+import "context"
+
+// watchLinkChanges calls notify whenever an interface or address changes
+func watchLinkChanges(ctx context.Context, notify func()) error {
+	return watchRtnetlink(ctx, rtmgrpLink|rtmgrpIPv4IfAddr|rtmgrpIPv6IfAddr, notify)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package collectors
+
+import "context"
+
+// watchLinkChanges is only implemented on Linux
+func watchLinkChanges(ctx context.Context, notify func()) error {
+	return errLinkWatchUnsupported
+}
@@ -0,0 +1,299 @@
+package collectors
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// errLinkWatchUnsupported is returned where rtnetlink notifications are not available
+var errLinkWatchUnsupported = errors.New("interface change notifications are not supported on this platform")
+
+// interfaceState is a point-in-time view of an interface's link and addressing
+type interfaceState struct {
+	up             bool
+	carrier        int   // 1 or 0, -1 when unknown
+	carrierChanges int64 // -1 when unknown
+	speed          int64 // Mbit/s, -1 when unknown
+	duplex         string
+	mtu            int
+	addresses      map[string]string // address to family
+}
+
+// readInterfaceStates takes an inventory of all non-loopback interfaces, with
+// link details from the sysfs at root where the platform provides them
+func readInterfaceStates(root string) (map[string]interfaceState, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]interfaceState, len(ifaces))
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		state := interfaceState{
+			up:             iface.Flags&net.FlagUp != 0,
+			carrier:        -1,
+			carrierChanges: -1,
+			speed:          -1,
+			mtu:            iface.MTU,
+			addresses:      make(map[string]string),
+		}
+
+		// The kernel refuses to report carrier and speed for interfaces that are down
+		if value, ok := readSysfsInt(root, iface.Name, "carrier"); ok {
+			state.carrier = int(value)
+		}
+		if value, ok := readSysfsInt(root, iface.Name, "carrier_changes"); ok {
+			state.carrierChanges = value
+		}
+		if value, ok := readSysfsInt(root, iface.Name, "speed"); ok && value > 0 {
+			state.speed = value
+		}
+		if value, ok := readSysfsString(root, iface.Name, "duplex"); ok && value != "unknown" {
+			state.duplex = value
+		}
+
+		addrs, err := iface.Addrs()
+		if err == nil {
+			for _, addr := range addrs {
+				family := "ipv6"
+				if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+					family = "ipv4"
+				}
+				state.addresses[addr.String()] = family
+			}
+		}
+
+		states[iface.Name] = state
+	}
+
+	return states, nil
+}
+
+// readSysfsString reads an attribute from <root>/class/net/<iface>
+func readSysfsString(root, iface, attribute string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(root, "class", "net", iface, attribute))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// readSysfsInt reads an integer attribute from <root>/class/net/<iface>
+func readSysfsInt(root, iface, attribute string) (int64, bool) {
+	value, ok := readSysfsString(root, iface, attribute)
+	if !ok {
+		return 0, false
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// interfaceStateMetrics reports link state and address inventory for each interface
+func interfaceStateMetrics(states map[string]interfaceState, timestamp time.Time) []metrics.Metric {
+	var collectedMetrics []metrics.Metric
+
+	for name, state := range states {
+		tags := map[string]string{
+			"interface": name,
+		}
+
+		collectedMetrics = append(collectedMetrics,
+			metrics.Metric{
+				Name:      "network_interface_up",
+				Value:     boolToFloat(state.up),
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			metrics.Metric{
+				Name:      "network_interface_mtu",
+				Value:     float64(state.mtu),
+				Unit:      "bytes",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		)
+
+		if state.carrier >= 0 {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_carrier",
+				Value:     float64(state.carrier),
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+		if state.carrierChanges >= 0 {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_carrier_changes_total",
+				Value:     float64(state.carrierChanges),
+				Unit:      "count",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeCounter,
+			})
+		}
+		if state.speed > 0 {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_speed_mbps",
+				Value:     float64(state.speed),
+				Unit:      "Mbit/s",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+		if state.duplex != "" {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_full_duplex",
+				Value:     boolToFloat(state.duplex == "full"),
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+
+		// Address inventory
+		familyCounts := map[string]int{"ipv4": 0, "ipv6": 0}
+		for address, family := range state.addresses {
+			familyCounts[family]++
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_address_info",
+				Value:     1,
+				Unit:      "boolean",
+				Timestamp: timestamp,
+				Tags: map[string]string{
+					"interface": name,
+					"address":   address,
+					"family":    family,
+				},
+				Type: metrics.MetricTypeGauge,
+			})
+		}
+		for family, count := range familyCounts {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "network_interface_addresses",
+				Value:     float64(count),
+				Unit:      "count",
+				Timestamp: timestamp,
+				Tags: map[string]string{
+					"interface": name,
+					"family":    family,
+				},
+				Type: metrics.MetricTypeGauge,
+			})
+		}
+	}
+
+	return collectedMetrics
+}
+
+// diffInterfaceStates returns a network_interface_event for every change between two inventories
+func diffInterfaceStates(previous, current map[string]interfaceState, timestamp time.Time) []metrics.Metric {
+	var events []metrics.Metric
+
+	event := func(iface, kind string, extra map[string]string) {
+		tags := map[string]string{
+			"interface": iface,
+			"event":     kind,
+		}
+		for key, value := range extra {
+			tags[key] = value
+		}
+		events = append(events, metrics.Metric{
+			Name:      "network_interface_event",
+			Value:     1,
+			Unit:      "event",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+	}
+
+	// Report in a stable order so bursts of events read naturally
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cur := current[name]
+		prev, exists := previous[name]
+		if !exists {
+			event(name, "interface_added", nil)
+			continue
+		}
+
+		if prev.up != cur.up {
+			if cur.up {
+				event(name, "link_up", nil)
+			} else {
+				event(name, "link_down", nil)
+			}
+		}
+		if prev.carrier >= 0 && cur.carrier >= 0 && prev.carrier != cur.carrier {
+			if cur.carrier == 1 {
+				event(name, "carrier_up", nil)
+			} else {
+				event(name, "carrier_down", nil)
+			}
+		}
+		if prev.speed > 0 && cur.speed > 0 && prev.speed != cur.speed {
+			event(name, "speed_changed", map[string]string{
+				"previous": strconv.FormatInt(prev.speed, 10),
+				"current":  strconv.FormatInt(cur.speed, 10),
+			})
+		}
+		if prev.duplex != "" && cur.duplex != "" && prev.duplex != cur.duplex {
+			event(name, "duplex_changed", map[string]string{
+				"previous": prev.duplex,
+				"current":  cur.duplex,
+			})
+		}
+		if prev.mtu != cur.mtu {
+			event(name, "mtu_changed", map[string]string{
+				"previous": strconv.Itoa(prev.mtu),
+				"current":  strconv.Itoa(cur.mtu),
+			})
+		}
+
+		for address, family := range cur.addresses {
+			if _, exists := prev.addresses[address]; !exists {
+				event(name, "address_added", map[string]string{"address": address, "family": family})
+			}
+		}
+		for address, family := range prev.addresses {
+			if _, exists := cur.addresses[address]; !exists {
+				event(name, "address_removed", map[string]string{"address": address, "family": family})
+			}
+		}
+	}
+
+	for name := range previous {
+		if _, exists := current[name]; !exists {
+			event(name, "interface_removed", nil)
+		}
+	}
+
+	return events
+}
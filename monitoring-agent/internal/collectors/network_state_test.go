@@ -0,0 +1,177 @@
+package collectors
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testInterfaceState returns an up interface with full-duplex 1 Gbit/s carrier
+func testInterfaceState(addresses ...string) interfaceState {
+	state := interfaceState{
+		up:             true,
+		carrier:        1,
+		carrierChanges: 2,
+		speed:          1000,
+		duplex:         "full",
+		mtu:            1500,
+		addresses:      make(map[string]string),
+	}
+	for _, address := range addresses {
+		family := "ipv4"
+		if strings.Contains(address, ":") {
+			family = "ipv6"
+		}
+		state.addresses[address] = family
+	}
+	return state
+}
+
+// eventSummaries describes each network_interface_event as
+// "interface event key=value ...", sorted
+func eventSummaries(previous, current map[string]interfaceState) []string {
+	var summaries []string
+	for _, event := range diffInterfaceStates(previous, current, time.Now()) {
+		if event.Name != "network_interface_event" || event.Value != 1 {
+			summaries = append(summaries, "unexpected metric "+event.Name)
+			continue
+		}
+
+		summary := event.Tags["interface"] + " " + event.Tags["event"]
+		var extra []string
+		for key, value := range event.Tags {
+			if key != "interface" && key != "event" {
+				extra = append(extra, key+"="+value)
+			}
+		}
+		sort.Strings(extra)
+		if len(extra) > 0 {
+			summary += " " + strings.Join(extra, " ")
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Strings(summaries)
+	return summaries
+}
+
+func TestDiffInterfaceStates(t *testing.T) {
+	base := testInterfaceState("10.0.0.5/24", "fe80::1/64")
+
+	tests := []struct {
+		name   string
+		modify func(state *interfaceState)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(state *interfaceState) {},
+		},
+		{
+			name:   "link down",
+			modify: func(state *interfaceState) { state.up = false },
+			want:   []string{"eth0 link_down"},
+		},
+		{
+			name:   "carrier lost",
+			modify: func(state *interfaceState) { state.carrier = 0 },
+			want:   []string{"eth0 carrier_down"},
+		},
+		{
+			name:   "carrier unknown while down",
+			modify: func(state *interfaceState) { state.up, state.carrier, state.speed, state.duplex = false, -1, -1, "" },
+			want:   []string{"eth0 link_down"},
+		},
+		{
+			name:   "speed change",
+			modify: func(state *interfaceState) { state.speed = 100 },
+			want:   []string{"eth0 speed_changed current=100 previous=1000"},
+		},
+		{
+			name:   "duplex change",
+			modify: func(state *interfaceState) { state.duplex = "half" },
+			want:   []string{"eth0 duplex_changed current=half previous=full"},
+		},
+		{
+			name:   "MTU change",
+			modify: func(state *interfaceState) { state.mtu = 9000 },
+			want:   []string{"eth0 mtu_changed current=9000 previous=1500"},
+		},
+		{
+			name: "address added and removed",
+			modify: func(state *interfaceState) {
+				state.addresses = map[string]string{"10.0.0.6/24": "ipv4", "fe80::1/64": "ipv6", "2001:db8::5/64": "ipv6"}
+			},
+			want: []string{
+				"eth0 address_added address=10.0.0.6/24 family=ipv4",
+				"eth0 address_added address=2001:db8::5/64 family=ipv6",
+				"eth0 address_removed address=10.0.0.5/24 family=ipv4",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		current := base
+		current.addresses = make(map[string]string)
+		for address, family := range base.addresses {
+			current.addresses[address] = family
+		}
+		tt.modify(&current)
+
+		got := eventSummaries(map[string]interfaceState{"eth0": base}, map[string]interfaceState{"eth0": current})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: events = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffInterfaceStatesLinkFlap(t *testing.T) {
+	up := map[string]interfaceState{"eth0": testInterfaceState("10.0.0.5/24")}
+	downState := testInterfaceState()
+	downState.up, downState.carrier, downState.speed, downState.duplex = false, 0, -1, ""
+	down := map[string]interfaceState{"eth0": downState}
+
+	if got, want := eventSummaries(up, down), []string{
+		"eth0 address_removed address=10.0.0.5/24 family=ipv4",
+		"eth0 carrier_down",
+		"eth0 link_down",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("going down: events = %q, want %q", got, want)
+	}
+
+	if got, want := eventSummaries(down, up), []string{
+		"eth0 address_added address=10.0.0.5/24 family=ipv4",
+		"eth0 carrier_up",
+		"eth0 link_up",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("coming back: events = %q, want %q", got, want)
+	}
+}
+
+func TestDiffInterfaceStatesAddedAndRemoved(t *testing.T) {
+	previous := map[string]interfaceState{"eth0": testInterfaceState(), "veth1": testInterfaceState()}
+	current := map[string]interfaceState{"eth0": testInterfaceState(), "wg0": testInterfaceState()}
+
+	want := []string{"veth1 interface_removed", "wg0 interface_added"}
+	if got := eventSummaries(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestReadSysfs(t *testing.T) {
+	root := "testdata/sys"
+
+	if value, ok := readSysfsInt(root, "fixture0", "speed"); !ok || value != 10000 {
+		t.Errorf("readSysfsInt(speed) = %d, %v, want 10000, true", value, ok)
+	}
+	if value, ok := readSysfsString(root, "fixture0", "duplex"); !ok || value != "full" {
+		t.Errorf("readSysfsString(duplex) = %q, %v, want full, true", value, ok)
+	}
+	if _, ok := readSysfsInt(root, "fixture0", "duplex"); ok {
+		t.Error("readSysfsInt() of a non-numeric attribute succeeded")
+	}
+	if _, ok := readSysfsString(root, "missing0", "speed"); ok {
+		t.Error("readSysfsString() of a missing interface succeeded")
+	}
+}
@@ -0,0 +1,271 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// errKernelClockUnsupported is returned where the kernel clock state cannot be read
+var errKernelClockUnsupported = errors.New("kernel clock state is not available on this platform")
+
+// kernelClock is the kernel's view of clock synchronization from adjtimex
+type kernelClock struct {
+	synced       bool
+	offset       time.Duration
+	maxError     time.Duration
+	estError     time.Duration
+	frequencyPPM float64
+}
+
+// NTPCollector measures the host clock against NTP servers and reports the
+// kernel clock discipline state. The offset to the best server is exposed to
+// the agent so outgoing batches can be tagged with it.
+type NTPCollector struct {
+	interval     time.Duration
+	config       metrics.NTPConfig
+	offset       time.Duration
+	offsetValid  bool
+	offsetMutex  sync.RWMutex
+	kernelWarned bool
+	logger       *logrus.Logger
+}
+
+// NewNTPCollector creates a new NTP clock offset and sync health collector
+func NewNTPCollector(interval time.Duration, config metrics.NTPConfig, logger *logrus.Logger) *NTPCollector {
+	return &NTPCollector{
+		interval: interval,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (nc *NTPCollector) Name() string {
+	return "ntp"
+}
+
+// Interval returns the collection interval
+func (nc *NTPCollector) Interval() time.Duration {
+	return nc.interval
+}
+
+// Start initializes the collector
+func (nc *NTPCollector) Start(ctx context.Context) error {
+	nc.logger.WithField("servers", nc.config.Servers).Info("Starting NTP collector")
+	return nil
+}
+
+// Stop shuts down the collector
+func (nc *NTPCollector) Stop() error {
+	nc.logger.Info("Stopping NTP collector")
+	return nil
+}
+
+// ClockOffset returns the offset measured against the best server in the last collection
+func (nc *NTPCollector) ClockOffset() (time.Duration, bool) {
+	nc.offsetMutex.RLock()
+	defer nc.offsetMutex.RUnlock()
+	return nc.offset, nc.offsetValid
+}
+
+// Collect queries every NTP server and reads the kernel clock state
+func (nc *NTPCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	var best *sntpResult
+	var bestServer string
+	for _, server := range nc.config.Servers {
+		tags := map[string]string{
+			"server": server,
+		}
+
+		result, err := sntpQuery(ctx, server, nc.config.Timeout, nc.config.Retries)
+		if err != nil {
+			nc.logger.WithFields(logrus.Fields{
+				"server": server,
+				"error":  err,
+			}).Warn("NTP query failed")
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "ntp_success",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+			continue
+		}
+
+		// The server with the shortest round trip gives the most accurate offset
+		if best == nil || result.delay < best.delay {
+			best = result
+			bestServer = server
+		}
+
+		collectedMetrics = append(collectedMetrics, nc.serverMetrics(result, tags, currentTime)...)
+	}
+
+	nc.offsetMutex.Lock()
+	nc.offsetValid = best != nil
+	if best != nil {
+		nc.offset = best.offset
+	}
+	nc.offsetMutex.Unlock()
+
+	// The offset stays one series as the best server changes; which server
+	// it came from is reported separately
+	if best != nil {
+		collectedMetrics = append(collectedMetrics,
+			metrics.Metric{
+				Name:      "ntp_clock_offset_ms",
+				Value:     durationToMs(best.offset),
+				Unit:      "ms",
+				Timestamp: currentTime,
+				Tags:      map[string]string{},
+				Type:      metrics.MetricTypeGauge,
+			},
+			metrics.Metric{
+				Name:      "ntp_clock_offset_server_info",
+				Value:     1,
+				Unit:      "info",
+				Timestamp: currentTime,
+				Tags: map[string]string{
+					"server": bestServer,
+				},
+				Type: metrics.MetricTypeGauge,
+			},
+		)
+	}
+
+	collectedMetrics = append(collectedMetrics, nc.kernelMetrics(currentTime)...)
+
+	nc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected NTP metrics")
+	return collectedMetrics, nil
+}
+
+// serverMetrics converts an SNTP exchange with one server into metrics
+func (nc *NTPCollector) serverMetrics(result *sntpResult, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	return []metrics.Metric{
+		{
+			Name:      "ntp_success",
+			Value:     1,
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_offset_ms",
+			Value:     durationToMs(result.offset),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_delay_ms",
+			Value:     durationToMs(result.delay),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_stratum",
+			Value:     float64(result.stratum),
+			Unit:      "stratum",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_root_delay_ms",
+			Value:     durationToMs(result.rootDelay),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_root_dispersion_ms",
+			Value:     durationToMs(result.rootDispersion),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_server_info",
+			Value:     1,
+			Unit:      "info",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"reference_id": result.referenceID,
+			}),
+			Type: metrics.MetricTypeGauge,
+		},
+	}
+}
+
+// kernelMetrics reports the kernel clock discipline state maintained by the local NTP daemon
+func (nc *NTPCollector) kernelMetrics(timestamp time.Time) []metrics.Metric {
+	clock, err := readKernelClock()
+	if err != nil {
+		if !nc.kernelWarned {
+			nc.logger.WithError(err).Debug("Kernel clock state unavailable")
+			nc.kernelWarned = true
+		}
+		return nil
+	}
+
+	tags := map[string]string{}
+	return []metrics.Metric{
+		{
+			Name:      "ntp_kernel_synced",
+			Value:     boolToFloat(clock.synced),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_kernel_offset_ms",
+			Value:     durationToMs(clock.offset),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_kernel_max_error_ms",
+			Value:     durationToMs(clock.maxError),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_kernel_est_error_ms",
+			Value:     durationToMs(clock.estError),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "ntp_kernel_frequency_ppm",
+			Value:     clock.frequencyPPM,
+			Unit:      "ppm",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+}
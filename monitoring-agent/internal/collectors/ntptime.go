@@ -0,0 +1,20 @@
+package collectors
+
+import "time"
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+const ntpEpochOffset = 2208988800
+
+// toNTPTime converts a time to the 64-bit NTP timestamp format (32-bit seconds, 32-bit fraction)
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// fromNTPTime converts a 64-bit NTP timestamp back to a time
+func fromNTPTime(ntp uint64) time.Time {
+	seconds := int64(ntp>>32) - ntpEpochOffset
+	nanos := (int64(ntp&0xffffffff) * int64(time.Second)) >> 32
+	return time.Unix(seconds, nanos)
+}
@@ -17,15 +17,18 @@ import (
 
 // PingCollector collects ping/ICMP latency metrics
 type PingCollector struct {
-	interval time.Duration
-	targets  []string
-	timeout  time.Duration
-	logger   *logrus.Logger
-	count    int
+	interval  time.Duration
+	targets   []string
+	timeout   time.Duration
+	logger    *logrus.Logger
+	count     int
+	egress    metrics.EgressConfig
+	overrides []metrics.PingEgress
+	paths     map[string]*egressPath
 }
 
 // NewPingCollector creates a new ping collector
-func NewPingCollector(interval time.Duration, targets []string, logger *logrus.Logger) *PingCollector {
+func NewPingCollector(interval time.Duration, targets []string, egress metrics.EgressConfig, overrides []metrics.PingEgress, logger *logrus.Logger) *PingCollector {
 	if len(targets) == 0 {
 		// Default targets for connectivity testing
 		targets = []string{"8.8.8.8", "1.1.1.1", "google.com", "cloudflare.com"}
@@ -35,8 +38,10 @@ func NewPingCollector(interval time.Duration, targets []string, logger *logrus.L
 		interval: interval,
 		targets:  targets,
 		timeout:  5 * time.Second,
-		logger:   logger,
-		count:    3, // Number of ping packets to send
+		logger:    logger,
+		count:     3, // Number of ping packets to send
+		egress:    egress,
+		overrides: overrides,
 	}
 }
 
@@ -54,6 +59,31 @@ func (pc *PingCollector) Interval() time.Duration {
 func (pc *PingCollector) Start(ctx context.Context) error {
 	pc.logger.WithField("targets", pc.targets).Info("Starting ping collector")
 	
+	// ICMP cannot be proxied, so only the source, interface and family apply
+	global := pc.egress
+	global.ProxyURL = ""
+	
+	pc.paths = make(map[string]*egressPath)
+	for _, target := range pc.targets {
+		var override metrics.EgressConfig
+		for _, o := range pc.overrides {
+			if o.Target == target {
+				override = o.Egress
+			}
+		}
+		
+		path, err := newEgressPath(global, override)
+		if err != nil {
+			return fmt.Errorf("invalid egress for ping target %s: %w", target, err)
+		}
+		// ping selects the source with a single option, unlike sockets that
+		// can bind to an interface and an address at once
+		if path.SourceAddress != "" && path.Interface != "" {
+			return fmt.Errorf("invalid egress for ping target %s: ping cannot use both source_address and interface", target)
+		}
+		pc.paths[target] = path
+	}
+	
 	// Test if ping command is available
 	var cmd string
 	if runtime.GOOS == "windows" {
@@ -81,7 +111,8 @@ func (pc *PingCollector) Collect(ctx context.Context) ([]metrics.Metric, error)
 	var collectedMetrics []metrics.Metric
 	
 	for _, target := range pc.targets {
-		targetMetrics, err := pc.pingTarget(ctx, target, currentTime)
+		path := pc.paths[target]
+		targetMetrics, err := pc.pingTarget(ctx, target, path, currentTime)
 		if err != nil {
 			pc.logger.WithFields(logrus.Fields{
 				"target": target,
@@ -94,9 +125,9 @@ func (pc *PingCollector) Collect(ctx context.Context) ([]metrics.Metric, error)
 				Value:     0, // Failed
 				Unit:      "boolean",
 				Timestamp: currentTime,
-				Tags: map[string]string{
+				Tags: path.tagged(map[string]string{
 					"target": target,
-				},
+				}),
 				Type: metrics.MetricTypeGauge,
 			})
 			continue
@@ -110,20 +141,20 @@ func (pc *PingCollector) Collect(ctx context.Context) ([]metrics.Metric, error)
 }
 
 // pingTarget performs ping test for a specific target
-func (pc *PingCollector) pingTarget(ctx context.Context, target string, timestamp time.Time) ([]metrics.Metric, error) {
+func (pc *PingCollector) pingTarget(ctx context.Context, target string, path *egressPath, timestamp time.Time) ([]metrics.Metric, error) {
 	// Resolve hostname to IP if needed
-	resolvedTarget, err := pc.resolveTarget(target)
+	resolvedTarget, err := pc.resolveTarget(ctx, target, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve target %s: %w", target, err)
 	}
 	
-	tags := map[string]string{
+	tags := path.tagged(map[string]string{
 		"target":    target,
 		"target_ip": resolvedTarget,
-	}
+	})
 	
 	// Execute ping command
-	results, err := pc.executePing(ctx, resolvedTarget)
+	results, err := pc.executePing(ctx, resolvedTarget, path)
 	if err != nil {
 		return nil, fmt.Errorf("ping execution failed: %w", err)
 	}
@@ -183,15 +214,15 @@ func (pc *PingCollector) pingTarget(ctx context.Context, target string, timestam
 	return collectedMetrics, nil
 }
 
-// resolveTarget resolves hostname to IP address
-func (pc *PingCollector) resolveTarget(target string) (string, error) {
+// resolveTarget resolves hostname to IP address in the egress path's IP family
+func (pc *PingCollector) resolveTarget(ctx context.Context, target string, path *egressPath) (string, error) {
 	// Check if target is already an IP address
 	if net.ParseIP(target) != nil {
 		return target, nil
 	}
 	
 	// Resolve hostname
-	ips, err := net.LookupIP(target)
+	ips, err := net.DefaultResolver.LookupIP(ctx, path.network("ip"), target)
 	if err != nil {
 		return "", err
 	}
@@ -219,18 +250,20 @@ type PingResults struct {
 }
 
 // executePing runs the actual ping command and parses results
-func (pc *PingCollector) executePing(ctx context.Context, target string) (*PingResults, error) {
-	var cmd *exec.Cmd
-	
+func (pc *PingCollector) executePing(ctx context.Context, target string, path *egressPath) (*PingResults, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, pc.timeout)
 	defer cancel()
 	
+	var args []string
 	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctxWithTimeout, "ping", "-n", fmt.Sprintf("%d", pc.count), target)
+		args = []string{"-n", fmt.Sprintf("%d", pc.count)}
 	} else {
-		cmd = exec.CommandContext(ctxWithTimeout, "ping", "-c", fmt.Sprintf("%d", pc.count), target)
+		args = []string{"-c", fmt.Sprintf("%d", pc.count)}
 	}
+	args = append(args, pc.egressArgs(path)...)
+	args = append(args, target)
 	
+	cmd := exec.CommandContext(ctxWithTimeout, "ping", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("ping command failed: %w", err)
@@ -239,10 +272,48 @@ func (pc *PingCollector) executePing(ctx context.Context, target string) (*PingR
 	return pc.parsePingOutput(string(output))
 }
 
+// egressArgs returns the ping options selecting the source address, interface and IP family
+func (pc *PingCollector) egressArgs(path *egressPath) []string {
+	var args []string
+	
+	switch runtime.GOOS {
+	case "linux":
+		switch path.IPFamily {
+		case "ipv4":
+			args = append(args, "-4")
+		case "ipv6":
+			args = append(args, "-6")
+		}
+		// -I takes an interface or a source address; Start rejects paths with both
+		if path.Interface != "" {
+			args = append(args, "-I", path.Interface)
+		} else if path.SourceAddress != "" {
+			args = append(args, "-I", path.SourceAddress)
+		}
+		
+	case "windows":
+		switch path.IPFamily {
+		case "ipv4":
+			args = append(args, "-4")
+		case "ipv6":
+			args = append(args, "-6")
+		}
+		if path.SourceAddress != "" {
+			args = append(args, "-S", path.SourceAddress)
+		}
+		
+	default:
+		// BSD ping follows the family of the resolved address
+		if path.SourceAddress != "" {
+			args = append(args, "-S", path.SourceAddress)
+		}
+	}
+	
+	return args
+}
+
 // parsePingOutput parses ping command output to extract metrics
 func (pc *PingCollector) parsePingOutput(output string) (*PingResults, error) {
-	results := &PingResults{}
-	
 	if runtime.GOOS == "windows" {
 		return pc.parseWindowsPingOutput(output)
 	}
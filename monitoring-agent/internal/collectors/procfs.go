@@ -0,0 +1,129 @@
+package collectors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultProcRoot is where procfs is mounted on a regular Linux host
+const defaultProcRoot = "/proc"
+
+// procPath joins a path relative to the proc root
+func procPath(procRoot string, elem ...string) string {
+	return filepath.Join(append([]string{procRoot}, elem...)...)
+}
+
+// sysRoot returns the sysfs mount point next to procRoot, so /proc pairs with
+// /sys and a host's /host/proc with /host/sys
+func sysRoot(procRoot string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(procRoot)), "sys")
+}
+
+// readProcNetSNMP parses files in the /proc/net/snmp format, where every
+// section is a line of field names followed by a line of values, both
+// prefixed with the section name (e.g. "Tcp:" or "TcpExt:")
+func readProcNetSNMP(path string) (map[string]map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sections := make(map[string]map[string]int64)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		header := strings.Fields(scanner.Text())
+		if len(header) < 2 {
+			continue
+		}
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("%s: missing values for section %s", path, header[0])
+		}
+		values := strings.Fields(scanner.Text())
+
+		if len(values) != len(header) || values[0] != header[0] {
+			return nil, fmt.Errorf("%s: malformed section %s", path, header[0])
+		}
+
+		section := strings.TrimSuffix(header[0], ":")
+		if sections[section] == nil {
+			sections[section] = make(map[string]int64)
+		}
+		for i := 1; i < len(header); i++ {
+			value, err := parseProcValue(values[i])
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid value for %s.%s: %w", path, section, header[i], err)
+			}
+			sections[section][header[i]] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// readProcNetSNMP6 parses /proc/net/snmp6, where every line is a single
+// "Name Value" pair and the protocol is encoded as a prefix of the name
+// (e.g. "Udp6InDatagrams"). The counters are split into sections using prefixes
+func readProcNetSNMP6(path string, prefixes []string) (map[string]map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sections := make(map[string]map[string]int64)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(fields[0], prefix) {
+				continue
+			}
+
+			value, err := parseProcValue(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid value for %s: %w", path, fields[0], err)
+			}
+			if sections[prefix] == nil {
+				sections[prefix] = make(map[string]int64)
+			}
+			sections[prefix][strings.TrimPrefix(fields[0], prefix)] = value
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// parseProcValue parses a procfs counter, which may be signed (Tcp MaxConn is -1)
+// or an unsigned 64-bit value beyond the range of int64
+func parseProcValue(s string) (int64, error) {
+	if value, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return value, nil
+	}
+
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value), nil
+}
@@ -0,0 +1,113 @@
+package collectors
+
+import (
+	"math"
+	"math/bits"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// Widths of the kernel counters passed to observe. Counters kept as unsigned
+// long are as wide as the machine word.
+const (
+	counter32     = 32
+	counter64     = 64
+	counterNative = bits.UintSize
+)
+
+// counterWrapLimit is the largest increase still attributed to a 32-bit counter
+// wrapping rather than being reset; larger apparent increases are treated as resets
+const counterWrapLimit = math.MaxUint32 / 2
+
+// counterSample is the previous reading of a counter series
+type counterSample struct {
+	value      uint64
+	timestamp  time.Time
+	generation uint64
+}
+
+// counterRates turns successive readings of monotonically increasing counters
+// into increases and per-second rates. Readings of a 32-bit counter that go
+// backwards are recognised as either a wrap or a reset (interface reset,
+// driver reload, module unload); a 64-bit counter going backwards is always a
+// reset. Series that disappear start from a fresh baseline when they come back.
+type counterRates struct {
+	samples    map[string]counterSample
+	generation uint64
+	resets     uint64
+}
+
+// newCounterRates creates an empty set of counter series
+func newCounterRates() *counterRates {
+	return &counterRates{
+		samples: make(map[string]counterSample),
+	}
+}
+
+// observe records a reading of a counter of the given width in bits and
+// returns the increase and per-second rate since the previous reading of the
+// same series. ok is false for the first reading, after a reset and when no
+// time has passed.
+func (cr *counterRates) observe(key string, value uint64, width int, timestamp time.Time) (uint64, float64, bool) {
+	last, exists := cr.samples[key]
+	cr.samples[key] = counterSample{
+		value:      value,
+		timestamp:  timestamp,
+		generation: cr.generation,
+	}
+
+	if !exists {
+		return 0, 0, false
+	}
+
+	elapsed := timestamp.Sub(last.timestamp).Seconds()
+	if elapsed <= 0 {
+		// Keep the older baseline so the next reading spans the full interval,
+		// marked as seen so the next sweep does not drop it
+		last.generation = cr.generation
+		cr.samples[key] = last
+		return 0, 0, false
+	}
+
+	var delta uint64
+	switch {
+	case value >= last.value:
+		delta = value - last.value
+	case width == counter32 && last.value <= math.MaxUint32 && math.MaxUint32-last.value+value+1 <= counterWrapLimit:
+		delta = math.MaxUint32 - last.value + value + 1
+	default:
+		cr.resets++
+		return 0, 0, false
+	}
+
+	return delta, float64(delta) / elapsed, true
+}
+
+// sweep forgets series that were not observed since the previous sweep, so a
+// series that disappears and later reappears is not compared against stale values
+func (cr *counterRates) sweep() {
+	for key, sample := range cr.samples {
+		if sample.generation != cr.generation {
+			delete(cr.samples, key)
+		}
+	}
+	cr.generation++
+}
+
+// resetCount returns the number of counter resets detected so far
+func (cr *counterRates) resetCount() uint64 {
+	return cr.resets
+}
+
+// resetMetric reports the number of counter resets detected so far under the given name
+func (cr *counterRates) resetMetric(name string, timestamp time.Time) metrics.Metric {
+	return metrics.Metric{
+		Name:      name,
+		Value:     float64(cr.resetCount()),
+		Unit:      "count",
+		Timestamp: timestamp,
+		Tags:      map[string]string{},
+		Type:      metrics.MetricTypeCounter,
+	}
+}
@@ -0,0 +1,231 @@
+package collectors
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCounterRatesObserve(t *testing.T) {
+	type reading struct {
+		value     uint64
+		elapsed   time.Duration // since the previous reading
+		wantDelta uint64
+		wantRate  float64
+		wantOK    bool
+	}
+
+	tests := []struct {
+		name       string
+		width      int // counter32 unless set
+		readings   []reading
+		wantResets uint64
+	}{
+		{
+			name: "first reading has no rate",
+			readings: []reading{
+				{value: 100},
+			},
+		},
+		{
+			name: "steady increase",
+			readings: []reading{
+				{value: 100},
+				{value: 200, elapsed: 10 * time.Second, wantDelta: 100, wantRate: 10, wantOK: true},
+				{value: 500, elapsed: 10 * time.Second, wantDelta: 300, wantRate: 30, wantOK: true},
+			},
+		},
+		{
+			name: "unchanged counter",
+			readings: []reading{
+				{value: 42},
+				{value: 42, elapsed: 5 * time.Second, wantDelta: 0, wantRate: 0, wantOK: true},
+			},
+		},
+		{
+			name: "32-bit wrap",
+			readings: []reading{
+				{value: math.MaxUint32 - 99},
+				{value: 100, elapsed: 10 * time.Second, wantDelta: 200, wantRate: 20, wantOK: true},
+				{value: 300, elapsed: 10 * time.Second, wantDelta: 200, wantRate: 20, wantOK: true},
+			},
+		},
+		{
+			name: "wrap exactly to zero",
+			readings: []reading{
+				{value: math.MaxUint32},
+				{value: 0, elapsed: time.Second, wantDelta: 1, wantRate: 1, wantOK: true},
+			},
+		},
+		{
+			name: "reset of a small counter",
+			readings: []reading{
+				{value: 5000},
+				{value: 10, elapsed: 10 * time.Second},
+				{value: 110, elapsed: 10 * time.Second, wantDelta: 100, wantRate: 10, wantOK: true},
+			},
+			wantResets: 1,
+		},
+		{
+			name: "reset of a 64-bit counter",
+			readings: []reading{
+				{value: 1 << 40},
+				{value: 1000, elapsed: 10 * time.Second},
+			},
+			wantResets: 1,
+		},
+		{
+			name:  "64-bit counter decrease is a reset, not a wrap",
+			width: counter64,
+			readings: []reading{
+				{value: math.MaxUint32 - 99},
+				{value: 100, elapsed: 10 * time.Second},
+				{value: 300, elapsed: 10 * time.Second, wantDelta: 200, wantRate: 20, wantOK: true},
+			},
+			wantResets: 1,
+		},
+		{
+			name:  "64-bit counter passing the 32-bit limit",
+			width: counter64,
+			readings: []reading{
+				{value: math.MaxUint32 - 99},
+				{value: math.MaxUint32 + 101, elapsed: 10 * time.Second, wantDelta: 200, wantRate: 20, wantOK: true},
+			},
+		},
+		{
+			name: "32-bit sized decrease too large to be a wrap",
+			readings: []reading{
+				{value: math.MaxUint32 / 4},
+				{value: math.MaxUint32 / 8, elapsed: 10 * time.Second},
+			},
+			wantResets: 1,
+		},
+		{
+			name: "repeated resets",
+			readings: []reading{
+				{value: 1000},
+				{value: 0, elapsed: time.Second},
+				{value: 500, elapsed: time.Second, wantDelta: 500, wantRate: 500, wantOK: true},
+				{value: 0, elapsed: time.Second},
+			},
+			wantResets: 2,
+		},
+		{
+			name: "no time elapsed keeps the baseline",
+			readings: []reading{
+				{value: 100},
+				{value: 150},
+				{value: 300, elapsed: 10 * time.Second, wantDelta: 200, wantRate: 20, wantOK: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width := tt.width
+			if width == 0 {
+				width = counter32
+			}
+			rates := newCounterRates()
+			timestamp := time.Unix(1700000000, 0)
+
+			for i, r := range tt.readings {
+				timestamp = timestamp.Add(r.elapsed)
+				delta, rate, ok := rates.observe("series", r.value, width, timestamp)
+				if ok != r.wantOK || delta != r.wantDelta || rate != r.wantRate {
+					t.Errorf("reading %d (%d): got delta=%d rate=%v ok=%v, want delta=%d rate=%v ok=%v",
+						i, r.value, delta, rate, ok, r.wantDelta, r.wantRate, r.wantOK)
+				}
+			}
+
+			if got := rates.resetCount(); got != tt.wantResets {
+				t.Errorf("resetCount() = %d, want %d", got, tt.wantResets)
+			}
+		})
+	}
+}
+
+func TestCounterRatesSweep(t *testing.T) {
+	tests := []struct {
+		name string
+		// present lists, per collection, whether the series was observed
+		present []bool
+		values  []uint64
+		wantOK  []bool
+	}{
+		{
+			name:    "always present",
+			present: []bool{true, true, true},
+			values:  []uint64{100, 200, 300},
+			wantOK:  []bool{false, true, true},
+		},
+		{
+			name:    "disappears and reappears with higher counters",
+			present: []bool{true, false, true, true},
+			values:  []uint64{100, 0, 5000, 5100},
+			wantOK:  []bool{false, false, false, true},
+		},
+		{
+			name:    "disappears and reappears with lower counters",
+			present: []bool{true, true, false, true, true},
+			values:  []uint64{100, 200, 0, 10, 20},
+			wantOK:  []bool{false, true, false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates := newCounterRates()
+			timestamp := time.Unix(1700000000, 0)
+
+			for i, present := range tt.present {
+				timestamp = timestamp.Add(10 * time.Second)
+				// Another series keeps every collection busy
+				rates.observe("other", uint64(i), counter32, timestamp)
+
+				if present {
+					if _, _, ok := rates.observe("series", tt.values[i], counter32, timestamp); ok != tt.wantOK[i] {
+						t.Errorf("collection %d: ok = %v, want %v", i, ok, tt.wantOK[i])
+					}
+				}
+				rates.sweep()
+			}
+
+			// A reappearing series is a new baseline, not a reset
+			if got := rates.resetCount(); got != 0 {
+				t.Errorf("resetCount() = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestCounterRatesSweepKeepsRestoredBaseline(t *testing.T) {
+	rates := newCounterRates()
+	timestamp := time.Unix(1700000000, 0)
+
+	rates.observe("series", 100, counter32, timestamp)
+	rates.sweep()
+
+	// A second reading at the same time keeps the first as the baseline,
+	// which must survive the next sweep
+	rates.observe("series", 150, counter32, timestamp)
+	rates.sweep()
+
+	delta, _, ok := rates.observe("series", 300, counter32, timestamp.Add(10*time.Second))
+	if !ok || delta != 200 {
+		t.Errorf("observe() after sweep = delta %d ok %v, want delta 200 ok true", delta, ok)
+	}
+}
+
+func TestCounterRatesResetMetric(t *testing.T) {
+	rates := newCounterRates()
+	timestamp := time.Unix(1700000000, 0)
+
+	rates.observe("a", 100, counter64, timestamp)
+	rates.observe("a", 1, counter64, timestamp.Add(time.Second))
+
+	metric := rates.resetMetric("test_counter_resets_total", timestamp)
+	if metric.Name != "test_counter_resets_total" || metric.Value != 1 {
+		t.Errorf("resetMetric() = %s %v, want test_counter_resets_total 1", metric.Name, metric.Value)
+	}
+}
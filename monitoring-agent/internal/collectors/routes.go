@@ -0,0 +1,444 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// errRouteNetlinkUnsupported is returned where rtnetlink is not available
+var errRouteNetlinkUnsupported = errors.New("routing table access is not supported on this platform")
+
+// routeRefreshInterval limits how often route notifications trigger a full table dump
+const routeRefreshInterval = time.Second
+
+// routeTableNames maps the reserved routing table IDs from linux/rtnetlink.h
+var routeTableNames = map[uint32]string{
+	253: "default",
+	254: "main",
+	255: "local",
+}
+
+// routeProtocolNames maps common route origins from linux/rtnetlink.h and /etc/iproute2/rt_protos
+var routeProtocolNames = map[uint8]string{
+	1:   "redirect",
+	2:   "kernel",
+	3:   "boot",
+	4:   "static",
+	9:   "ra",
+	16:  "dhcp",
+	18:  "keepalived",
+	42:  "babel",
+	186: "bgp",
+	187: "isis",
+	188: "ospf",
+	189: "rip",
+}
+
+// neighborReachableStates are neighbor states in which the neighbor answered recently or needs no resolution
+var neighborReachableStates = map[string]bool{
+	"reachable": true,
+	"stale":     true,
+	"delay":     true,
+	"probe":     true,
+	"permanent": true,
+	"noarp":     true,
+}
+
+// routeEntry is a single route, or a single next hop of a multipath route
+type routeEntry struct {
+	family      string
+	table       string
+	destination string
+	gateway     string
+	iface       string
+	protocol    string
+	priority    uint32
+}
+
+// neighborEntry is an ARP or NDP cache entry
+type neighborEntry struct {
+	family  string
+	address string
+	iface   string
+	state   string
+}
+
+// routingState is a snapshot of the routing policy database, routes and neighbors
+type routingState struct {
+	routes    []routeEntry
+	rules     map[string]int // rule count by family
+	neighbors []neighborEntry
+}
+
+// RoutesCollector collects routing table, policy rule and neighbor table
+// metrics, default gateway reachability, and reports route changes
+type RoutesCollector struct {
+	interval    time.Duration
+	config      metrics.RoutesConfig
+	lastRoutes  map[routeEntry]bool
+	lastRefresh time.Time
+	eventSink   func(metrics.Metric)
+	stateMutex  sync.Mutex
+	refresh     *time.Timer
+	stopped     bool
+	watchCancel context.CancelFunc
+	watchWg     sync.WaitGroup
+	logger      *logrus.Logger
+}
+
+// NewRoutesCollector creates a new routing and neighbor table collector
+func NewRoutesCollector(interval time.Duration, config metrics.RoutesConfig, logger *logrus.Logger) *RoutesCollector {
+	return &RoutesCollector{
+		interval:   interval,
+		config:     config,
+		lastRoutes: make(map[routeEntry]bool),
+		logger:     logger,
+	}
+}
+
+// Name returns the collector name
+func (rc *RoutesCollector) Name() string {
+	return "routes"
+}
+
+// Interval returns the collection interval
+func (rc *RoutesCollector) Interval() time.Duration {
+	return rc.interval
+}
+
+// Start initializes the collector
+func (rc *RoutesCollector) Start(ctx context.Context) error {
+	rc.logger.Info("Starting routes collector")
+
+	// Initialize with first measurement
+	state, err := readRoutingState()
+	if err != nil {
+		return fmt.Errorf("failed to read initial routing table: %w", err)
+	}
+	rc.lastRoutes = routeSet(state.routes)
+	rc.lastRefresh = time.Now()
+
+	// Subscribe to route changes
+	watchCtx, cancel := context.WithCancel(ctx)
+	rc.watchCancel = cancel
+	rc.watchWg.Add(1)
+	go func() {
+		defer rc.watchWg.Done()
+		if err := watchRouteChanges(watchCtx, rc.handleRouteChange); err != nil {
+			rc.logger.WithError(err).Warn("Route change notifications unavailable, reporting changes on the collection interval")
+		}
+	}()
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (rc *RoutesCollector) Stop() error {
+	rc.logger.Info("Stopping routes collector")
+
+	if rc.watchCancel != nil {
+		rc.watchCancel()
+		rc.watchWg.Wait()
+	}
+
+	rc.stateMutex.Lock()
+	defer rc.stateMutex.Unlock()
+	if rc.refresh != nil {
+		rc.refresh.Stop()
+	}
+	rc.stopped = true
+	return nil
+}
+
+// SetEventSink sets where route change events are sent between collections
+func (rc *RoutesCollector) SetEventSink(sink func(metrics.Metric)) {
+	rc.stateMutex.Lock()
+	defer rc.stateMutex.Unlock()
+	rc.eventSink = sink
+}
+
+// handleRouteChange re-reads the routing table after a netlink notification
+// and emits the resulting change events immediately
+func (rc *RoutesCollector) handleRouteChange() {
+	rc.stateMutex.Lock()
+	defer rc.stateMutex.Unlock()
+
+	// Without a sink, leave the changes for the next collection
+	if rc.eventSink == nil || rc.stopped {
+		return
+	}
+
+	// Coalesce bursts of updates into one refresh once the interval has passed
+	if wait := routeRefreshInterval - time.Since(rc.lastRefresh); wait > 0 {
+		if rc.refresh == nil {
+			rc.refresh = time.AfterFunc(wait, func() {
+				rc.stateMutex.Lock()
+				rc.refresh = nil
+				rc.stateMutex.Unlock()
+				rc.handleRouteChange()
+			})
+		}
+		return
+	}
+
+	state, err := readRoutingState()
+	if err != nil {
+		rc.logger.WithError(err).Warn("Failed to read routing table")
+		return
+	}
+
+	for _, event := range rc.diffRoutes(state.routes, time.Now()) {
+		rc.eventSink(event)
+	}
+}
+
+// Collect gathers routing and neighbor table metrics
+func (rc *RoutesCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	rc.stateMutex.Lock()
+	state, err := readRoutingState()
+	if err != nil {
+		rc.stateMutex.Unlock()
+		return nil, fmt.Errorf("failed to read routing table: %w", err)
+	}
+	collectedMetrics := rc.diffRoutes(state.routes, currentTime)
+	rc.stateMutex.Unlock()
+
+	// Route counts per family and table
+	routeCounts := make(map[[2]string]int)
+	for _, route := range state.routes {
+		routeCounts[[2]string{route.family, route.table}]++
+	}
+	for key, count := range routeCounts {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "routes_count",
+			Value:     float64(count),
+			Unit:      "routes",
+			Timestamp: currentTime,
+			Tags: map[string]string{
+				"family": key[0],
+				"table":  key[1],
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	for family, count := range state.rules {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "routes_rules_count",
+			Value:     float64(count),
+			Unit:      "rules",
+			Timestamp: currentTime,
+			Tags: map[string]string{
+				"family": family,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	// Neighbor counts per family, interface and state
+	neighborStates := make(map[string]string, len(state.neighbors))
+	neighborCounts := make(map[[3]string]int)
+	for _, neighbor := range state.neighbors {
+		neighborStates[neighbor.iface+"|"+neighbor.address] = neighbor.state
+		neighborCounts[[3]string{neighbor.family, neighbor.iface, neighbor.state}]++
+	}
+	for key, count := range neighborCounts {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "routes_neighbors",
+			Value:     float64(count),
+			Unit:      "entries",
+			Timestamp: currentTime,
+			Tags: map[string]string{
+				"family":    key[0],
+				"interface": key[1],
+				"state":     key[2],
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	// Default gateways and whether the neighbor table shows them as reachable
+	hasDefault := map[string]bool{"ipv4": false, "ipv6": false}
+	for _, route := range state.routes {
+		if !isDefaultGateway(route) {
+			continue
+		}
+		hasDefault[route.family] = true
+
+		tags := map[string]string{
+			"family":    route.family,
+			"gateway":   route.gateway,
+			"interface": route.iface,
+		}
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "routes_default_gateway_info",
+			Value:     1,
+			Unit:      "boolean",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+
+		// Gateways without a neighbor entry have not been resolved yet
+		if neighborState, exists := neighborStates[route.iface+"|"+route.gateway]; exists {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "routes_default_gateway_reachable",
+				Value:     boolToFloat(neighborReachableStates[neighborState]),
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+	for family, present := range hasDefault {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "routes_default_route_present",
+			Value:     boolToFloat(present),
+			Unit:      "boolean",
+			Timestamp: currentTime,
+			Tags: map[string]string{
+				"family": family,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	rc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected routes metrics")
+	return collectedMetrics, nil
+}
+
+// diffRoutes compares routes against the previous snapshot, returns a
+// routes_event for each change and records the new snapshot. Callers hold stateMutex.
+func (rc *RoutesCollector) diffRoutes(routes []routeEntry, timestamp time.Time) []metrics.Metric {
+	current := routeSet(routes)
+	var events []metrics.Metric
+	suppressed := 0
+
+	event := func(kind string, route routeEntry) {
+		// Default gateway changes are always reported
+		if !isDefaultGateway(route) {
+			if len(events) >= rc.config.MaxEvents {
+				suppressed++
+				return
+			}
+			kind = "route_" + kind
+		} else {
+			kind = "default_gateway_" + kind
+		}
+
+		events = append(events, metrics.Metric{
+			Name:      "routes_event",
+			Value:     1,
+			Unit:      "event",
+			Timestamp: timestamp,
+			Tags: map[string]string{
+				"event":       kind,
+				"family":      route.family,
+				"table":       route.table,
+				"destination": route.destination,
+				"gateway":     route.gateway,
+				"interface":   route.iface,
+				"protocol":    route.protocol,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+
+		rc.logger.WithFields(logrus.Fields{
+			"event":       kind,
+			"destination": route.destination,
+			"gateway":     route.gateway,
+			"interface":   route.iface,
+		}).Debug("Route changed")
+	}
+
+	for _, route := range sortedRoutes(current) {
+		if !rc.lastRoutes[route] {
+			event("added", route)
+		}
+	}
+	for _, route := range sortedRoutes(rc.lastRoutes) {
+		if !current[route] {
+			event("removed", route)
+		}
+	}
+
+	if suppressed > 0 {
+		events = append(events, metrics.Metric{
+			Name:      "routes_event",
+			Value:     float64(suppressed),
+			Unit:      "event",
+			Timestamp: timestamp,
+			Tags: map[string]string{
+				"event": "route_changes_suppressed",
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	rc.lastRoutes = current
+	rc.lastRefresh = timestamp
+	return events
+}
+
+// isDefaultGateway reports whether a route is a default route via a gateway in the main table
+func isDefaultGateway(route routeEntry) bool {
+	return route.table == "main" && route.destination == "default" && route.gateway != ""
+}
+
+// routeSet indexes routes for comparison between snapshots
+func routeSet(routes []routeEntry) map[routeEntry]bool {
+	set := make(map[routeEntry]bool, len(routes))
+	for _, route := range routes {
+		set[route] = true
+	}
+	return set
+}
+
+// sortedRoutes orders routes so change events are reported deterministically
+func sortedRoutes(set map[routeEntry]bool) []routeEntry {
+	routes := make([]routeEntry, 0, len(set))
+	for route := range set {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.family != b.family {
+			return a.family < b.family
+		}
+		if a.table != b.table {
+			return a.table < b.table
+		}
+		if a.destination != b.destination {
+			return a.destination < b.destination
+		}
+		return a.gateway < b.gateway
+	})
+	return routes
+}
+
+// routeTableName returns the name of a reserved routing table, or its number
+func routeTableName(table uint32) string {
+	if name, exists := routeTableNames[table]; exists {
+		return name
+	}
+	return strconv.FormatUint(uint64(table), 10)
+}
+
+// routeProtocolName returns the name of a route origin, or its number
+func routeProtocolName(protocol uint8) string {
+	if name, exists := routeProtocolNames[protocol]; exists {
+		return name
+	}
+	return strconv.Itoa(int(protocol))
+}
@@ -0,0 +1,201 @@
+package collectors
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// rtnetlink message and attribute constants from linux/rtnetlink.h, linux/fib_rules.h and linux/neighbour.h
+const (
+	rtMsgSize      = 12
+	fibRuleHdrSize = 12
+	ndMsgSize      = 12
+	rtNextHopSize  = 8
+	rtaVia         = 18
+	ndaDst         = 1
+)
+
+// neighborStateNames maps NUD_* bits to the names used by iproute2
+var neighborStateNames = []struct {
+	bit  uint16
+	name string
+}{
+	{0x01, "incomplete"},
+	{0x02, "reachable"},
+	{0x04, "stale"},
+	{0x08, "delay"},
+	{0x10, "probe"},
+	{0x20, "failed"},
+	{0x40, "noarp"},
+	{0x80, "permanent"},
+}
+
+// readRoutingState dumps routes from all tables, policy rules and neighbors for IPv4 and IPv6
+func readRoutingState() (*routingState, error) {
+	interfaceNames := make(map[int]string)
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			interfaceNames[iface.Index] = iface.Name
+		}
+	}
+
+	state := &routingState{
+		rules: make(map[string]int),
+	}
+
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		familyName := "ipv4"
+		if family == syscall.AF_INET6 {
+			familyName = "ipv6"
+		}
+
+		// Routes
+		request := make([]byte, rtMsgSize)
+		request[0] = family
+		messages, err := netlinkDump(syscall.NETLINK_ROUTE, syscall.RTM_GETROUTE, request)
+		if err != nil {
+			return nil, fmt.Errorf("route dump failed: %w", err)
+		}
+		for _, message := range messages {
+			state.routes = append(state.routes, parseRouteMessage(familyName, message.Data, interfaceNames)...)
+		}
+
+		// Policy rules
+		request = make([]byte, fibRuleHdrSize)
+		request[0] = family
+		messages, err = netlinkDump(syscall.NETLINK_ROUTE, syscall.RTM_GETRULE, request)
+		if err != nil {
+			return nil, fmt.Errorf("rule dump failed: %w", err)
+		}
+		state.rules[familyName] = len(messages)
+
+		// ARP and NDP neighbors
+		request = make([]byte, ndMsgSize)
+		request[0] = family
+		messages, err = netlinkDump(syscall.NETLINK_ROUTE, syscall.RTM_GETNEIGH, request)
+		if err != nil {
+			return nil, fmt.Errorf("neighbor dump failed: %w", err)
+		}
+		for _, message := range messages {
+			if neighbor, ok := parseNeighborMessage(familyName, message.Data, interfaceNames); ok {
+				state.neighbors = append(state.neighbors, neighbor)
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// parseRouteMessage decodes an rtmsg and its attributes, returning one entry per next hop
+func parseRouteMessage(family string, data []byte, interfaceNames map[int]string) []routeEntry {
+	if len(data) < rtMsgSize {
+		return nil
+	}
+
+	// Skip cached routes, which IPv6 dumps include
+	flags := binary.LittleEndian.Uint32(data[8:12])
+	if flags&syscall.RTM_F_CLONED != 0 {
+		return nil
+	}
+
+	dstLen := data[1]
+	attrs := parseNetlinkAttrs(data[rtMsgSize:])
+
+	table := uint32(data[4])
+	if value, exists := attrs[syscall.RTA_TABLE]; exists && len(value) >= 4 {
+		table = binary.LittleEndian.Uint32(value)
+	}
+
+	route := routeEntry{
+		family:      family,
+		table:       routeTableName(table),
+		destination: "default",
+		protocol:    routeProtocolName(data[5]),
+	}
+	if value, exists := attrs[syscall.RTA_DST]; exists {
+		route.destination = fmt.Sprintf("%s/%d", net.IP(value), dstLen)
+	}
+	if value, exists := attrs[syscall.RTA_PRIORITY]; exists && len(value) >= 4 {
+		route.priority = binary.LittleEndian.Uint32(value)
+	}
+
+	// Multipath routes list their next hops in a nested attribute
+	if value, exists := attrs[syscall.RTA_MULTIPATH]; exists {
+		var routes []routeEntry
+		for len(value) >= rtNextHopSize {
+			hopLen := int(binary.LittleEndian.Uint16(value[0:2]))
+			if hopLen < rtNextHopSize || hopLen > len(value) {
+				break
+			}
+
+			hop := route
+			hop.iface = interfaceNames[int(int32(binary.LittleEndian.Uint32(value[4:8])))]
+			hop.gateway = routeGateway(parseNetlinkAttrs(value[rtNextHopSize:hopLen]))
+			routes = append(routes, hop)
+
+			next := (hopLen + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+			if next > len(value) {
+				break
+			}
+			value = value[next:]
+		}
+		return routes
+	}
+
+	if value, exists := attrs[syscall.RTA_OIF]; exists && len(value) >= 4 {
+		route.iface = interfaceNames[int(int32(binary.LittleEndian.Uint32(value)))]
+	}
+	route.gateway = routeGateway(attrs)
+
+	return []routeEntry{route}
+}
+
+// routeGateway returns the next hop address from RTA_GATEWAY, or RTA_VIA for
+// IPv4 routes through an IPv6 next hop
+func routeGateway(attrs map[uint16][]byte) string {
+	if value, exists := attrs[syscall.RTA_GATEWAY]; exists {
+		return net.IP(value).String()
+	}
+	if value, exists := attrs[rtaVia]; exists && len(value) > 2 {
+		return net.IP(value[2:]).String()
+	}
+	return ""
+}
+
+// parseNeighborMessage decodes an ndmsg and its destination address
+func parseNeighborMessage(family string, data []byte, interfaceNames map[int]string) (neighborEntry, bool) {
+	if len(data) < ndMsgSize {
+		return neighborEntry{}, false
+	}
+
+	attrs := parseNetlinkAttrs(data[ndMsgSize:])
+	address, exists := attrs[ndaDst]
+	if !exists {
+		return neighborEntry{}, false
+	}
+
+	neighbor := neighborEntry{
+		family:  family,
+		address: net.IP(address).String(),
+		iface:   interfaceNames[int(int32(binary.LittleEndian.Uint32(data[4:8])))],
+		state:   "none",
+	}
+
+	state := binary.LittleEndian.Uint16(data[8:10])
+	for _, s := range neighborStateNames {
+		if state&s.bit != 0 {
+			neighbor.state = s.name
+			break
+		}
+	}
+
+	return neighbor, true
+}
+
+// watchRouteChanges calls notify whenever an IPv4 or IPv6 route changes
+func watchRouteChanges(ctx context.Context, notify func()) error {
+	return watchRtnetlink(ctx, rtmgrpIPv4Route|rtmgrpIPv6Route, notify)
+}
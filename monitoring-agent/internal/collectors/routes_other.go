@@ -0,0 +1,15 @@
+//go:build !linux
+
+package collectors
+
+import "context"
+
+// readRoutingState is only implemented on Linux
+func readRoutingState() (*routingState, error) {
+	return nil, errRouteNetlinkUnsupported
+}
+
+// watchRouteChanges is only implemented on Linux
+func watchRouteChanges(ctx context.Context, notify func()) error {
+	return errRouteNetlinkUnsupported
+}
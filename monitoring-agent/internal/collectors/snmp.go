@@ -0,0 +1,224 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BER tags used by SNMP (RFC 3416)
+const (
+	berInteger        = 0x02
+	berOctetString    = 0x04
+	berNull           = 0x05
+	berObjectID       = 0x06
+	berSequence       = 0x30
+	berTimeTicks      = 0x43
+	snmpGetRequest    = 0xa0
+	snmpGetResponse   = 0xa2
+	snmpNoSuchObject  = 0x80
+	snmpNoSuchInst    = 0x81
+	snmpEndOfMibView  = 0x82
+	snmpVersion2c     = 1
+	snmpDefaultPort   = "161"
+	snmpMaxLengthSize = 4
+)
+
+// sysUpTimeOID is SNMPv2-MIB::sysUpTime.0, the time since the agent was (re)initialized
+var sysUpTimeOID = []int{1, 3, 6, 1, 2, 1, 1, 3, 0}
+
+// errSNMPRequestID is returned for responses to a different request
+var errSNMPRequestID = errors.New("SNMP request ID mismatch")
+
+// snmpValue is the undecoded value of a variable binding
+type snmpValue struct {
+	tag     byte
+	content []byte
+}
+
+// snmpGet builds an SNMPv2c GetRequest for a single object
+func snmpGet(community string, requestID int32, oid []int) []byte {
+	varbind := berEncode(berSequence, append(berEncodeOID(oid), berNull, 0))
+	pdu := berEncode(snmpGetRequest, concatBytes(
+		berEncodeInteger(int64(requestID)),
+		berEncodeInteger(0), // error-status
+		berEncodeInteger(0), // error-index
+		berEncode(berSequence, varbind),
+	))
+
+	return berEncode(berSequence, concatBytes(
+		berEncodeInteger(snmpVersion2c),
+		berEncode(berOctetString, []byte(community)),
+		pdu,
+	))
+}
+
+// parseSNMPResponse decodes a GetResponse and returns the value of its first variable binding
+func parseSNMPResponse(data []byte, requestID int32) (*snmpValue, error) {
+	tag, message, _, err := berDecode(data)
+	if err != nil || tag != berSequence {
+		return nil, fmt.Errorf("malformed SNMP message")
+	}
+
+	// version, community, PDU
+	fields, err := berDecodeAll(message)
+	if err != nil || len(fields) != 3 {
+		return nil, fmt.Errorf("malformed SNMP message")
+	}
+	if fields[2].tag != snmpGetResponse {
+		return nil, fmt.Errorf("unexpected SNMP PDU type 0x%02x", fields[2].tag)
+	}
+
+	// request-id, error-status, error-index, variable-bindings
+	pdu, err := berDecodeAll(fields[2].content)
+	if err != nil || len(pdu) != 4 || pdu[3].tag != berSequence {
+		return nil, fmt.Errorf("malformed SNMP PDU")
+	}
+	if id, err := berDecodeInteger(pdu[0].content); err != nil || id != int64(requestID) {
+		return nil, errSNMPRequestID
+	}
+	if status, err := berDecodeInteger(pdu[1].content); err != nil || status != 0 {
+		return nil, fmt.Errorf("SNMP agent returned error status %d", status)
+	}
+
+	varbinds, err := berDecodeAll(pdu[3].content)
+	if err != nil || len(varbinds) == 0 {
+		return nil, fmt.Errorf("SNMP response has no variable bindings")
+	}
+	varbind, err := berDecodeAll(varbinds[0].content)
+	if err != nil || len(varbind) != 2 {
+		return nil, fmt.Errorf("malformed SNMP variable binding")
+	}
+
+	value := varbind[1]
+	switch value.tag {
+	case snmpNoSuchObject:
+		return nil, fmt.Errorf("SNMP agent has no such object")
+	case snmpNoSuchInst:
+		return nil, fmt.Errorf("SNMP agent has no such instance")
+	case snmpEndOfMibView:
+		return nil, fmt.Errorf("SNMP agent reached the end of the MIB view")
+	}
+
+	return &value, nil
+}
+
+// snmpMatcher ignores responses to other requests, e.g. late replies to an earlier collection
+func snmpMatcher(requestID int32) func(request, response []byte) bool {
+	return func(request, response []byte) bool {
+		_, err := parseSNMPResponse(response, requestID)
+		return !errors.Is(err, errSNMPRequestID)
+	}
+}
+
+// berEncode wraps content in a tag and definite length
+func berEncode(tag byte, content []byte) []byte {
+	length := len(content)
+	if length < 0x80 {
+		return concatBytes([]byte{tag, byte(length)}, content)
+	}
+
+	var lengthBytes []byte
+	for l := length; l > 0; l >>= 8 {
+		lengthBytes = append([]byte{byte(l)}, lengthBytes...)
+	}
+	return concatBytes([]byte{tag, 0x80 | byte(len(lengthBytes))}, lengthBytes, content)
+}
+
+// berEncodeInteger encodes a two's complement integer in the fewest bytes
+func berEncodeInteger(value int64) []byte {
+	content := []byte{byte(value)}
+	for value > 0x7f || value < -0x80 {
+		value >>= 8
+		content = append([]byte{byte(value)}, content...)
+	}
+	return berEncode(berInteger, content)
+}
+
+// berEncodeOID encodes an object identifier, packing the first two arcs into one
+func berEncodeOID(oid []int) []byte {
+	content := []byte{byte(oid[0]*40 + oid[1])}
+	for _, arc := range oid[2:] {
+		chunk := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			chunk = append([]byte{byte(arc&0x7f) | 0x80}, chunk...)
+		}
+		content = append(content, chunk...)
+	}
+	return berEncode(berObjectID, content)
+}
+
+// berDecode splits the first TLV off data
+func berDecode(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+
+	tag := data[0]
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > snmpMaxLengthSize || len(data) < offset+size {
+			return 0, nil, nil, errors.New("invalid BER length")
+		}
+		length = 0
+		for _, b := range data[offset : offset+size] {
+			length = length<<8 | int(b)
+		}
+		offset += size
+	}
+
+	if length < 0 || len(data)-offset < length {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// berDecodeAll decodes consecutive TLVs filling data
+func berDecodeAll(data []byte) ([]snmpValue, error) {
+	var values []snmpValue
+	for len(data) > 0 {
+		tag, content, rest, err := berDecode(data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, snmpValue{tag: tag, content: content})
+		data = rest
+	}
+	return values, nil
+}
+
+// berDecodeInteger decodes a two's complement integer
+func berDecodeInteger(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, errors.New("invalid BER integer")
+	}
+
+	value := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// berDecodeUnsigned decodes the unsigned application types (Counter32, Gauge32, TimeTicks)
+func berDecodeUnsigned(content []byte) (uint64, error) {
+	if len(content) == 0 || len(content) > 9 {
+		return 0, errors.New("invalid BER unsigned integer")
+	}
+
+	var value uint64
+	for _, b := range content {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// concatBytes joins byte slices into a new slice
+func concatBytes(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
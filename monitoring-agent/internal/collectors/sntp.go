@@ -0,0 +1,104 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// RFC 4330 SNTP packet layout
+const (
+	sntpPacketSize  = 48
+	sntpDefaultPort = "123"
+	sntpVersion     = 4
+	sntpModeClient  = 3
+	sntpModeServer  = 4
+	sntpLeapAlarm   = 3
+)
+
+// sntpResult holds the server state and clock comparison from one SNTP exchange
+type sntpResult struct {
+	stratum        uint8
+	leap           uint8
+	referenceID    string
+	rootDelay      time.Duration
+	rootDispersion time.Duration
+	offset         time.Duration // Server clock minus local clock
+	delay          time.Duration // Round-trip network delay excluding server processing
+}
+
+// sntpRequest builds a client request carrying the send time as its transmit timestamp
+func sntpRequest(sentAt time.Time) []byte {
+	buf := make([]byte, sntpPacketSize)
+	buf[0] = sntpVersion<<3 | sntpModeClient
+	binary.BigEndian.PutUint64(buf[40:48], toNTPTime(sentAt))
+	return buf
+}
+
+// sntpMatches reports whether a response answers the given request by
+// comparing its origin timestamp with the request's transmit timestamp
+func sntpMatches(request, response []byte) bool {
+	return len(response) >= sntpPacketSize && bytes.Equal(response[24:32], request[40:48])
+}
+
+// parseSNTPResponse decodes a server response and computes the clock offset
+// and round-trip delay from the four timestamps of the exchange
+func parseSNTPResponse(buf []byte, sentAt, receivedAt time.Time) (*sntpResult, error) {
+	if len(buf) < sntpPacketSize {
+		return nil, fmt.Errorf("SNTP response too short: %d bytes", len(buf))
+	}
+
+	leap := buf[0] >> 6
+	mode := buf[0] & 0x7
+	if mode != sntpModeServer {
+		return nil, fmt.Errorf("unexpected SNTP mode %d", mode)
+	}
+
+	stratum := buf[1]
+	if stratum == 0 {
+		// Kiss-o'-Death, e.g. RATE or DENY
+		return nil, fmt.Errorf("SNTP server sent kiss code %q", string(bytes.TrimRight(buf[12:16], "\x00")))
+	}
+	if leap == sntpLeapAlarm {
+		return nil, fmt.Errorf("SNTP server clock is not synchronized")
+	}
+
+	referenceID := net.IP(buf[12:16]).String()
+	if stratum == 1 {
+		referenceID = string(bytes.TrimRight(buf[12:16], "\x00"))
+	}
+
+	receiveTime := fromNTPTime(binary.BigEndian.Uint64(buf[32:40]))
+	transmitTime := fromNTPTime(binary.BigEndian.Uint64(buf[40:48]))
+
+	return &sntpResult{
+		stratum:        stratum,
+		leap:           leap,
+		referenceID:    referenceID,
+		rootDelay:      ntpShortDuration(binary.BigEndian.Uint32(buf[4:8])),
+		rootDispersion: ntpShortDuration(binary.BigEndian.Uint32(buf[8:12])),
+		offset:         (receiveTime.Sub(sentAt) + transmitTime.Sub(receivedAt)) / 2,
+		delay:          receivedAt.Sub(sentAt) - transmitTime.Sub(receiveTime),
+	}, nil
+}
+
+// sntpQuery asks an NTP server for its time, resending after each timeout up to retries times
+func sntpQuery(ctx context.Context, address string, timeout time.Duration, retries int) (*sntpResult, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, sntpDefaultPort)
+	}
+
+	response, err := udpExchange(ctx, address, timeout, retries, sntpRequest, sntpMatches)
+	if err != nil {
+		return nil, err
+	}
+	return parseSNTPResponse(response.data, response.sentAt, response.receivedAt)
+}
+
+// ntpShortDuration converts the 32-bit NTP short format (16-bit seconds, 16-bit fraction)
+func ntpShortDuration(value uint32) time.Duration {
+	return time.Duration(uint64(value) * uint64(time.Second) >> 16)
+}
@@ -0,0 +1,361 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// softnetColumns maps /proc/net/softnet_stat columns to metric names
+var softnetColumns = []struct {
+	column int
+	name   string
+}{
+	{0, "softnet_processed"},
+	{1, "softnet_dropped"},
+	{2, "softnet_time_squeeze"},
+	{8, "softnet_cpu_collision"},
+	{9, "softnet_received_rps"},
+	{10, "softnet_flow_limit"},
+}
+
+const (
+	softnetBacklogColumn = 11
+	softnetIndexColumn   = 12
+)
+
+// ethtoolQueuePatterns recognise per-queue statistic names used by common drivers,
+// e.g. "rx_queue_0_packets" (virtio_net, ixgbe), "rx0_bytes" (mlx5), "tx-0.tx_packets" (i40e)
+// and "queue_0_tx_cnt" (ena)
+var ethtoolQueuePatterns = []struct {
+	pattern   *regexp.Regexp
+	direction int
+	queue     int
+	stat      int
+}{
+	{regexp.MustCompile(`^(rx|tx)[_-]?(?:queue[_-]?)?(\d+)[_.](.+)$`), 1, 2, 3},
+	{regexp.MustCompile(`^queue_(\d+)_(rx|tx)_(.+)$`), 2, 1, 3},
+}
+
+// errEthtoolUnsupported is returned where the ethtool ioctl interface is not available
+var errEthtoolUnsupported = errors.New("ethtool is not supported on this platform")
+
+// ethtoolInfo holds the driver details of a network interface
+type ethtoolInfo struct {
+	driver string
+	ring   *ethtoolRing
+	stats  map[string]uint64
+}
+
+// ethtoolRing holds the current and maximum RX/TX ring buffer sizes
+type ethtoolRing struct {
+	rxPending uint32
+	rxMax     uint32
+	txPending uint32
+	txMax     uint32
+}
+
+// SoftnetCollector collects kernel packet processing statistics per CPU from
+// /proc/net/softnet_stat and NIC queue, ring buffer and driver statistics via ethtool
+type SoftnetCollector struct {
+	interval      time.Duration
+	config        metrics.SoftnetConfig
+	procRoot      string
+	sysRoot       string
+	rates         *counterRates
+	ethtoolWarned map[string]bool
+	logger        *logrus.Logger
+}
+
+// NewSoftnetCollector creates a new softnet and NIC queue statistics collector
+func NewSoftnetCollector(interval time.Duration, config metrics.SoftnetConfig, procRoot string, logger *logrus.Logger) *SoftnetCollector {
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+
+	return &SoftnetCollector{
+		interval:      interval,
+		config:        config,
+		procRoot:      procRoot,
+		sysRoot:       sysRoot(procRoot),
+		rates:         newCounterRates(),
+		ethtoolWarned: make(map[string]bool),
+		logger:        logger,
+	}
+}
+
+// Name returns the collector name
+func (sc *SoftnetCollector) Name() string {
+	return "softnet"
+}
+
+// Interval returns the collection interval
+func (sc *SoftnetCollector) Interval() time.Duration {
+	return sc.interval
+}
+
+// Start initializes the collector
+func (sc *SoftnetCollector) Start(ctx context.Context) error {
+	sc.logger.WithField("interfaces", sc.config.Interfaces).Info("Starting softnet collector")
+
+	// Initialize with first measurement
+	if _, err := sc.collectSoftnet(time.Now()); err != nil {
+		return fmt.Errorf("softnet statistics not available: %w", err)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (sc *SoftnetCollector) Stop() error {
+	sc.logger.Info("Stopping softnet collector")
+	return nil
+}
+
+// Collect gathers per-CPU softnet and per-interface NIC statistics
+func (sc *SoftnetCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	collectedMetrics, err := sc.collectSoftnet(currentTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read softnet statistics: %w", err)
+	}
+
+	interfaces, err := sc.interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces: %w", err)
+	}
+	for _, iface := range interfaces {
+		collectedMetrics = append(collectedMetrics, sc.collectInterface(iface, currentTime)...)
+	}
+
+	sc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected softnet metrics")
+	return collectedMetrics, nil
+}
+
+// collectSoftnet parses /proc/net/softnet_stat, one line of hexadecimal columns per CPU
+func (sc *SoftnetCollector) collectSoftnet(timestamp time.Time) ([]metrics.Metric, error) {
+	file, err := os.Open(procPath(sc.procRoot, "net", "softnet_stat"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var collectedMetrics []metrics.Metric
+	scanner := bufio.NewScanner(file)
+
+	for line := 0; scanner.Scan(); line++ {
+		columns := strings.Fields(scanner.Text())
+		values := make([]uint64, len(columns))
+		for i, column := range columns {
+			value, err := strconv.ParseUint(column, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid softnet value %q: %w", column, err)
+			}
+			values[i] = value
+		}
+
+		// Lines only exist for online CPUs; newer kernels report the CPU index
+		cpu := line
+		if len(values) > softnetIndexColumn {
+			cpu = int(values[softnetIndexColumn])
+		}
+		tags := map[string]string{
+			"cpu": strconv.Itoa(cpu),
+		}
+
+		for _, column := range softnetColumns {
+			if column.column >= len(values) {
+				continue
+			}
+			value := values[column.column]
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      column.name + "_total",
+				Value:     float64(value),
+				Unit:      "packets",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeCounter,
+			})
+
+			// The kernel keeps these as 32-bit counters, so they wrap on busy hosts
+			if _, rate, ok := sc.rates.observe(tags["cpu"]+"|"+column.name, value, counter32, timestamp); ok {
+				collectedMetrics = append(collectedMetrics, metrics.Metric{
+					Name:      column.name + "_per_sec",
+					Value:     rate,
+					Unit:      "packets/sec",
+					Timestamp: timestamp,
+					Tags:      tags,
+					Type:      metrics.MetricTypeGauge,
+				})
+			}
+		}
+
+		if len(values) > softnetBacklogColumn {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "softnet_backlog_length",
+				Value:     float64(values[softnetBacklogColumn]),
+				Unit:      "packets",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// CPUs that went offline start from a fresh baseline if they return
+	sc.rates.sweep()
+	collectedMetrics = append(collectedMetrics, sc.rates.resetMetric("softnet_counter_resets_total", timestamp))
+
+	return collectedMetrics, nil
+}
+
+// interfaces returns the configured interfaces, or every non-loopback interface
+func (sc *SoftnetCollector) interfaces() ([]string, error) {
+	if len(sc.config.Interfaces) > 0 {
+		return sc.config.Interfaces, nil
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		names = append(names, iface.Name)
+	}
+	return names, nil
+}
+
+// collectInterface reports queue counts from sysfs and ring sizes, per-queue
+// and driver statistics from ethtool
+func (sc *SoftnetCollector) collectInterface(iface string, timestamp time.Time) []metrics.Metric {
+	var collectedMetrics []metrics.Metric
+	tags := map[string]string{
+		"interface": iface,
+	}
+
+	// Queue counts
+	for _, direction := range []string{"rx", "tx"} {
+		queues, err := filepath.Glob(filepath.Join(sc.sysRoot, "class", "net", iface, "queues", direction+"-*"))
+		if err != nil || len(queues) == 0 {
+			continue
+		}
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "softnet_" + direction + "_queues",
+			Value:     float64(len(queues)),
+			Unit:      "queues",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+	}
+
+	info, err := readEthtool(iface)
+	if err != nil {
+		// Virtual interfaces commonly lack ethtool support; only mention it once
+		if !sc.ethtoolWarned[iface] {
+			sc.logger.WithFields(logrus.Fields{
+				"interface": iface,
+				"error":     err,
+			}).Debug("ethtool statistics unavailable")
+			sc.ethtoolWarned[iface] = true
+		}
+		return collectedMetrics
+	}
+
+	if info.ring != nil {
+		for _, ring := range []struct {
+			name  string
+			value uint32
+		}{
+			{"softnet_ring_rx_pending", info.ring.rxPending},
+			{"softnet_ring_rx_max", info.ring.rxMax},
+			{"softnet_ring_tx_pending", info.ring.txPending},
+			{"softnet_ring_tx_max", info.ring.txMax},
+		} {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      ring.name,
+				Value:     float64(ring.value),
+				Unit:      "descriptors",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+
+	for name, value := range info.stats {
+		if direction, queue, stat, ok := parseEthtoolQueueStat(name); ok {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "softnet_queue_stat",
+				Value:     float64(value),
+				Unit:      "count",
+				Timestamp: timestamp,
+				Tags: map[string]string{
+					"interface": iface,
+					"driver":    info.driver,
+					"direction": direction,
+					"queue":     queue,
+					"stat":      stat,
+				},
+				Type: metrics.MetricTypeCounter,
+			})
+			continue
+		}
+
+		if sc.config.DriverStats {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "softnet_driver_stat",
+				Value:     float64(value),
+				Unit:      "count",
+				Timestamp: timestamp,
+				Tags: map[string]string{
+					"interface": iface,
+					"driver":    info.driver,
+					"stat":      name,
+				},
+				Type: metrics.MetricTypeCounter,
+			})
+		}
+	}
+
+	return collectedMetrics
+}
+
+// parseEthtoolQueueStat splits a per-queue ethtool statistic name into its
+// direction, queue number and statistic
+func parseEthtoolQueueStat(name string) (string, string, string, bool) {
+	for _, p := range ethtoolQueuePatterns {
+		matches := p.pattern.FindStringSubmatch(name)
+		if matches == nil {
+			continue
+		}
+
+		direction := matches[p.direction]
+		// Drop a repeated direction prefix, e.g. "tx-0.tx_packets"
+		stat := strings.TrimPrefix(matches[p.stat], direction+"_")
+		return direction, matches[p.queue], stat, true
+	}
+
+	return "", "", "", false
+}
@@ -0,0 +1,82 @@
+package collectors
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSysRoot(t *testing.T) {
+	tests := []struct {
+		procRoot string
+		want     string
+	}{
+		{"/proc", "/sys"},
+		{"/proc/", "/sys"},
+		{"/host/proc", "/host/sys"},
+		{"testdata/proc", "testdata/sys"},
+	}
+
+	for _, tt := range tests {
+		if got := sysRoot(tt.procRoot); got != tt.want {
+			t.Errorf("sysRoot(%q) = %q, want %q", tt.procRoot, got, tt.want)
+		}
+	}
+}
+
+func TestSoftnetCollect(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	collector := NewSoftnetCollector(0, metrics.SoftnetConfig{Interfaces: []string{"fixture0"}}, "testdata/proc", logger)
+	collected, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	got := make(map[string]float64)
+	for _, metric := range collected {
+		key := metric.Name
+		if cpu, ok := metric.Tags["cpu"]; ok {
+			key += "{cpu=" + cpu + "}"
+		}
+		if iface, ok := metric.Tags["interface"]; ok {
+			key += "{interface=" + iface + "}"
+		}
+		got[key] = metric.Value
+	}
+
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"softnet_processed_total{cpu=0}", 0x4cb6},
+		{"softnet_dropped_total{cpu=0}", 2},
+		{"softnet_time_squeeze_total{cpu=0}", 1},
+		{"softnet_backlog_length{cpu=0}", 0},
+		// The second line reports its CPU index in the last column
+		{"softnet_processed_total{cpu=2}", 0xa000},
+		{"softnet_received_rps_total{cpu=2}", 5},
+		{"softnet_backlog_length{cpu=2}", 1},
+		{"softnet_rx_queues{interface=fixture0}", 2},
+		{"softnet_tx_queues{interface=fixture0}", 1},
+	}
+
+	for _, tt := range tests {
+		value, ok := got[tt.key]
+		if !ok {
+			t.Errorf("metric %s missing", tt.key)
+			continue
+		}
+		if value != tt.want {
+			t.Errorf("metric %s = %v, want %v", tt.key, value, tt.want)
+		}
+	}
+
+	if _, ok := got["softnet_processed_per_sec{cpu=0}"]; ok {
+		t.Error("first collection reported a rate")
+	}
+}
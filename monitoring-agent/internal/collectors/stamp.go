@@ -0,0 +1,430 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// STAMPCollector acts as an RFC 8762 STAMP session-sender and measures
+// round-trip and one-way delay, loss and reordering against session-reflectors
+type STAMPCollector struct {
+	interval time.Duration
+	targets  []string
+	config   metrics.STAMPConfig
+	logger   *logrus.Logger
+}
+
+// stampReply is a reflected packet together with its local receive time
+type stampReply struct {
+	packet     *stampReflectorPacket
+	receivedAt time.Time
+}
+
+// STAMPResults holds the results of a STAMP test session
+type STAMPResults struct {
+	sent            int
+	received        int
+	duplicates      int
+	reordered       int
+	rtts            []float64
+	forwardDelays   []float64
+	backwardDelays  []float64
+	reflectorSynced bool
+}
+
+// NewSTAMPCollector creates a new STAMP session-sender collector
+func NewSTAMPCollector(interval time.Duration, targets []string, config metrics.STAMPConfig, logger *logrus.Logger) *STAMPCollector {
+	return &STAMPCollector{
+		interval: interval,
+		targets:  targets,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (sc *STAMPCollector) Name() string {
+	return "stamp"
+}
+
+// Interval returns the collection interval
+func (sc *STAMPCollector) Interval() time.Duration {
+	return sc.interval
+}
+
+// Start initializes the collector
+func (sc *STAMPCollector) Start(ctx context.Context) error {
+	sc.logger.WithField("targets", sc.targets).Info("Starting STAMP collector")
+
+	if len(sc.targets) == 0 {
+		return fmt.Errorf("no STAMP targets configured")
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (sc *STAMPCollector) Stop() error {
+	sc.logger.Info("Stopping STAMP collector")
+	return nil
+}
+
+// Collect runs a STAMP test session against every target
+func (sc *STAMPCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for _, target := range sc.targets {
+		tags := map[string]string{
+			"target": target,
+		}
+
+		results, targetIP, err := sc.runSession(ctx, target)
+		if err != nil {
+			sc.logger.WithFields(logrus.Fields{
+				"target": target,
+				"error":  err,
+			}).Warn("Failed to run STAMP session")
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "stamp_success",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+			continue
+		}
+
+		tags["target_ip"] = targetIP
+		collectedMetrics = append(collectedMetrics, sc.buildMetrics(results, tags, currentTime)...)
+	}
+
+	sc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected STAMP metrics")
+	return collectedMetrics, nil
+}
+
+// runSession sends a train of test packets to a reflector and gathers the reflected packets
+func (sc *STAMPCollector) runSession(ctx context.Context, target string) (*STAMPResults, string, error) {
+	address := target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		address = net.JoinHostPort(target, stampDefaultPort)
+	}
+
+	remoteAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve target %s: %w", target, err)
+	}
+
+	conn, err := net.DialUDP("udp", nil, remoteAddr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open UDP socket: %w", err)
+	}
+	defer conn.Close()
+
+	// The reader stops once the last packet has had the full timeout to come back
+	deadline := time.Now().Add(time.Duration(sc.config.PacketCount-1)*sc.config.PacketInterval + sc.config.Timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, "", fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	repliesChan := make(chan []stampReply, 1)
+	go sc.readReplies(conn, repliesChan)
+
+	errorEstimate := stampErrorEstimate(sc.config.ClockSynced)
+	sendTimes := make(map[uint32]time.Time, sc.config.PacketCount)
+
+	for seq := 0; seq < sc.config.PacketCount; seq++ {
+		if seq > 0 {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				<-repliesChan
+				return nil, "", ctx.Err()
+			case <-time.After(sc.config.PacketInterval):
+			}
+		}
+
+		sentAt := time.Now()
+		packet := &stampSenderPacket{
+			Sequence:      uint32(seq),
+			Timestamp:     sentAt,
+			ErrorEstimate: errorEstimate,
+		}
+		if _, err := conn.Write(packet.Marshal()); err != nil {
+			sc.logger.WithFields(logrus.Fields{
+				"target":   target,
+				"sequence": seq,
+				"error":    err,
+			}).Debug("Failed to send STAMP packet")
+			continue
+		}
+		sendTimes[uint32(seq)] = sentAt
+	}
+
+	replies := <-repliesChan
+	return sc.analyzeReplies(sendTimes, replies), remoteAddr.IP.String(), nil
+}
+
+// readReplies reads reflected packets until the connection deadline expires
+func (sc *STAMPCollector) readReplies(conn *net.UDPConn, repliesChan chan<- []stampReply) {
+	var replies []stampReply
+	buf := make([]byte, 1500)
+
+	for {
+		n, err := conn.Read(buf)
+		receivedAt := time.Now()
+		if err != nil {
+			break
+		}
+
+		packet, err := parseSTAMPReflectorPacket(buf[:n])
+		if err != nil {
+			sc.logger.WithError(err).Debug("Ignoring malformed STAMP reply")
+			continue
+		}
+
+		replies = append(replies, stampReply{packet: packet, receivedAt: receivedAt})
+	}
+
+	repliesChan <- replies
+}
+
+// analyzeReplies computes delay, loss and reordering statistics for a session
+func (sc *STAMPCollector) analyzeReplies(sendTimes map[uint32]time.Time, replies []stampReply) *STAMPResults {
+	results := &STAMPResults{
+		sent:            len(sendTimes),
+		reflectorSynced: true,
+	}
+
+	seen := make(map[uint32]bool, len(replies))
+	var highestSeq uint32
+
+	for _, reply := range replies {
+		packet := reply.packet
+
+		sentAt, ok := sendTimes[packet.SenderSequence]
+		if !ok {
+			// Not a packet from this session
+			continue
+		}
+		if seen[packet.SenderSequence] {
+			results.duplicates++
+			continue
+		}
+		if len(seen) > 0 && packet.SenderSequence < highestSeq {
+			results.reordered++
+		}
+		if packet.SenderSequence > highestSeq {
+			highestSeq = packet.SenderSequence
+		}
+		seen[packet.SenderSequence] = true
+		results.received++
+
+		// RTT excludes the time the packet spent inside the reflector
+		turnaround := packet.Timestamp.Sub(packet.ReceiveTimestamp)
+		rtt := reply.receivedAt.Sub(sentAt) - turnaround
+		results.rtts = append(results.rtts, durationToMs(rtt))
+
+		// One-way delays are only meaningful when both clocks are synchronized
+		if !stampClockSynced(packet.ErrorEstimate) {
+			results.reflectorSynced = false
+		}
+		if sc.config.ClockSynced && stampClockSynced(packet.ErrorEstimate) {
+			results.forwardDelays = append(results.forwardDelays, durationToMs(packet.ReceiveTimestamp.Sub(sentAt)))
+			results.backwardDelays = append(results.backwardDelays, durationToMs(reply.receivedAt.Sub(packet.Timestamp)))
+		}
+	}
+	results.reflectorSynced = results.reflectorSynced && results.received > 0
+
+	return results
+}
+
+// buildMetrics converts session results into metrics
+func (sc *STAMPCollector) buildMetrics(results *STAMPResults, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	success := 0.0
+	if results.received > 0 {
+		success = 1
+	}
+
+	packetLoss := 0.0
+	if results.sent > 0 {
+		packetLoss = float64(results.sent-results.received) / float64(results.sent) * 100
+	}
+
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "stamp_success",
+			Value:     success,
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_packets_sent",
+			Value:     float64(results.sent),
+			Unit:      "packets",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_packets_received",
+			Value:     float64(results.received),
+			Unit:      "packets",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_packet_loss_percent",
+			Value:     packetLoss,
+			Unit:      "percent",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_reordered_packets",
+			Value:     float64(results.reordered),
+			Unit:      "packets",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_duplicate_packets",
+			Value:     float64(results.duplicates),
+			Unit:      "packets",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "stamp_reflector_clock_synced",
+			Value:     boolToFloat(results.reflectorSynced),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	if len(results.rtts) > 0 {
+		minRTT, avgRTT, maxRTT := summarize(results.rtts)
+		collectedMetrics = append(collectedMetrics, []metrics.Metric{
+			{
+				Name:      "stamp_rtt_avg_ms",
+				Value:     avgRTT,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "stamp_rtt_min_ms",
+				Value:     minRTT,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "stamp_rtt_max_ms",
+				Value:     maxRTT,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "stamp_jitter_ms",
+				Value:     jitter(results.rtts),
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}...)
+	}
+
+	if len(results.forwardDelays) > 0 {
+		_, avgForward, _ := summarize(results.forwardDelays)
+		_, avgBackward, _ := summarize(results.backwardDelays)
+		collectedMetrics = append(collectedMetrics, []metrics.Metric{
+			{
+				Name:      "stamp_forward_delay_avg_ms",
+				Value:     avgForward,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "stamp_backward_delay_avg_ms",
+				Value:     avgBackward,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}...)
+	}
+
+	return collectedMetrics
+}
+
+// durationToMs converts a duration to fractional milliseconds
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// boolToFloat converts a boolean to a 0/1 metric value
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// summarize returns the minimum, average and maximum of a set of samples
+func summarize(samples []float64) (float64, float64, float64) {
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+
+	min, max, sum := samples[0], samples[0], 0.0
+	for _, sample := range samples {
+		sum += sample
+		if sample < min {
+			min = sample
+		}
+		if sample > max {
+			max = sample
+		}
+	}
+
+	return min, sum / float64(len(samples)), max
+}
+
+// jitter returns the mean absolute difference between consecutive samples
+func jitter(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	var total float64
+	for i := 1; i < len(samples); i++ {
+		total += math.Abs(samples[i] - samples[i-1])
+	}
+
+	return total / float64(len(samples)-1)
+}
@@ -0,0 +1,108 @@
+package collectors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// STAMP (RFC 8762) unauthenticated mode packet layout.
+const (
+	stampPacketSize      = 44
+	stampDefaultPort     = "862"
+	stampSyncFlag        = 0x8000 // S bit: the clock is synchronized to an external source
+	stampErrorScale      = 22     // error estimate of 2^(22-32) seconds, roughly one millisecond
+	stampErrorMultiplier = 1
+)
+
+// stampSenderPacket is a session-sender test packet
+type stampSenderPacket struct {
+	Sequence      uint32
+	Timestamp     time.Time
+	ErrorEstimate uint16
+}
+
+// stampReflectorPacket is a session-reflector test packet
+type stampReflectorPacket struct {
+	Sequence            uint32
+	Timestamp           time.Time
+	ErrorEstimate       uint16
+	ReceiveTimestamp    time.Time
+	SenderSequence      uint32
+	SenderTimestamp     time.Time
+	SenderErrorEstimate uint16
+	SenderTTL           uint8
+}
+
+// stampErrorEstimate builds the Error Estimate field in NTP timestamp format
+func stampErrorEstimate(synced bool) uint16 {
+	estimate := uint16(stampErrorScale<<8 | stampErrorMultiplier)
+	if synced {
+		estimate |= stampSyncFlag
+	}
+	return estimate
+}
+
+// stampClockSynced reports whether an Error Estimate field has the S bit set
+func stampClockSynced(estimate uint16) bool {
+	return estimate&stampSyncFlag != 0
+}
+
+// Marshal encodes the session-sender packet
+func (p *stampSenderPacket) Marshal() []byte {
+	buf := make([]byte, stampPacketSize)
+	binary.BigEndian.PutUint32(buf[0:4], p.Sequence)
+	binary.BigEndian.PutUint64(buf[4:12], toNTPTime(p.Timestamp))
+	binary.BigEndian.PutUint16(buf[12:14], p.ErrorEstimate)
+	return buf
+}
+
+// parseSTAMPSenderPacket decodes a session-sender packet
+func parseSTAMPSenderPacket(buf []byte) (*stampSenderPacket, error) {
+	if len(buf) < stampPacketSize {
+		return nil, fmt.Errorf("stamp packet too short: %d bytes", len(buf))
+	}
+
+	return &stampSenderPacket{
+		Sequence:      binary.BigEndian.Uint32(buf[0:4]),
+		Timestamp:     fromNTPTime(binary.BigEndian.Uint64(buf[4:12])),
+		ErrorEstimate: binary.BigEndian.Uint16(buf[12:14]),
+	}, nil
+}
+
+// Marshal encodes the session-reflector packet, padded to size bytes to keep
+// the reflected packet symmetrical with the sender's packet
+func (p *stampReflectorPacket) Marshal(size int) []byte {
+	if size < stampPacketSize {
+		size = stampPacketSize
+	}
+
+	buf := make([]byte, size)
+	binary.BigEndian.PutUint32(buf[0:4], p.Sequence)
+	binary.BigEndian.PutUint64(buf[4:12], toNTPTime(p.Timestamp))
+	binary.BigEndian.PutUint16(buf[12:14], p.ErrorEstimate)
+	binary.BigEndian.PutUint64(buf[16:24], toNTPTime(p.ReceiveTimestamp))
+	binary.BigEndian.PutUint32(buf[24:28], p.SenderSequence)
+	binary.BigEndian.PutUint64(buf[28:36], toNTPTime(p.SenderTimestamp))
+	binary.BigEndian.PutUint16(buf[36:38], p.SenderErrorEstimate)
+	buf[40] = p.SenderTTL
+	return buf
+}
+
+// parseSTAMPReflectorPacket decodes a session-reflector packet
+func parseSTAMPReflectorPacket(buf []byte) (*stampReflectorPacket, error) {
+	if len(buf) < stampPacketSize {
+		return nil, fmt.Errorf("stamp packet too short: %d bytes", len(buf))
+	}
+
+	return &stampReflectorPacket{
+		Sequence:            binary.BigEndian.Uint32(buf[0:4]),
+		Timestamp:           fromNTPTime(binary.BigEndian.Uint64(buf[4:12])),
+		ErrorEstimate:       binary.BigEndian.Uint16(buf[12:14]),
+		ReceiveTimestamp:    fromNTPTime(binary.BigEndian.Uint64(buf[16:24])),
+		SenderSequence:      binary.BigEndian.Uint32(buf[24:28]),
+		SenderTimestamp:     fromNTPTime(binary.BigEndian.Uint64(buf[28:36])),
+		SenderErrorEstimate: binary.BigEndian.Uint16(buf[36:38]),
+		SenderTTL:           buf[40],
+	}, nil
+}
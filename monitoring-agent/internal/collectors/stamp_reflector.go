@@ -0,0 +1,247 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/ipv4"
+)
+
+// stampMaxSessions caps the session-senders tracked at once, so that packets
+// from spoofed source addresses cannot grow the session table without limit
+const stampMaxSessions = 1024
+
+// STAMPReflector runs an RFC 8762 STAMP session-reflector so that other agents,
+// routers and third-party probes can measure towards this host
+type STAMPReflector struct {
+	interval  time.Duration
+	config    metrics.STAMPConfig
+	logger    *logrus.Logger
+	conn      *ipv4.PacketConn
+	sessions  map[string]*stampSession
+	lastSweep time.Time
+	reflected uint64
+	malformed uint64
+	rejected  uint64
+	mutex     sync.Mutex
+	wg        sync.WaitGroup
+}
+
+// stampSession tracks the reflector sequence number of one session-sender
+type stampSession struct {
+	nextSequence uint32
+	lastSeen     time.Time
+}
+
+// NewSTAMPReflector creates a new STAMP session-reflector
+func NewSTAMPReflector(interval time.Duration, config metrics.STAMPConfig, logger *logrus.Logger) *STAMPReflector {
+	return &STAMPReflector{
+		interval: interval,
+		config:   config,
+		logger:   logger,
+		sessions: make(map[string]*stampSession),
+	}
+}
+
+// Name returns the collector name
+func (sr *STAMPReflector) Name() string {
+	return "stamp_reflector"
+}
+
+// Interval returns the collection interval
+func (sr *STAMPReflector) Interval() time.Duration {
+	return sr.interval
+}
+
+// Start opens the reflector socket and begins reflecting test packets
+func (sr *STAMPReflector) Start(ctx context.Context) error {
+	sr.logger.WithField("address", sr.config.ReflectorAddress).Info("Starting STAMP reflector")
+
+	conn, err := net.ListenPacket("udp", sr.config.ReflectorAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", sr.config.ReflectorAddress, err)
+	}
+
+	sr.conn = ipv4.NewPacketConn(conn)
+	if err := sr.conn.SetControlMessage(ipv4.FlagTTL, true); err != nil {
+		// The sender TTL field is left as zero when the kernel cannot report it
+		sr.logger.WithError(err).Debug("Unable to receive TTL of STAMP packets")
+	}
+
+	sr.wg.Add(1)
+	go sr.reflectLoop()
+
+	return nil
+}
+
+// Stop closes the reflector socket
+func (sr *STAMPReflector) Stop() error {
+	sr.logger.Info("Stopping STAMP reflector")
+
+	if sr.conn == nil {
+		return nil
+	}
+
+	err := sr.conn.Close()
+	sr.wg.Wait()
+	return err
+}
+
+// Collect reports reflector activity since the previous collection
+func (sr *STAMPReflector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	sr.mutex.Lock()
+	sr.expireSessions(currentTime)
+	reflected := sr.reflected
+	malformed := sr.malformed
+	rejected := sr.rejected
+	sessions := len(sr.sessions)
+	sr.mutex.Unlock()
+
+	tags := map[string]string{
+		"address": sr.config.ReflectorAddress,
+	}
+
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "stamp_reflector_packets_reflected_total",
+			Value:     float64(reflected),
+			Unit:      "packets",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeCounter,
+		},
+		{
+			Name:      "stamp_reflector_packets_malformed_total",
+			Value:     float64(malformed),
+			Unit:      "packets",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeCounter,
+		},
+		{
+			Name:      "stamp_reflector_packets_rejected_total",
+			Value:     float64(rejected),
+			Unit:      "packets",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeCounter,
+		},
+		{
+			Name:      "stamp_reflector_active_sessions",
+			Value:     float64(sessions),
+			Unit:      "sessions",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	sr.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected STAMP reflector metrics")
+	return collectedMetrics, nil
+}
+
+// reflectLoop answers session-sender packets until the socket is closed
+func (sr *STAMPReflector) reflectLoop() {
+	defer sr.wg.Done()
+
+	buf := make([]byte, 1500)
+	errorEstimate := stampErrorEstimate(sr.config.ClockSynced)
+
+	for {
+		n, cm, src, err := sr.conn.ReadFrom(buf)
+		receivedAt := time.Now()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return
+		}
+
+		request, err := parseSTAMPSenderPacket(buf[:n])
+		if err != nil {
+			sr.mutex.Lock()
+			sr.malformed++
+			sr.mutex.Unlock()
+			continue
+		}
+
+		// Stateful reflector: keep an independent sequence number per session-sender
+		sr.mutex.Lock()
+		session := sr.session(src.String(), receivedAt)
+		if session == nil {
+			sr.rejected++
+			sr.mutex.Unlock()
+			continue
+		}
+		seq := session.nextSequence
+		session.nextSequence++
+		sr.mutex.Unlock()
+
+		reply := &stampReflectorPacket{
+			Sequence:            seq,
+			ErrorEstimate:       errorEstimate,
+			ReceiveTimestamp:    receivedAt,
+			SenderSequence:      request.Sequence,
+			SenderTimestamp:     request.Timestamp,
+			SenderErrorEstimate: request.ErrorEstimate,
+		}
+		if cm != nil {
+			reply.SenderTTL = uint8(cm.TTL)
+		}
+		reply.Timestamp = time.Now()
+
+		if _, err := sr.conn.WriteTo(reply.Marshal(n), nil, src); err != nil {
+			sr.logger.WithFields(logrus.Fields{
+				"sender": src.String(),
+				"error":  err,
+			}).Debug("Failed to reflect STAMP packet")
+			continue
+		}
+
+		sr.mutex.Lock()
+		sr.reflected++
+		sr.mutex.Unlock()
+	}
+}
+
+// session returns the session of sender, starting one unless the session
+// table is full. Idle sessions are expired here as well as in Collect, so
+// that a burst of senders between collections cannot exhaust the table.
+// The caller holds sr.mutex.
+func (sr *STAMPReflector) session(sender string, now time.Time) *stampSession {
+	if now.Sub(sr.lastSweep) > sr.interval {
+		sr.expireSessions(now)
+	}
+
+	session, exists := sr.sessions[sender]
+	if !exists {
+		if len(sr.sessions) >= stampMaxSessions {
+			sr.expireSessions(now)
+			if len(sr.sessions) >= stampMaxSessions {
+				return nil
+			}
+		}
+		session = &stampSession{}
+		sr.sessions[sender] = session
+	}
+	session.lastSeen = now
+	return session
+}
+
+// expireSessions removes sessions idle for two intervals, which are
+// considered finished. The caller holds sr.mutex.
+func (sr *STAMPReflector) expireSessions(now time.Time) {
+	for sender, session := range sr.sessions {
+		if now.Sub(session.lastSeen) > 2*sr.interval {
+			delete(sr.sessions, sender)
+		}
+	}
+	sr.lastSweep = now
+}
@@ -0,0 +1,207 @@
+package collectors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNTPTimeRoundTrip(t *testing.T) {
+	tests := []time.Time{
+		time.Unix(0, 0),
+		time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC),
+		time.Date(2036, 2, 7, 6, 28, 15, 999999999, time.UTC),
+	}
+
+	for _, tt := range tests {
+		if got := fromNTPTime(toNTPTime(tt)); !ntpTimeEqual(got, tt) {
+			t.Errorf("fromNTPTime(toNTPTime(%v)) = %v, want within 1ns", tt, got)
+		}
+	}
+
+	if got := toNTPTime(time.Unix(0, 0)) >> 32; got != ntpEpochOffset {
+		t.Errorf("toNTPTime(Unix epoch) seconds = %d, want %d", got, ntpEpochOffset)
+	}
+	if got := toNTPTime(time.Unix(1, int64(time.Second/2))) & 0xffffffff; got != 1<<31 {
+		t.Errorf("toNTPTime(1.5s) fraction = %#x, want %#x", got, uint64(1<<31))
+	}
+}
+
+func TestSTAMPSenderPacketRoundTrip(t *testing.T) {
+	packet := &stampSenderPacket{
+		Sequence:      42,
+		Timestamp:     time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC),
+		ErrorEstimate: stampErrorEstimate(true),
+	}
+
+	buf := packet.Marshal()
+	if len(buf) != stampPacketSize {
+		t.Fatalf("Marshal() = %d bytes, want %d", len(buf), stampPacketSize)
+	}
+
+	parsed, err := parseSTAMPSenderPacket(buf)
+	if err != nil {
+		t.Fatalf("parseSTAMPSenderPacket() error = %v", err)
+	}
+	if parsed.Sequence != packet.Sequence || !ntpTimeEqual(parsed.Timestamp, packet.Timestamp) || parsed.ErrorEstimate != packet.ErrorEstimate {
+		t.Errorf("parseSTAMPSenderPacket() = %+v, want %+v", parsed, packet)
+	}
+	if !stampClockSynced(parsed.ErrorEstimate) {
+		t.Error("stampClockSynced() = false for a synchronized error estimate")
+	}
+
+	if _, err := parseSTAMPSenderPacket(buf[:stampPacketSize-1]); err == nil {
+		t.Error("parseSTAMPSenderPacket() of a short packet succeeded, want an error")
+	}
+}
+
+func TestSTAMPReflectorPacketRoundTrip(t *testing.T) {
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	packet := &stampReflectorPacket{
+		Sequence:            7,
+		Timestamp:           base.Add(2 * time.Millisecond),
+		ErrorEstimate:       stampErrorEstimate(false),
+		ReceiveTimestamp:    base.Add(time.Millisecond),
+		SenderSequence:      9,
+		SenderTimestamp:     base,
+		SenderErrorEstimate: stampErrorEstimate(true),
+		SenderTTL:           63,
+	}
+
+	// Replies are padded to the size of the sender's packet
+	buf := packet.Marshal(100)
+	if len(buf) != 100 {
+		t.Fatalf("Marshal(100) = %d bytes, want 100", len(buf))
+	}
+	if got := len(packet.Marshal(10)); got != stampPacketSize {
+		t.Errorf("Marshal(10) = %d bytes, want %d", got, stampPacketSize)
+	}
+
+	parsed, err := parseSTAMPReflectorPacket(buf)
+	if err != nil {
+		t.Fatalf("parseSTAMPReflectorPacket() error = %v", err)
+	}
+	if parsed.Sequence != packet.Sequence ||
+		!ntpTimeEqual(parsed.Timestamp, packet.Timestamp) ||
+		parsed.ErrorEstimate != packet.ErrorEstimate ||
+		!ntpTimeEqual(parsed.ReceiveTimestamp, packet.ReceiveTimestamp) ||
+		parsed.SenderSequence != packet.SenderSequence ||
+		!ntpTimeEqual(parsed.SenderTimestamp, packet.SenderTimestamp) ||
+		parsed.SenderErrorEstimate != packet.SenderErrorEstimate ||
+		parsed.SenderTTL != packet.SenderTTL {
+		t.Errorf("parseSTAMPReflectorPacket() = %+v, want %+v", parsed, packet)
+	}
+	if stampClockSynced(parsed.ErrorEstimate) {
+		t.Error("stampClockSynced() = true for an unsynchronized error estimate")
+	}
+
+	if _, err := parseSTAMPReflectorPacket(buf[:20]); err == nil {
+		t.Error("parseSTAMPReflectorPacket() of a short packet succeeded, want an error")
+	}
+}
+
+func TestSTAMPAnalyzeReplies(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	collector := NewSTAMPCollector(time.Minute, nil, metrics.STAMPConfig{}, logger)
+
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	sendTimes := make(map[uint32]time.Time)
+	for seq := uint32(0); seq < 5; seq++ {
+		sendTimes[seq] = base.Add(time.Duration(seq) * 100 * time.Millisecond)
+	}
+
+	// reply reflects seq after 10ms with a 1ms turnaround in the reflector
+	reply := func(seq uint32) stampReply {
+		received := sendTimes[seq].Add(5 * time.Millisecond)
+		return stampReply{
+			packet: &stampReflectorPacket{
+				SenderSequence:   seq,
+				ReceiveTimestamp: received,
+				Timestamp:        received.Add(time.Millisecond),
+			},
+			receivedAt: sendTimes[seq].Add(11 * time.Millisecond),
+		}
+	}
+
+	// 3 is lost, 1 arrives after 2, 2 is duplicated and 99 is another session's
+	replies := []stampReply{reply(0), reply(2), reply(1), reply(2), reply(4)}
+	replies = append(replies, stampReply{packet: &stampReflectorPacket{SenderSequence: 99}})
+
+	results := collector.analyzeReplies(sendTimes, replies)
+	if results.sent != 5 || results.received != 4 {
+		t.Errorf("analyzeReplies() sent/received = %d/%d, want 5/4", results.sent, results.received)
+	}
+	if results.reordered != 1 {
+		t.Errorf("analyzeReplies() reordered = %d, want 1", results.reordered)
+	}
+	if results.duplicates != 1 {
+		t.Errorf("analyzeReplies() duplicates = %d, want 1", results.duplicates)
+	}
+	for _, rtt := range results.rtts {
+		if rtt != 10 {
+			t.Errorf("analyzeReplies() rtt = %v, want 10 without the reflector turnaround", rtt)
+		}
+	}
+	if results.reflectorSynced {
+		t.Error("analyzeReplies() reflectorSynced = true for an unsynchronized reflector")
+	}
+	if len(results.forwardDelays) != 0 {
+		t.Errorf("analyzeReplies() forwardDelays = %v without synchronized clocks, want none", results.forwardDelays)
+	}
+
+	lossMetric := findMetric(collector.buildMetrics(results, nil, base), "stamp_packet_loss_percent")
+	if lossMetric == nil || lossMetric.Value != 20 {
+		t.Errorf("stamp_packet_loss_percent = %v, want 20", lossMetric)
+	}
+}
+
+func TestSTAMPReflectorSessionLimit(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	reflector := NewSTAMPReflector(time.Minute, metrics.STAMPConfig{}, logger)
+
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < stampMaxSessions; i++ {
+		if reflector.session(fmt.Sprintf("192.0.2.1:%d", i), now) == nil {
+			t.Fatalf("session() rejected sender %d below the limit", i)
+		}
+	}
+
+	if reflector.session("198.51.100.1:862", now) != nil {
+		t.Error("session() accepted a new sender beyond the limit")
+	}
+	if reflector.session("192.0.2.1:0", now) == nil {
+		t.Error("session() rejected a known sender at the limit")
+	}
+
+	// Idle sessions are expired by the receive path without a collection
+	later := now.Add(3 * time.Minute)
+	if reflector.session("198.51.100.1:862", later) == nil {
+		t.Error("session() rejected a new sender after the idle sessions expired")
+	}
+	if len(reflector.sessions) != 1 {
+		t.Errorf("sessions after expiry = %d, want 1", len(reflector.sessions))
+	}
+}
+
+// ntpTimeEqual reports whether got is want after an NTP timestamp round trip,
+// whose 32-bit fraction is truncated to below a nanosecond
+func ntpTimeEqual(got, want time.Time) bool {
+	diff := want.Sub(got)
+	return diff >= 0 && diff <= time.Nanosecond
+}
+
+// findMetric returns the first metric called name
+func findMetric(collected []metrics.Metric, name string) *metrics.Metric {
+	for i := range collected {
+		if collected[i].Name == name {
+			return &collected[i]
+		}
+	}
+	return nil
+}
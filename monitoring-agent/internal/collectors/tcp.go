@@ -0,0 +1,125 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// TCPCollector measures how long it takes to open a TCP connection to each
+// target, optionally through a proxy or from a chosen source address or interface
+type TCPCollector struct {
+	interval time.Duration
+	targets  []metrics.TCPTarget
+	egress   metrics.EgressConfig
+	paths    []*egressPath
+	logger   *logrus.Logger
+}
+
+// NewTCPCollector creates a new TCP connect collector
+func NewTCPCollector(interval time.Duration, targets []metrics.TCPTarget, egress metrics.EgressConfig, logger *logrus.Logger) *TCPCollector {
+	return &TCPCollector{
+		interval: interval,
+		targets:  targets,
+		egress:   egress,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (tc *TCPCollector) Name() string {
+	return "tcp"
+}
+
+// Interval returns the collection interval
+func (tc *TCPCollector) Interval() time.Duration {
+	return tc.interval
+}
+
+// Start initializes the collector
+func (tc *TCPCollector) Start(ctx context.Context) error {
+	tc.logger.WithField("targets", len(tc.targets)).Info("Starting TCP collector")
+
+	if len(tc.targets) == 0 {
+		return fmt.Errorf("no TCP targets configured")
+	}
+
+	tc.paths = nil
+	for _, target := range tc.targets {
+		path, err := newEgressPath(tc.egress, target.Egress)
+		if err != nil {
+			return fmt.Errorf("invalid TCP target %s: %w", target.Address, err)
+		}
+		tc.paths = append(tc.paths, path)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (tc *TCPCollector) Stop() error {
+	tc.logger.Info("Stopping TCP collector")
+	return nil
+}
+
+// Collect connects to every target once
+func (tc *TCPCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for i, target := range tc.targets {
+		tags := tc.paths[i].tagged(map[string]string{
+			"target": target.Address,
+		})
+
+		connectTime, err := tc.connect(ctx, target, tc.paths[i])
+		if err != nil {
+			tc.logger.WithFields(logrus.Fields{
+				"target": target.Address,
+				"error":  err,
+			}).Warn("TCP connect failed")
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "tcp_connect_success",
+			Value:     boolToFloat(err == nil),
+			Unit:      "boolean",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+		if err == nil {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "tcp_connect_time_ms",
+				Value:     durationToMs(connectTime),
+				Unit:      "ms",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+
+	tc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected TCP metrics")
+	return collectedMetrics, nil
+}
+
+// connect opens and closes a connection, returning the time to establish it.
+// Through a proxy this includes the proxy handshake.
+func (tc *TCPCollector) connect(ctx context.Context, target metrics.TCPTarget, path *egressPath) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, target.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := path.dialTCP(ctx, target.Address)
+	if err != nil {
+		return 0, err
+	}
+	connectTime := time.Since(start)
+	conn.Close()
+
+	return connectTime, nil
+}
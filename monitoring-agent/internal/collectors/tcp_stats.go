@@ -0,0 +1,467 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// tcpStateNames maps kernel TCP states (include/net/tcp_states.h) to names
+var tcpStateNames = map[uint8]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+	12: "NEW_SYN_RECV",
+}
+
+const tcpStateListen = 10
+
+// tcpStatsCounters lists the kernel TCP counters reported as totals and per-second rates
+var tcpStatsCounters = []struct {
+	section string
+	field   string
+	name    string
+	unit    string
+}{
+	{"Tcp", "ActiveOpens", "tcp_stats_active_opens", "connections"},
+	{"Tcp", "PassiveOpens", "tcp_stats_passive_opens", "connections"},
+	{"Tcp", "AttemptFails", "tcp_stats_attempt_fails", "connections"},
+	{"Tcp", "EstabResets", "tcp_stats_established_resets", "connections"},
+	{"Tcp", "InSegs", "tcp_stats_in_segments", "segments"},
+	{"Tcp", "OutSegs", "tcp_stats_out_segments", "segments"},
+	{"Tcp", "RetransSegs", "tcp_stats_retransmitted_segments", "segments"},
+	{"Tcp", "InErrs", "tcp_stats_in_errors", "segments"},
+	{"Tcp", "OutRsts", "tcp_stats_out_resets", "segments"},
+	{"TcpExt", "TCPTimeouts", "tcp_stats_rto_timeouts", "timeouts"},
+	{"TcpExt", "TCPSynRetrans", "tcp_stats_syn_retransmits", "segments"},
+	{"TcpExt", "TCPLostRetransmit", "tcp_stats_lost_retransmits", "segments"},
+	{"TcpExt", "ListenOverflows", "tcp_stats_listen_overflows", "connections"},
+	{"TcpExt", "ListenDrops", "tcp_stats_listen_drops", "connections"},
+	{"TcpExt", "SyncookiesSent", "tcp_stats_syncookies_sent", "cookies"},
+	{"TcpExt", "SyncookiesRecv", "tcp_stats_syncookies_received", "cookies"},
+	{"TcpExt", "SyncookiesFailed", "tcp_stats_syncookies_failed", "cookies"},
+}
+
+// errSocketDiagUnsupported is returned where sock_diag netlink is not available
+var errSocketDiagUnsupported = errors.New("sock_diag is not supported on this platform")
+
+// TCPStatsCollector collects TCP connection health metrics: sockets by state,
+// kernel retransmission/RTO/listen-queue counters and per-socket RTT and
+// congestion window aggregated by local port and remote subnet
+type TCPStatsCollector struct {
+	interval   time.Duration
+	config     metrics.TCPStatsConfig
+	procRoot   string
+	rates      *counterRates
+	diagWarned bool
+	logger     *logrus.Logger
+}
+
+// tcpSocketInfo holds the details of a single socket reported by sock_diag
+type tcpSocketInfo struct {
+	family       string
+	localIP      net.IP
+	localPort    uint16
+	remoteIP     net.IP
+	remotePort   uint16
+	rtt          time.Duration
+	sndCwnd      uint32
+	unacked      uint32
+	totalRetrans uint32
+}
+
+// tcpSocketGroup aggregates sockets sharing a local port and remote subnet
+type tcpSocketGroup struct {
+	family       string
+	localPort    string
+	remoteSubnet string
+	sockets      int
+	rttSum       float64
+	rttMax       float64
+	cwndSum      float64
+	totalRetrans uint64
+	unacked      uint64
+}
+
+// NewTCPStatsCollector creates a new TCP connection statistics collector
+func NewTCPStatsCollector(interval time.Duration, config metrics.TCPStatsConfig, procRoot string, logger *logrus.Logger) *TCPStatsCollector {
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+
+	return &TCPStatsCollector{
+		interval: interval,
+		config:   config,
+		procRoot: procRoot,
+		rates:    newCounterRates(),
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (tc *TCPStatsCollector) Name() string {
+	return "tcp_stats"
+}
+
+// Interval returns the collection interval
+func (tc *TCPStatsCollector) Interval() time.Duration {
+	return tc.interval
+}
+
+// Start initializes the collector
+func (tc *TCPStatsCollector) Start(ctx context.Context) error {
+	tc.logger.Info("Starting TCP statistics collector")
+
+	// Initialize with first measurement
+	counters, err := tc.readCounters()
+	if err != nil {
+		return fmt.Errorf("failed to get initial TCP counters: %w", err)
+	}
+	tc.observeCounters(counters, time.Now())
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (tc *TCPStatsCollector) Stop() error {
+	tc.logger.Info("Stopping TCP statistics collector")
+	return nil
+}
+
+// Collect gathers TCP connection metrics
+func (tc *TCPStatsCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+
+	var collectedMetrics []metrics.Metric
+
+	// Socket counts by state
+	states, listenPorts, err := tc.readSocketStates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TCP sockets: %w", err)
+	}
+	for _, stateName := range tcpStateNames {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "tcp_stats_connections",
+			Value:     float64(states[stateName]),
+			Unit:      "connections",
+			Timestamp: currentTime,
+			Tags: map[string]string{
+				"state": stateName,
+			},
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	// Kernel counters and rates
+	counters, err := tc.readCounters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TCP counters: %w", err)
+	}
+	collectedMetrics = append(collectedMetrics, tc.counterMetrics(counters, currentTime)...)
+
+	// Per-socket details are best effort: sock_diag may be unavailable. It
+	// reports the agent's own network namespace regardless of procRoot.
+	sockets, err := readTCPSocketDiag()
+	if err != nil {
+		if !tc.diagWarned {
+			tc.logger.WithError(err).Warn("Per-socket TCP details unavailable")
+			tc.diagWarned = true
+		}
+	} else {
+		collectedMetrics = append(collectedMetrics, tc.groupMetrics(sockets, listenPorts, currentTime)...)
+	}
+
+	tc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected TCP statistics metrics")
+	return collectedMetrics, nil
+}
+
+// readCounters reads the Tcp and TcpExt sections of /proc/net/snmp and /proc/net/netstat
+func (tc *TCPStatsCollector) readCounters() (map[string]int64, error) {
+	counters := make(map[string]int64)
+
+	for _, file := range []string{"snmp", "netstat"} {
+		sections, err := readProcNetSNMP(procPath(tc.procRoot, "net", file))
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range []string{"Tcp", "TcpExt"} {
+			for field, value := range sections[section] {
+				counters[section+"."+field] = value
+			}
+		}
+	}
+
+	return counters, nil
+}
+
+// observeCounters records the kernel TCP counters and returns their increases
+// and per-second rates since the previous collection
+func (tc *TCPStatsCollector) observeCounters(counters map[string]int64, timestamp time.Time) (map[string]uint64, map[string]float64) {
+	deltas := make(map[string]uint64, len(counters))
+	rates := make(map[string]float64, len(counters))
+
+	for key, value := range counters {
+		if delta, rate, ok := tc.rates.observe(key, uint64(value), counterNative, timestamp); ok {
+			deltas[key] = delta
+			rates[key] = rate
+		}
+	}
+	tc.rates.sweep()
+
+	return deltas, rates
+}
+
+// counterMetrics builds total and per-second metrics for the kernel TCP counters
+func (tc *TCPStatsCollector) counterMetrics(counters map[string]int64, timestamp time.Time) []metrics.Metric {
+	var collectedMetrics []metrics.Metric
+	deltas, rates := tc.observeCounters(counters, timestamp)
+
+	for _, counter := range tcpStatsCounters {
+		key := counter.section + "." + counter.field
+		value, exists := counters[key]
+		if !exists {
+			continue
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      counter.name + "_total",
+			Value:     float64(value),
+			Unit:      counter.unit,
+			Timestamp: timestamp,
+			Tags:      map[string]string{},
+			Type:      metrics.MetricTypeCounter,
+		})
+
+		if rate, ok := rates[key]; ok {
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      counter.name + "_per_sec",
+				Value:     rate,
+				Unit:      counter.unit + "/sec",
+				Timestamp: timestamp,
+				Tags:      map[string]string{},
+				Type:      metrics.MetricTypeGauge,
+			})
+		}
+	}
+
+	// Share of outgoing segments that were retransmissions
+	retransDelta, retransOK := deltas["Tcp.RetransSegs"]
+	outDelta, outOK := deltas["Tcp.OutSegs"]
+	if retransOK && outOK && outDelta > 0 {
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "tcp_stats_retransmission_ratio_percent",
+			Value:     float64(retransDelta) / float64(outDelta) * 100,
+			Unit:      "percent",
+			Timestamp: timestamp,
+			Tags:      map[string]string{},
+			Type:      metrics.MetricTypeGauge,
+		})
+	}
+
+	collectedMetrics = append(collectedMetrics, tc.rates.resetMetric("tcp_stats_counter_resets_total", timestamp))
+
+	return collectedMetrics
+}
+
+// readSocketStates counts sockets by state from /proc/net/tcp and /proc/net/tcp6
+// and returns the set of local ports with a listening socket
+func (tc *TCPStatsCollector) readSocketStates() (map[string]int, map[uint16]bool, error) {
+	states := make(map[string]int)
+	listenPorts := make(map[uint16]bool)
+
+	for _, file := range []string{"tcp", "tcp6"} {
+		err := tc.scanProcNetTCP(procPath(tc.procRoot, "net", file), func(state uint8, localPort uint16) {
+			if name, ok := tcpStateNames[state]; ok {
+				states[name]++
+			}
+			if state == tcpStateListen {
+				listenPorts[localPort] = true
+			}
+		})
+		if err != nil {
+			// tcp6 is missing when IPv6 is disabled
+			if os.IsNotExist(err) && file == "tcp6" {
+				continue
+			}
+			return nil, nil, err
+		}
+	}
+
+	return states, listenPorts, nil
+}
+
+// scanProcNetTCP calls fn with the state and local port of every socket in a /proc/net/tcp file
+func (tc *TCPStatsCollector) scanProcNetTCP(path string, fn func(state uint8, localPort uint16)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip header
+
+	for scanner.Scan() {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			continue
+		}
+
+		var localPort uint64
+		if idx := strings.LastIndex(fields[1], ":"); idx >= 0 {
+			localPort, _ = strconv.ParseUint(fields[1][idx+1:], 16, 16)
+		}
+
+		fn(uint8(state), uint16(localPort))
+	}
+
+	return scanner.Err()
+}
+
+// groupMetrics aggregates per-socket details by local port and remote subnet
+func (tc *TCPStatsCollector) groupMetrics(sockets []tcpSocketInfo, listenPorts map[uint16]bool, timestamp time.Time) []metrics.Metric {
+	groups := make(map[string]*tcpSocketGroup)
+
+	for _, socket := range sockets {
+		// Client sockets use ephemeral local ports which would explode cardinality
+		localPort := "ephemeral"
+		if listenPorts[socket.localPort] {
+			localPort = strconv.Itoa(int(socket.localPort))
+		}
+
+		remoteSubnet := tc.remoteSubnet(socket.remoteIP)
+		key := socket.family + "|" + localPort + "|" + remoteSubnet
+
+		group, exists := groups[key]
+		if !exists {
+			group = &tcpSocketGroup{
+				family:       socket.family,
+				localPort:    localPort,
+				remoteSubnet: remoteSubnet,
+			}
+			groups[key] = group
+		}
+
+		rtt := durationToMs(socket.rtt)
+		group.sockets++
+		group.rttSum += rtt
+		if rtt > group.rttMax {
+			group.rttMax = rtt
+		}
+		group.cwndSum += float64(socket.sndCwnd)
+		group.totalRetrans += uint64(socket.totalRetrans)
+		group.unacked += uint64(socket.unacked)
+	}
+
+	sortedGroups := make([]*tcpSocketGroup, 0, len(groups))
+	for _, group := range groups {
+		sortedGroups = append(sortedGroups, group)
+	}
+	sort.Slice(sortedGroups, func(i, j int) bool {
+		return sortedGroups[i].sockets > sortedGroups[j].sockets
+	})
+	if len(sortedGroups) > tc.config.MaxGroups {
+		tc.logger.WithFields(logrus.Fields{
+			"groups":     len(sortedGroups),
+			"max_groups": tc.config.MaxGroups,
+		}).Debug("Truncating TCP socket groups")
+		sortedGroups = sortedGroups[:tc.config.MaxGroups]
+	}
+
+	var collectedMetrics []metrics.Metric
+	for _, group := range sortedGroups {
+		tags := map[string]string{
+			"family":        group.family,
+			"local_port":    group.localPort,
+			"remote_subnet": group.remoteSubnet,
+		}
+
+		collectedMetrics = append(collectedMetrics, []metrics.Metric{
+			{
+				Name:      "tcp_stats_group_sockets",
+				Value:     float64(group.sockets),
+				Unit:      "sockets",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "tcp_stats_group_rtt_avg_ms",
+				Value:     group.rttSum / float64(group.sockets),
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "tcp_stats_group_rtt_max_ms",
+				Value:     group.rttMax,
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "tcp_stats_group_cwnd_avg_segments",
+				Value:     group.cwndSum / float64(group.sockets),
+				Unit:      "segments",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "tcp_stats_group_retransmits",
+				Value:     float64(group.totalRetrans),
+				Unit:      "segments",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "tcp_stats_group_unacked_segments",
+				Value:     float64(group.unacked),
+				Unit:      "segments",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}...)
+	}
+
+	return collectedMetrics
+}
+
+// remoteSubnet masks a peer address to the configured aggregation prefix
+func (tc *TCPStatsCollector) remoteSubnet(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		mask := net.CIDRMask(tc.config.RemotePrefixV4, 32)
+		return (&net.IPNet{IP: ip4.Mask(mask), Mask: mask}).String()
+	}
+
+	mask := net.CIDRMask(tc.config.RemotePrefixV6, 128)
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
@@ -0,0 +1,90 @@
+package collectors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// sock_diag constants from linux/sock_diag.h and linux/inet_diag.h
+const (
+	sockDiagByFamily    = 20
+	inetDiagInfo        = 2
+	inetDiagReqV2Size   = 56
+	inetDiagMsgSize     = 72
+	inetDiagSockIDStart = 4
+)
+
+// tcp_info field offsets from linux/tcp.h
+const (
+	tcpInfoUnackedOffset      = 24
+	tcpInfoRTTOffset          = 68
+	tcpInfoSndCwndOffset      = 80
+	tcpInfoTotalRetransOffset = 100
+	tcpInfoMinSize            = 104
+)
+
+// tcpDiagStates selects every state that carries a full tcp_info: LISTEN,
+// TIME_WAIT and CLOSE sockets are excluded
+const tcpDiagStates = (1<<12 - 1) &^ (1<<tcpStateListen | 1<<6 | 1<<7)
+
+// readTCPSocketDiag dumps IPv4 and IPv6 TCP sockets with their tcp_info via sock_diag netlink
+func readTCPSocketDiag() ([]tcpSocketInfo, error) {
+	var sockets []tcpSocketInfo
+
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		request := make([]byte, inetDiagReqV2Size)
+		request[0] = family
+		request[1] = syscall.IPPROTO_TCP
+		request[2] = 1 << (inetDiagInfo - 1)
+		binary.LittleEndian.PutUint32(request[4:8], tcpDiagStates)
+
+		messages, err := netlinkDump(syscall.NETLINK_INET_DIAG, sockDiagByFamily, request)
+		if err != nil {
+			return nil, fmt.Errorf("sock_diag dump failed: %w", err)
+		}
+
+		for _, message := range messages {
+			if socket, ok := parseInetDiagMessage(family, message.Data); ok {
+				sockets = append(sockets, socket)
+			}
+		}
+	}
+
+	return sockets, nil
+}
+
+// parseInetDiagMessage decodes an inet_diag_msg followed by its INET_DIAG_INFO attribute
+func parseInetDiagMessage(family uint8, data []byte) (tcpSocketInfo, bool) {
+	if len(data) < inetDiagMsgSize {
+		return tcpSocketInfo{}, false
+	}
+
+	id := data[inetDiagSockIDStart:]
+	socket := tcpSocketInfo{
+		localPort:  binary.BigEndian.Uint16(id[0:2]),
+		remotePort: binary.BigEndian.Uint16(id[2:4]),
+	}
+
+	if family == syscall.AF_INET {
+		socket.family = "ipv4"
+		socket.localIP = net.IP(append([]byte(nil), id[4:8]...))
+		socket.remoteIP = net.IP(append([]byte(nil), id[20:24]...))
+	} else {
+		socket.family = "ipv6"
+		socket.localIP = net.IP(append([]byte(nil), id[4:20]...))
+		socket.remoteIP = net.IP(append([]byte(nil), id[20:36]...))
+	}
+
+	attrs := parseNetlinkAttrs(data[inetDiagMsgSize:])
+	if info := attrs[inetDiagInfo]; len(info) >= tcpInfoMinSize {
+		socket.unacked = binary.LittleEndian.Uint32(info[tcpInfoUnackedOffset:])
+		socket.rtt = time.Duration(binary.LittleEndian.Uint32(info[tcpInfoRTTOffset:])) * time.Microsecond
+		socket.sndCwnd = binary.LittleEndian.Uint32(info[tcpInfoSndCwndOffset:])
+		socket.totalRetrans = binary.LittleEndian.Uint32(info[tcpInfoTotalRetransOffset:])
+	}
+
+	return socket, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package collectors
+
+// readTCPSocketDiag is only implemented on Linux
+func readTCPSocketDiag() ([]tcpSocketInfo, error) {
+	return nil, errSocketDiagUnsupported
+}
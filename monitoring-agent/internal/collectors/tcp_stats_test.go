@@ -0,0 +1,157 @@
+package collectors
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestTCPStatsCollector(procRoot string, maxGroups int) *TCPStatsCollector {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	config := metrics.TCPStatsConfig{RemotePrefixV4: 24, RemotePrefixV6: 64, MaxGroups: maxGroups}
+	return NewTCPStatsCollector(0, config, procRoot, logger)
+}
+
+func TestTCPStatsReadSocketStates(t *testing.T) {
+	collector := newTestTCPStatsCollector("testdata/proc", 50)
+
+	states, listenPorts, err := collector.readSocketStates()
+	if err != nil {
+		t.Fatalf("readSocketStates() error = %v", err)
+	}
+
+	wantStates := map[string]int{
+		"LISTEN":      3,
+		"ESTABLISHED": 3,
+		"TIME_WAIT":   1,
+		"SYN_SENT":    1,
+	}
+	for state, want := range wantStates {
+		if got := states[state]; got != want {
+			t.Errorf("state %s = %d, want %d", state, got, want)
+		}
+	}
+	if len(states) != len(wantStates) {
+		t.Errorf("readSocketStates() states = %v, want %v", states, wantStates)
+	}
+
+	for _, port := range []uint16{22, 80, 443} {
+		if !listenPorts[port] {
+			t.Errorf("listening port %d missing", port)
+		}
+	}
+	if len(listenPorts) != 3 {
+		t.Errorf("readSocketStates() listen ports = %v, want 22, 80 and 443", listenPorts)
+	}
+}
+
+func TestTCPStatsReadSocketStatesWithoutTCP6(t *testing.T) {
+	collector := newTestTCPStatsCollector("testdata/proc/missing", 50)
+
+	// tcp6 is absent when IPv6 is disabled, but tcp is required
+	if _, _, err := collector.readSocketStates(); err == nil {
+		t.Error("readSocketStates() without tcp succeeded, want an error")
+	}
+}
+
+func TestTCPStatsReadCounters(t *testing.T) {
+	collector := newTestTCPStatsCollector("testdata/proc", 50)
+
+	counters, err := collector.readCounters()
+	if err != nil {
+		t.Fatalf("readCounters() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want int64
+	}{
+		{"Tcp.ActiveOpens", 195},
+		{"Tcp.PassiveOpens", 144},
+		{"Tcp.OutSegs", 19519},
+		{"Tcp.RetransSegs", 1},
+		{"Tcp.OutRsts", 44},
+		{"TcpExt.SyncookiesSent", 0},
+		{"TcpExt.DelayedACKs", 40},
+	}
+	for _, tt := range tests {
+		if got, ok := counters[tt.key]; !ok || got != tt.want {
+			t.Errorf("counter %s = %d (present %v), want %d", tt.key, got, ok, tt.want)
+		}
+	}
+	if _, ok := counters["Udp.InDatagrams"]; ok {
+		t.Error("readCounters() returned a counter outside the Tcp and TcpExt sections")
+	}
+}
+
+func TestTCPStatsGroupMetrics(t *testing.T) {
+	collector := newTestTCPStatsCollector("testdata/proc", 2)
+	listenPorts := map[uint16]bool{22: true, 443: true}
+
+	sockets := []tcpSocketInfo{
+		{family: "ipv4", localPort: 22, remoteIP: net.ParseIP("192.168.1.10"), rtt: 10 * time.Millisecond, sndCwnd: 10, totalRetrans: 1},
+		{family: "ipv4", localPort: 22, remoteIP: net.ParseIP("192.168.1.11"), rtt: 30 * time.Millisecond, sndCwnd: 20, totalRetrans: 2, unacked: 3},
+		{family: "ipv4", localPort: 22, remoteIP: net.ParseIP("192.168.1.200"), rtt: 20 * time.Millisecond, sndCwnd: 30},
+		{family: "ipv6", localPort: 443, remoteIP: net.ParseIP("2001:db8::1"), rtt: 5 * time.Millisecond, sndCwnd: 10},
+		{family: "ipv6", localPort: 443, remoteIP: net.ParseIP("2001:db8::2:1"), rtt: 7 * time.Millisecond, sndCwnd: 10},
+		// A client socket from an ephemeral port, dropped by max_groups
+		{family: "ipv4", localPort: 41394, remoteIP: net.ParseIP("8.8.8.8"), rtt: time.Millisecond, sndCwnd: 10},
+	}
+
+	collected := collector.groupMetrics(sockets, listenPorts, time.Now())
+
+	type groupKey struct{ name, localPort, remoteSubnet string }
+	values := make(map[groupKey]float64)
+	for _, metric := range collected {
+		values[groupKey{metric.Name, metric.Tags["local_port"], metric.Tags["remote_subnet"]}] = metric.Value
+	}
+
+	tests := []struct {
+		key  groupKey
+		want float64
+	}{
+		{groupKey{"tcp_stats_group_sockets", "22", "192.168.1.0/24"}, 3},
+		{groupKey{"tcp_stats_group_rtt_avg_ms", "22", "192.168.1.0/24"}, 20},
+		{groupKey{"tcp_stats_group_rtt_max_ms", "22", "192.168.1.0/24"}, 30},
+		{groupKey{"tcp_stats_group_cwnd_avg_segments", "22", "192.168.1.0/24"}, 20},
+		{groupKey{"tcp_stats_group_retransmits", "22", "192.168.1.0/24"}, 3},
+		{groupKey{"tcp_stats_group_unacked_segments", "22", "192.168.1.0/24"}, 3},
+		{groupKey{"tcp_stats_group_sockets", "443", "2001:db8::/64"}, 2},
+		{groupKey{"tcp_stats_group_rtt_avg_ms", "443", "2001:db8::/64"}, 6},
+	}
+	for _, tt := range tests {
+		if got, ok := values[tt.key]; !ok || got != tt.want {
+			t.Errorf("%v = %v (present %v), want %v", tt.key, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := values[groupKey{"tcp_stats_group_sockets", "ephemeral", "8.8.8.0/24"}]; ok {
+		t.Error("groupMetrics() kept the smallest group beyond max_groups")
+	}
+	if want := 2 * 6; len(collected) != want {
+		t.Errorf("groupMetrics() = %d metrics, want %d", len(collected), want)
+	}
+}
+
+func TestTCPStatsRemoteSubnet(t *testing.T) {
+	collector := newTestTCPStatsCollector("testdata/proc", 50)
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"10.1.2.3", "10.1.2.0/24"},
+		{"::ffff:10.1.2.3", "10.1.2.0/24"},
+		{"2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+	}
+	for _, tt := range tests {
+		if got := collector.remoteSubnet(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("remoteSubnet(%s) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
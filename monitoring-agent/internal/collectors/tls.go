@@ -0,0 +1,438 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+)
+
+// tlsDefaultPort is used for TLS targets given without a port
+const tlsDefaultPort = "443"
+
+// TLSCollector performs TLS handshakes against configured endpoints and reports
+// handshake latency, negotiated parameters, OCSP stapling, certificate chain
+// expiry and verification results
+type TLSCollector struct {
+	interval time.Duration
+	targets  []metrics.TLSTarget
+	config   metrics.TLSConfig
+	caPools  map[string]*x509.CertPool
+	logger   *logrus.Logger
+}
+
+// tlsResult holds the outcome of a single handshake
+type tlsResult struct {
+	connectTime   time.Duration
+	handshakeTime time.Duration
+	state         tls.ConnectionState
+}
+
+// NewTLSCollector creates a new TLS handshake and certificate collector
+func NewTLSCollector(interval time.Duration, targets []metrics.TLSTarget, config metrics.TLSConfig, logger *logrus.Logger) *TLSCollector {
+	return &TLSCollector{
+		interval: interval,
+		targets:  targets,
+		config:   config,
+		caPools:  make(map[string]*x509.CertPool),
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (tc *TLSCollector) Name() string {
+	return "tls"
+}
+
+// Interval returns the collection interval
+func (tc *TLSCollector) Interval() time.Duration {
+	return tc.interval
+}
+
+// Start initializes the collector
+func (tc *TLSCollector) Start(ctx context.Context) error {
+	tc.logger.WithField("targets", len(tc.targets)).Info("Starting TLS collector")
+
+	if len(tc.targets) == 0 {
+		return fmt.Errorf("no TLS targets configured")
+	}
+
+	// Load custom CA bundles up front so a bad path fails at startup
+	caFiles := []string{tc.config.CAFile}
+	for _, target := range tc.targets {
+		caFiles = append(caFiles, target.CAFile)
+	}
+	for _, caFile := range caFiles {
+		if caFile == "" || tc.caPools[caFile] != nil {
+			continue
+		}
+		pool, err := loadCAPool(caFile)
+		if err != nil {
+			return err
+		}
+		tc.caPools[caFile] = pool
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (tc *TLSCollector) Stop() error {
+	tc.logger.Info("Stopping TLS collector")
+	return nil
+}
+
+// Collect performs a handshake with every target
+func (tc *TLSCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for _, target := range tc.targets {
+		address, serverName := tlsTargetAddress(target)
+		tags := map[string]string{
+			"target":      target.Address,
+			"server_name": serverName,
+		}
+
+		result, err := tc.handshake(ctx, address, serverName, tc.alpn(target))
+		if err != nil {
+			tc.logger.WithFields(logrus.Fields{
+				"target": target.Address,
+				"error":  err,
+			}).Warn("TLS handshake failed")
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "tls_success",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			})
+			continue
+		}
+
+		collectedMetrics = append(collectedMetrics, tc.buildMetrics(target, serverName, result, tags, currentTime)...)
+	}
+
+	tc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected TLS metrics")
+	return collectedMetrics, nil
+}
+
+// handshake connects to the target and completes a TLS handshake without
+// verifying the chain, which is checked separately so expired or untrusted
+// certificates can still be reported
+func (tc *TLSCollector) handshake(ctx context.Context, address, serverName string, alpn []string) (*tlsResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, tc.config.Timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+	connectTime := time.Since(start)
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		NextProtos:         alpn,
+		InsecureSkipVerify: true,
+	})
+
+	start = time.Now()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("handshake with %s failed: %w", address, err)
+	}
+	handshakeTime := time.Since(start)
+
+	return &tlsResult{
+		connectTime:   connectTime,
+		handshakeTime: handshakeTime,
+		state:         tlsConn.ConnectionState(),
+	}, nil
+}
+
+// buildMetrics converts a completed handshake into metrics
+func (tc *TLSCollector) buildMetrics(target metrics.TLSTarget, serverName string, result *tlsResult, tags map[string]string, timestamp time.Time) []metrics.Metric {
+	state := result.state
+	collectedMetrics := []metrics.Metric{
+		{
+			Name:      "tls_success",
+			Value:     1,
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "tls_connect_time_ms",
+			Value:     durationToMs(result.connectTime),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "tls_handshake_time_ms",
+			Value:     durationToMs(result.handshakeTime),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "tls_connection_info",
+			Value:     1,
+			Unit:      "info",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"version":      tls.VersionName(state.Version),
+				"cipher_suite": tls.CipherSuiteName(state.CipherSuite),
+				"alpn":         state.NegotiatedProtocol,
+			}),
+			Type: metrics.MetricTypeGauge,
+		},
+		{
+			Name:      "tls_ocsp_stapled",
+			Value:     boolToFloat(len(state.OCSPResponse) > 0),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	}
+
+	peers := state.PeerCertificates
+	if len(peers) == 0 {
+		return collectedMetrics
+	}
+
+	if len(state.OCSPResponse) > 0 {
+		var issuer *x509.Certificate
+		if len(peers) > 1 {
+			issuer = peers[1]
+		}
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "tls_ocsp_status",
+			Value:     1,
+			Unit:      "info",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"status": ocspStatus(state.OCSPResponse, peers[0], issuer),
+			}),
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	// Verify against the configured roots
+	caName := "system"
+	var roots *x509.CertPool
+	if caFile := tc.caFile(target); caFile != "" {
+		caName = "custom"
+		roots = tc.caPools[caFile]
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range peers[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, verifyErr := peers[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   timestamp,
+	})
+
+	verifyTags := mergeTags(tags, map[string]string{
+		"ca":    caName,
+		"error": "none",
+	})
+	if verifyErr != nil {
+		verifyTags["error"] = verificationErrorCategory(verifyErr)
+		tc.logger.WithFields(logrus.Fields{
+			"target": target.Address,
+			"error":  verifyErr,
+		}).Warn("TLS certificate verification failed")
+	}
+	collectedMetrics = append(collectedMetrics, metrics.Metric{
+		Name:      "tls_verification_ok",
+		Value:     boolToFloat(verifyErr == nil),
+		Unit:      "boolean",
+		Timestamp: timestamp,
+		Tags:      verifyTags,
+		Type:      metrics.MetricTypeGauge,
+	})
+
+	// The presented chain, completed with the trust anchor when verification succeeded
+	chain := peers
+	if len(chains) > 0 {
+		verified := chains[0]
+		if root := verified[len(verified)-1]; !bytes.Equal(root.Raw, peers[len(peers)-1].Raw) {
+			chain = append(append([]*x509.Certificate{}, peers...), root)
+		}
+	}
+
+	minExpiry := math.MaxFloat64
+	for depth, cert := range chain {
+		expiryDays := cert.NotAfter.Sub(timestamp).Hours() / 24
+		if expiryDays < minExpiry {
+			minExpiry = expiryDays
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "tls_certificate_expiry_days",
+			Value:     expiryDays,
+			Unit:      "days",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"depth":   fmt.Sprintf("%d", depth),
+				"role":    certificateRole(depth, cert),
+				"subject": cert.Subject.String(),
+				"issuer":  cert.Issuer.String(),
+				"serial":  cert.SerialNumber.Text(16),
+			}),
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	collectedMetrics = append(collectedMetrics,
+		metrics.Metric{
+			Name:      "tls_certificate_chain_length",
+			Value:     float64(len(peers)),
+			Unit:      "certificates",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "tls_chain_min_expiry_days",
+			Value:     minExpiry,
+			Unit:      "days",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	)
+
+	return collectedMetrics
+}
+
+// alpn returns the protocols offered to a target
+func (tc *TLSCollector) alpn(target metrics.TLSTarget) []string {
+	if len(target.ALPN) > 0 {
+		return target.ALPN
+	}
+	return tc.config.ALPN
+}
+
+// caFile returns the CA bundle a target is verified against, empty for the system roots
+func (tc *TLSCollector) caFile(target metrics.TLSTarget) string {
+	if target.CAFile != "" {
+		return target.CAFile
+	}
+	return tc.config.CAFile
+}
+
+// tlsTargetAddress returns the dial address and server name of a target
+func tlsTargetAddress(target metrics.TLSTarget) (string, string) {
+	address := target.Address
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = strings.Trim(address, "[]")
+		address = net.JoinHostPort(host, tlsDefaultPort)
+	}
+
+	serverName := target.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	return address, serverName
+}
+
+// loadCAPool reads a PEM bundle of trusted certificates
+func loadCAPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle %s: %w", path, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// certificateRole classifies a certificate by its position in the chain
+func certificateRole(depth int, cert *x509.Certificate) string {
+	switch {
+	case depth == 0:
+		return "leaf"
+	case bytes.Equal(cert.RawIssuer, cert.RawSubject):
+		return "root"
+	default:
+		return "intermediate"
+	}
+}
+
+// ocspStatus parses a stapled OCSP response, checking its signature when the issuer is known
+func ocspStatus(response []byte, leaf, issuer *x509.Certificate) string {
+	parsed, err := ocsp.ParseResponseForCert(response, leaf, issuer)
+	if err != nil {
+		return "invalid"
+	}
+
+	switch parsed.Status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// verificationErrorCategory reduces a chain verification error to a stable tag value
+func verificationErrorCategory(err error) string {
+	var invalidErr x509.CertificateInvalidError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+
+	switch {
+	case errors.As(err, &invalidErr):
+		if invalidErr.Reason == x509.Expired {
+			return "expired"
+		}
+		return "invalid"
+	case errors.As(err, &authorityErr):
+		return "unknown_authority"
+	case errors.As(err, &hostnameErr):
+		return "hostname_mismatch"
+	default:
+		return "other"
+	}
+}
+
+// mergeTags returns a copy of base with extra added
+func mergeTags(base, extra map[string]string) map[string]string {
+	tags := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		tags[k] = v
+	}
+	for k, v := range extra {
+		tags[k] = v
+	}
+	return tags
+}
@@ -0,0 +1,328 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// tracerouteHopLine matches a hop line of traceroute or tracert output: the
+// hop number followed by addresses, round-trip times and "*" for lost probes
+var tracerouteHopLine = regexp.MustCompile(`^\s*(\d+)\s+(.*)$`)
+
+// TracerouteCollector records the path to each target using the system
+// traceroute (tracert on Windows)
+type TracerouteCollector struct {
+	interval time.Duration
+	targets  []string
+	config   metrics.TracerouteConfig
+	egress   metrics.EgressConfig
+	command  string
+	paths    []*egressPath
+	logger   *logrus.Logger
+}
+
+// tracerouteHop is one line of traceroute output
+type tracerouteHop struct {
+	number  int
+	address string    // Empty when no probe was answered
+	rtts    []float64 // Milliseconds, one per answered probe
+}
+
+// NewTracerouteCollector creates a new traceroute collector
+func NewTracerouteCollector(interval time.Duration, targets []string, config metrics.TracerouteConfig, egress metrics.EgressConfig, logger *logrus.Logger) *TracerouteCollector {
+	command := "traceroute"
+	if runtime.GOOS == "windows" {
+		command = "tracert"
+	}
+
+	return &TracerouteCollector{
+		interval: interval,
+		targets:  targets,
+		config:   config,
+		egress:   egress,
+		command:  command,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (tc *TracerouteCollector) Name() string {
+	return "traceroute"
+}
+
+// Interval returns the collection interval
+func (tc *TracerouteCollector) Interval() time.Duration {
+	return tc.interval
+}
+
+// Start initializes the collector
+func (tc *TracerouteCollector) Start(ctx context.Context) error {
+	tc.logger.WithField("targets", tc.targets).Info("Starting traceroute collector")
+
+	if len(tc.targets) == 0 {
+		return fmt.Errorf("no traceroute targets configured")
+	}
+	if _, err := exec.LookPath(tc.command); err != nil {
+		return fmt.Errorf("%s command not found: %w", tc.command, err)
+	}
+
+	// Probes are sent directly, so only the source, interface and family apply
+	global := tc.egress
+	global.ProxyURL = ""
+	tc.paths = nil
+	for _, target := range tc.targets {
+		path, err := newEgressPath(global, metrics.EgressConfig{})
+		if err != nil {
+			return fmt.Errorf("invalid egress for traceroute target %s: %w", target, err)
+		}
+		tc.paths = append(tc.paths, path)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (tc *TracerouteCollector) Stop() error {
+	tc.logger.Info("Stopping traceroute collector")
+	return nil
+}
+
+// Collect traces the path to every target once
+func (tc *TracerouteCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for i, target := range tc.targets {
+		targetMetrics, err := tc.traceTarget(ctx, target, tc.paths[i], currentTime)
+		if err != nil {
+			tc.logger.WithFields(logrus.Fields{
+				"target": target,
+				"error":  err,
+			}).Warn("Traceroute failed")
+
+			collectedMetrics = append(collectedMetrics, metrics.Metric{
+				Name:      "traceroute_success",
+				Value:     0,
+				Unit:      "boolean",
+				Timestamp: currentTime,
+				Tags:      tc.paths[i].tagged(map[string]string{"target": target}),
+				Type:      metrics.MetricTypeGauge,
+			})
+			continue
+		}
+		collectedMetrics = append(collectedMetrics, targetMetrics...)
+	}
+
+	tc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected traceroute metrics")
+	return collectedMetrics, nil
+}
+
+// traceTarget runs traceroute against one target and reports the hops
+func (tc *TracerouteCollector) traceTarget(ctx context.Context, target string, path *egressPath, timestamp time.Time) ([]metrics.Metric, error) {
+	targetIP, err := resolveInFamily(ctx, target, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve target %s: %w", target, err)
+	}
+
+	hops, err := tc.executeTraceroute(ctx, targetIP, path)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := path.tagged(map[string]string{
+		"target":    target,
+		"target_ip": targetIP,
+	})
+
+	reached := false
+	unresponsive := 0
+	var collectedMetrics []metrics.Metric
+	for _, hop := range hops {
+		if hop.address == "" {
+			unresponsive++
+			continue
+		}
+		reached = hop.address == targetIP
+		if len(hop.rtts) == 0 {
+			continue
+		}
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "traceroute_hop_rtt_ms",
+			Value:     averageOf(hop.rtts),
+			Unit:      "ms",
+			Timestamp: timestamp,
+			Tags: mergeTags(tags, map[string]string{
+				"hop":         strconv.Itoa(hop.number),
+				"hop_address": hop.address,
+			}),
+			Type: metrics.MetricTypeGauge,
+		})
+	}
+
+	collectedMetrics = append(collectedMetrics,
+		metrics.Metric{
+			Name:      "traceroute_success",
+			Value:     boolToFloat(reached),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "traceroute_hop_count",
+			Value:     float64(len(hops)),
+			Unit:      "hops",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+		metrics.Metric{
+			Name:      "traceroute_unresponsive_hops",
+			Value:     float64(unresponsive),
+			Unit:      "hops",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		},
+	)
+
+	return collectedMetrics, nil
+}
+
+// executeTraceroute runs the traceroute command and parses its hops
+func (tc *TracerouteCollector) executeTraceroute(ctx context.Context, targetIP string, path *egressPath) ([]tracerouteHop, error) {
+	// Every hop may wait the full probe timeout
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(tc.config.MaxHops)*tc.config.Timeout+5*time.Second)
+	defer cancel()
+
+	var args []string
+	if runtime.GOOS == "windows" {
+		args = []string{"-d", "-h", strconv.Itoa(tc.config.MaxHops), "-w", strconv.FormatInt(tc.config.Timeout.Milliseconds(), 10)}
+	} else {
+		waitSeconds := int((tc.config.Timeout + time.Second - 1) / time.Second)
+		args = []string{"-n", "-q", "1", "-m", strconv.Itoa(tc.config.MaxHops), "-w", strconv.Itoa(waitSeconds)}
+	}
+	args = append(args, tc.egressArgs(path)...)
+	args = append(args, targetIP)
+
+	output, err := exec.CommandContext(ctx, tc.command, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s command failed: %w", tc.command, err)
+	}
+
+	hops := parseTracerouteOutput(string(output))
+	if len(hops) == 0 {
+		return nil, fmt.Errorf("%s reported no hops", tc.command)
+	}
+	return hops, nil
+}
+
+// egressArgs returns the traceroute options selecting the source address, interface and IP family
+func (tc *TracerouteCollector) egressArgs(path *egressPath) []string {
+	var args []string
+
+	switch runtime.GOOS {
+	case "linux", "windows":
+		switch path.IPFamily {
+		case "ipv4":
+			args = append(args, "-4")
+		case "ipv6":
+			args = append(args, "-6")
+		}
+		if runtime.GOOS == "windows" {
+			if path.SourceAddress != "" {
+				args = append(args, "-S", path.SourceAddress)
+			}
+			return args
+		}
+		if path.SourceAddress != "" {
+			args = append(args, "-s", path.SourceAddress)
+		}
+		if path.Interface != "" {
+			args = append(args, "-i", path.Interface)
+		}
+
+	default:
+		// BSD traceroute follows the family of the target address
+		if path.SourceAddress != "" {
+			args = append(args, "-s", path.SourceAddress)
+		}
+	}
+
+	return args
+}
+
+// parseTracerouteOutput extracts the hops from traceroute or tracert output
+func parseTracerouteOutput(output string) []tracerouteHop {
+	var hops []tracerouteHop
+	for _, line := range strings.Split(output, "\n") {
+		matches := tracerouteHopLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if matches == nil {
+			continue
+		}
+		number, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		hop := tracerouteHop{number: number}
+		fields := strings.Fields(matches[2])
+		for i, field := range fields {
+			if hop.address == "" && net.ParseIP(strings.Trim(field, "[]()")) != nil {
+				hop.address = strings.Trim(field, "[]()")
+				continue
+			}
+			// Round-trip times are printed as "12.3 ms", or "<1 ms" on Windows
+			if i+1 < len(fields) && fields[i+1] == "ms" {
+				if strings.HasPrefix(field, "<") {
+					hop.rtts = append(hop.rtts, 0.5)
+				} else if rtt, err := strconv.ParseFloat(field, 64); err == nil {
+					hop.rtts = append(hop.rtts, rtt)
+				}
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// resolveInFamily resolves target to an IP address in the egress path's IP
+// family, preferring IPv4 when the family is not restricted
+func resolveInFamily(ctx context.Context, target string, path *egressPath) (string, error) {
+	if net.ParseIP(target) != nil {
+		return target, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, path.network("ip"), target)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("no IP addresses found for %s", target)
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String(), nil
+		}
+	}
+	return ips[0].String(), nil
+}
+
+// averageOf returns the mean of values, which must not be empty
+func averageOf(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,328 @@
+package collectors
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// UDPCollector probes UDP services with protocol-aware requests (NTP, SNMP or
+// a raw payload) and reports reachability and response latency
+type UDPCollector struct {
+	interval time.Duration
+	targets  []metrics.UDPTarget
+	config   metrics.UDPConfig
+	probes   []*udpProbe
+	logger   *logrus.Logger
+}
+
+// udpProbe is a target prepared for probing
+type udpProbe struct {
+	target  metrics.UDPTarget
+	address string
+	payload []byte
+	expect  *regexp.Regexp
+}
+
+// udpResponse is the first matching response to a request
+type udpResponse struct {
+	data       []byte
+	sentAt     time.Time
+	receivedAt time.Time
+}
+
+// NewUDPCollector creates a new UDP service probe collector
+func NewUDPCollector(interval time.Duration, targets []metrics.UDPTarget, config metrics.UDPConfig, logger *logrus.Logger) *UDPCollector {
+	return &UDPCollector{
+		interval: interval,
+		targets:  targets,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Name returns the collector name
+func (uc *UDPCollector) Name() string {
+	return "udp"
+}
+
+// Interval returns the collection interval
+func (uc *UDPCollector) Interval() time.Duration {
+	return uc.interval
+}
+
+// Start initializes the collector
+func (uc *UDPCollector) Start(ctx context.Context) error {
+	uc.logger.WithField("targets", len(uc.targets)).Info("Starting UDP collector")
+
+	if len(uc.targets) == 0 {
+		return fmt.Errorf("no UDP targets configured")
+	}
+
+	uc.probes = nil
+	for _, target := range uc.targets {
+		probe, err := newUDPProbe(target)
+		if err != nil {
+			return fmt.Errorf("invalid UDP target %s: %w", target.Address, err)
+		}
+		uc.probes = append(uc.probes, probe)
+	}
+
+	return nil
+}
+
+// Stop shuts down the collector
+func (uc *UDPCollector) Stop() error {
+	uc.logger.Info("Stopping UDP collector")
+	return nil
+}
+
+// Collect probes every target
+func (uc *UDPCollector) Collect(ctx context.Context) ([]metrics.Metric, error) {
+	currentTime := time.Now()
+	var collectedMetrics []metrics.Metric
+
+	for _, probe := range uc.probes {
+		tags := map[string]string{
+			"target":   probe.target.Address,
+			"protocol": probe.target.Protocol,
+		}
+
+		probeMetrics, err := uc.runProbe(ctx, probe, tags, currentTime)
+		if err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"target":   probe.target.Address,
+				"protocol": probe.target.Protocol,
+				"error":    err,
+			}).Warn("UDP probe failed")
+		}
+
+		collectedMetrics = append(collectedMetrics, metrics.Metric{
+			Name:      "udp_success",
+			Value:     boolToFloat(err == nil),
+			Unit:      "boolean",
+			Timestamp: currentTime,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+		collectedMetrics = append(collectedMetrics, probeMetrics...)
+	}
+
+	uc.logger.WithField("metrics_count", len(collectedMetrics)).Debug("Collected UDP metrics")
+	return collectedMetrics, nil
+}
+
+// runProbe sends the protocol request to a target and converts the response
+// into metrics. Metrics gathered before a failure, such as the latency of a
+// response that did not match, are returned together with the error.
+func (uc *UDPCollector) runProbe(ctx context.Context, probe *udpProbe, tags map[string]string, timestamp time.Time) ([]metrics.Metric, error) {
+	switch probe.target.Protocol {
+	case "ntp":
+		result, err := sntpQuery(ctx, probe.address, uc.config.Timeout, uc.config.Retries)
+		if err != nil {
+			return nil, err
+		}
+		return []metrics.Metric{
+			uc.responseTimeMetric(result.delay, tags, timestamp),
+			{
+				Name:      "udp_ntp_offset_ms",
+				Value:     durationToMs(result.offset),
+				Unit:      "ms",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+			{
+				Name:      "udp_ntp_stratum",
+				Value:     float64(result.stratum),
+				Unit:      "stratum",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}, nil
+
+	case "snmp":
+		requestID := rand.Int31()
+		request := snmpGet(probe.target.Community, requestID, sysUpTimeOID)
+		response, err := udpExchange(ctx, probe.address, uc.config.Timeout, uc.config.Retries,
+			func(time.Time) []byte { return request }, snmpMatcher(requestID))
+		if err != nil {
+			return nil, err
+		}
+		probeMetrics := []metrics.Metric{
+			uc.responseTimeMetric(response.receivedAt.Sub(response.sentAt), tags, timestamp),
+		}
+
+		value, err := parseSNMPResponse(response.data, requestID)
+		if err != nil {
+			return probeMetrics, err
+		}
+		if value.tag != berTimeTicks {
+			return probeMetrics, fmt.Errorf("unexpected sysUpTime type 0x%02x", value.tag)
+		}
+		ticks, err := berDecodeUnsigned(value.content)
+		if err != nil {
+			return probeMetrics, err
+		}
+		return append(probeMetrics, metrics.Metric{
+			Name:      "udp_snmp_sysuptime_seconds",
+			Value:     float64(ticks) / 100,
+			Unit:      "seconds",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		}), nil
+
+	default:
+		response, err := udpExchange(ctx, probe.address, uc.config.Timeout, uc.config.Retries,
+			func(time.Time) []byte { return probe.payload }, nil)
+		if err != nil {
+			return nil, err
+		}
+		probeMetrics := []metrics.Metric{
+			uc.responseTimeMetric(response.receivedAt.Sub(response.sentAt), tags, timestamp),
+			{
+				Name:      "udp_response_bytes",
+				Value:     float64(len(response.data)),
+				Unit:      "bytes",
+				Timestamp: timestamp,
+				Tags:      tags,
+				Type:      metrics.MetricTypeGauge,
+			},
+		}
+
+		if probe.expect == nil {
+			return probeMetrics, nil
+		}
+		matched := probe.expect.Match(response.data)
+		probeMetrics = append(probeMetrics, metrics.Metric{
+			Name:      "udp_response_match",
+			Value:     boolToFloat(matched),
+			Unit:      "boolean",
+			Timestamp: timestamp,
+			Tags:      tags,
+			Type:      metrics.MetricTypeGauge,
+		})
+		if !matched {
+			return probeMetrics, fmt.Errorf("response does not match %q", probe.target.ExpectRegex)
+		}
+		return probeMetrics, nil
+	}
+}
+
+// responseTimeMetric reports the time between sending a request and receiving its response
+func (uc *UDPCollector) responseTimeMetric(rtt time.Duration, tags map[string]string, timestamp time.Time) metrics.Metric {
+	return metrics.Metric{
+		Name:      "udp_response_time_ms",
+		Value:     durationToMs(rtt),
+		Unit:      "ms",
+		Timestamp: timestamp,
+		Tags:      tags,
+		Type:      metrics.MetricTypeGauge,
+	}
+}
+
+// newUDPProbe resolves protocol defaults for a target
+func newUDPProbe(target metrics.UDPTarget) (*udpProbe, error) {
+	probe := &udpProbe{
+		target:  target,
+		address: target.Address,
+	}
+
+	switch target.Protocol {
+	case "ntp":
+	case "snmp":
+		if _, _, err := net.SplitHostPort(probe.address); err != nil {
+			probe.address = net.JoinHostPort(probe.address, snmpDefaultPort)
+		}
+	case "raw":
+		probe.payload = []byte(target.Payload)
+		if target.PayloadHex != "" {
+			payload, err := hex.DecodeString(target.PayloadHex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid payload_hex: %w", err)
+			}
+			probe.payload = payload
+		}
+		if target.ExpectRegex != "" {
+			expect, err := regexp.Compile(target.ExpectRegex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expect_regex: %w", err)
+			}
+			probe.expect = expect
+		}
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", target.Protocol)
+	}
+
+	return probe, nil
+}
+
+// udpExchange sends a request and waits for a response accepted by match,
+// resending a freshly built request after each timeout up to retries times.
+// A nil match accepts any response from the target.
+func udpExchange(ctx context.Context, address string, timeout time.Duration, retries int, build func(sentAt time.Time) []byte, match func(request, response []byte) bool) (*udpResponse, error) {
+	remoteAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve target %s: %w", address, err)
+	}
+
+	conn, err := net.DialUDP("udp", nil, remoteAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open UDP socket: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock reads when the collection is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	buf := make([]byte, 65535)
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		sentAt := time.Now()
+		request := build(sentAt)
+		if _, err := conn.Write(request); err != nil {
+			// ICMP port unreachable from a previous attempt surfaces here
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		if err := conn.SetReadDeadline(sentAt.Add(timeout)); err != nil {
+			return nil, fmt.Errorf("failed to set read deadline: %w", err)
+		}
+
+		for {
+			n, err := conn.Read(buf)
+			receivedAt := time.Now()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response: %w", err)
+			}
+
+			response := buf[:n]
+			if match != nil && !match(request, response) {
+				continue
+			}
+			return &udpResponse{
+				data:       append([]byte(nil), response...),
+				sentAt:     sentAt,
+				receivedAt: receivedAt,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no response from %s after %d attempts", address, retries+1)
+}
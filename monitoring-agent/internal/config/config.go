@@ -1,21 +1,42 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
 	"github.com/spf13/viper"
 	"github.com/google/uuid"
+	"github.com/mitchellh/mapstructure"
 )
 
+// defaultNetstatProtocols are the protocol counter sections reported by the netstat collector
+var defaultNetstatProtocols = []string{"ip", "icmp", "tcp", "udp", "udplite", "tcpext", "ip6", "icmp6", "udp6", "udplite6"}
+
 // Manager handles configuration loading and validation
 type Manager struct {
 	config *metrics.AgentConfig
 	viper  *viper.Viper
+
+	// secrets are the ${env:} and ${file:} references resolved for config
+	secretsMutex       sync.RWMutex
+	secrets            *secretSet
+	secretFilesChanged chan struct{}
+
+	// pendingSecrets were resolved for pendingConfig, returned by Reload but
+	// not yet committed
+	pendingConfig  *metrics.AgentConfig
+	pendingSecrets *secretSet
 }
 
 // NewManager creates a new configuration manager
@@ -36,44 +57,134 @@ func NewManager() *Manager {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	
 	return &Manager{
-		viper: v,
+		viper:              v,
+		secretFilesChanged: make(chan struct{}, 1),
 	}
 }
 
+// SetConfigFile reads the configuration from path instead of searching the default locations
+func (m *Manager) SetConfigFile(path string) {
+	m.viper.SetConfigFile(path)
+}
+
+// ConfigFileUsed returns the path of the configuration file that was read, if any
+func (m *Manager) ConfigFileUsed() string {
+	return m.viper.ConfigFileUsed()
+}
+
 // Load reads and validates the configuration
 func (m *Manager) Load() error {
 	// Set default values
 	m.setDefaults()
 	
+	config, secrets, err := m.read()
+	if err != nil {
+		return err
+	}
+	
+	m.config = config
+	m.setSecrets(secrets)
+	return nil
+}
+
+// Reload re-reads the configuration file and returns the validated result
+// without making it current; the caller commits it with Commit once it has
+// been applied, so a rejected configuration leaves the current one in place
+func (m *Manager) Reload() (*metrics.AgentConfig, error) {
+	config, secrets, err := m.read()
+	if err != nil {
+		m.stageSecrets(nil, nil)
+		return nil, err
+	}
+	
+	m.stageSecrets(config, secrets)
+	return config, nil
+}
+
+// Commit makes a configuration returned by Reload the current one, along
+// with the secrets resolved for it
+func (m *Manager) Commit(config *metrics.AgentConfig) {
+	m.config = config
+	m.commitSecrets(config)
+}
+
+// Discard drops a configuration returned by Reload that could not be
+// applied, so that its secrets are no longer redacted
+func (m *Manager) Discard(config *metrics.AgentConfig) {
+	m.secretsMutex.Lock()
+	defer m.secretsMutex.Unlock()
+	if m.pendingConfig == config {
+		m.pendingConfig, m.pendingSecrets = nil, nil
+	}
+}
+
+// read loads the config file, environment and defaults into a validated config
+// and returns the secrets resolved for it
+func (m *Manager) read() (*metrics.AgentConfig, *secretSet, error) {
 	// Try to read config file
 	if err := m.viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return fmt.Errorf("failed to read config file: %w", err)
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 		}
 		// Config file not found, use defaults and environment variables
 	}
 	
-	// Unmarshal into config struct
-	config := &metrics.AgentConfig{}
-	if err := m.viper.Unmarshal(config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	config, err := m.decode()
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	// Resolve ${env:NAME} and ${file:/path} secret references
+	secrets, problems := resolveSecrets(config)
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("failed to resolve secret in %s: %w", problems[0].path, problems[0].err)
 	}
 	
 	// Validate and auto-detect missing values
 	if err := m.validateAndEnrich(config); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+		return nil, nil, fmt.Errorf("config validation failed: %w", secretError(secrets, err))
 	}
 	
-	m.config = config
+	return config, secrets, nil
+}
+
+// ValidateTargets validates the targets, egress and DNS settings of a config
+// that was changed after loading, such as the probe command's one-off target
+func (m *Manager) ValidateTargets(config *metrics.AgentConfig) error {
+	if err := m.validateCustomTargets(&config.CustomTargets); err != nil {
+		return err
+	}
+	if err := m.validateEgress(&config.Egress); err != nil {
+		return fmt.Errorf("invalid egress configuration: %w", err)
+	}
+	if err := m.validateDNS(&config.DNS); err != nil {
+		return fmt.Errorf("invalid dns configuration: %w", err)
+	}
 	return nil
 }
 
+// decode unmarshals the settings read by viper into a config struct without
+// validating them. Keys are matched against the yaml tags, the same names
+// the configuration file and SaveConfig use. On error the returned config
+// holds every setting that could be decoded.
+func (m *Manager) decode() (*metrics.AgentConfig, error) {
+	config := &metrics.AgentConfig{}
+	if err := m.viper.Unmarshal(config, func(decoderConfig *mapstructure.DecoderConfig) {
+		decoderConfig.TagName = "yaml"
+	}); err != nil {
+		return config, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return config, nil
+}
+
 // GetConfig returns the loaded configuration
 func (m *Manager) GetConfig() *metrics.AgentConfig {
 	return m.config
 }
 
-// SaveConfig writes the current configuration to file
+// SaveConfig writes the current configuration to file. The settings are
+// written as read, so secret references are saved rather than the values
+// they resolve to.
 func (m *Manager) SaveConfig(filePath string) error {
 	if m.config == nil {
 		return fmt.Errorf("no configuration loaded")
@@ -102,8 +213,18 @@ func (m *Manager) setDefaults() {
 	m.viper.SetDefault("batch_size", 100)
 	m.viper.SetDefault("log_level", "info")
 	
+	// Backend authentication and TLS
+	m.viper.SetDefault("backend.token", "")
+	m.viper.SetDefault("backend.ca_file", "")
+	m.viper.SetDefault("backend.cert_file", "")
+	m.viper.SetDefault("backend.key_file", "")
+	m.viper.SetDefault("backend.server_name", "")
+	m.viper.SetDefault("backend.min_tls_version", "1.2")
+	m.viper.SetDefault("backend.pinned_sha256", []string{})
+	m.viper.SetDefault("backend.insecure_skip_verify", false)
+	
 	// Default collectors
-	m.viper.SetDefault("collectors", []string{"network_interface", "ping", "system"})
+	m.viper.SetDefault("collectors", []string{"network_interface", "ping"})
 	
 	// Location defaults
 	m.viper.SetDefault("location.provider", "auto-detect")
@@ -135,6 +256,88 @@ func (m *Manager) setDefaults() {
 		},
 	}
 	m.viper.SetDefault("custom_targets.http_targets", httpTargets)
+	
+	// STAMP measurements
+	m.viper.SetDefault("stamp.packet_count", 10)
+	m.viper.SetDefault("stamp.packet_interval", "100ms")
+	m.viper.SetDefault("stamp.timeout", "2s")
+	m.viper.SetDefault("stamp.clock_synced", false)
+	m.viper.SetDefault("stamp.reflector_address", ":862")
+	
+	// TCP connection statistics
+	m.viper.SetDefault("tcp_stats.remote_prefix_v4", 24)
+	m.viper.SetDefault("tcp_stats.remote_prefix_v6", 64)
+	m.viper.SetDefault("tcp_stats.max_groups", 50)
+	
+	// Kernel protocol counters
+	m.viper.SetDefault("proc_root", "/proc")
+	m.viper.SetDefault("netstat.protocols", defaultNetstatProtocols)
+	
+	// Connection tracking
+	m.viper.SetDefault("conntrack.top_flows", 0)
+	
+	// Softnet and NIC queue statistics
+	m.viper.SetDefault("softnet.interfaces", []string{})
+	m.viper.SetDefault("softnet.driver_stats", true)
+	
+	// Routing and neighbor tables
+	m.viper.SetDefault("routes.max_events", 100)
+	
+	// TLS handshake and certificate monitoring
+	m.viper.SetDefault("custom_targets.tls_targets", []map[string]interface{}{})
+	m.viper.SetDefault("tls.timeout", "10s")
+	m.viper.SetDefault("tls.ca_file", "")
+	m.viper.SetDefault("tls.alpn", []string{"h2", "http/1.1"})
+	
+	// UDP service probes
+	m.viper.SetDefault("custom_targets.udp_targets", []map[string]interface{}{})
+	m.viper.SetDefault("udp.timeout", "2s")
+	m.viper.SetDefault("udp.retries", 1)
+	
+	// NTP clock offset and kernel sync state
+	m.viper.SetDefault("ntp.servers", []string{"pool.ntp.org"})
+	m.viper.SetDefault("ntp.timeout", "2s")
+	m.viper.SetDefault("ntp.retries", 1)
+	
+	// gRPC health and unary method probes
+	m.viper.SetDefault("custom_targets.grpc_targets", []map[string]interface{}{})
+	m.viper.SetDefault("grpc.timeout", "5s")
+	
+	// DNS resolution probes against custom_targets.dns_servers
+	m.viper.SetDefault("dns.names", []string{"google.com"})
+	m.viper.SetDefault("dns.record_type", "A")
+	m.viper.SetDefault("dns.timeout", "2s")
+	
+	// Path discovery with the system traceroute
+	m.viper.SetDefault("custom_targets.traceroute_targets", []string{})
+	m.viper.SetDefault("traceroute.max_hops", 30)
+	m.viper.SetDefault("traceroute.timeout", "2s")
+	
+	// Scripted HTTP transactions
+	m.viper.SetDefault("custom_targets.http_transactions", []map[string]interface{}{})
+	
+	// TCP connect probes and egress path selection
+	m.viper.SetDefault("custom_targets.tcp_targets", []map[string]interface{}{})
+	m.viper.SetDefault("egress.proxy_url", "")
+	m.viper.SetDefault("egress.source_address", "")
+	m.viper.SetDefault("egress.interface", "")
+	m.viper.SetDefault("egress.ip_family", "")
+	
+	// Local control API
+	m.viper.SetDefault("control.enabled", true)
+	m.viper.SetDefault("control.address", defaultControlAddress())
+	m.viper.SetDefault("control.token", "")
+	
+	// Liveness and readiness probes
+	m.viper.SetDefault("health.address", "")
+	m.viper.SetDefault("health.max_collect_age", "0s")
+	m.viper.SetDefault("health.max_send_age", "0s")
+	
+	// Agent self-telemetry
+	m.viper.SetDefault("self_telemetry", true)
+	
+	// Reload on config file changes (SIGHUP always reloads)
+	m.viper.SetDefault("watch_config", false)
 }
 
 // validateAndEnrich validates the configuration and enriches it with auto-detected values
@@ -149,6 +352,11 @@ func (m *Manager) validateAndEnrich(config *metrics.AgentConfig) error {
 		return fmt.Errorf("backend_url is required")
 	}
 	
+	// Validate backend authentication and TLS
+	if err := m.validateBackend(config.BackendURL, &config.Backend); err != nil {
+		return fmt.Errorf("invalid backend configuration: %w", err)
+	}
+	
 	// Validate collect interval
 	if config.CollectInterval == 0 {
 		config.CollectInterval = 30 * time.Second
@@ -166,20 +374,25 @@ func (m *Manager) validateAndEnrich(config *metrics.AgentConfig) error {
 	}
 	
 	// Validate log level
-	validLogLevels := map[string]bool{
-		"debug": true, "info": true, "warn": true, "error": true, "fatal": true,
-	}
-	if !validLogLevels[strings.ToLower(config.LogLevel)] {
+	if !validLogLevel(config.LogLevel) {
 		config.LogLevel = "info"
 	}
 	
+	// Validate proc root
+	if config.ProcRoot == "" {
+		config.ProcRoot = "/proc"
+	}
+	
 	// Validate collectors
 	if len(config.Collectors) == 0 {
 		config.Collectors = []string{"network_interface", "ping"}
 	}
 	
-	// Auto-detect cloud provider and location
-	if err := m.autoDetectLocation(&config.Location); err != nil {
+	// Auto-detect cloud provider and location. A reload keeps the location
+	// detected at startup rather than querying the metadata servers again.
+	if m.config != nil && isAutoDetect(config.Location.Provider) {
+		config.Location = m.config.Location
+	} else if err := m.autoDetectLocation(&config.Location); err != nil {
 		// Log error but don't fail - use defaults
 		fmt.Printf("Warning: Failed to auto-detect location: %v\n", err)
 	}
@@ -189,12 +402,185 @@ func (m *Manager) validateAndEnrich(config *metrics.AgentConfig) error {
 		return fmt.Errorf("invalid custom targets: %w", err)
 	}
 	
+	// Validate STAMP settings
+	if err := m.validateSTAMP(&config.STAMP); err != nil {
+		return fmt.Errorf("invalid stamp configuration: %w", err)
+	}
+	
+	// Validate TCP statistics settings
+	if err := m.validateTCPStats(&config.TCPStats); err != nil {
+		return fmt.Errorf("invalid tcp_stats configuration: %w", err)
+	}
+	
+	// Validate kernel protocol counter sections
+	if len(config.Netstat.Protocols) == 0 {
+		config.Netstat.Protocols = defaultNetstatProtocols
+	}
+	
+	// Validate conntrack settings
+	if config.Conntrack.TopFlows < 0 {
+		return fmt.Errorf("conntrack.top_flows cannot be negative")
+	}
+	
+	// Validate routes settings
+	if config.Routes.MaxEvents <= 0 {
+		config.Routes.MaxEvents = 100
+	}
+	
+	// Validate TLS settings
+	if config.TLS.Timeout <= 0 {
+		config.TLS.Timeout = 10 * time.Second
+	}
+	
+	// Validate UDP settings
+	if config.UDP.Timeout <= 0 {
+		config.UDP.Timeout = 2 * time.Second
+	}
+	if config.UDP.Retries < 0 {
+		return fmt.Errorf("udp.retries cannot be negative")
+	}
+	
+	// Validate NTP settings
+	if config.NTP.Timeout <= 0 {
+		config.NTP.Timeout = 2 * time.Second
+	}
+	if config.NTP.Retries < 0 {
+		return fmt.Errorf("ntp.retries cannot be negative")
+	}
+	
+	// Validate gRPC settings
+	if config.GRPC.Timeout <= 0 {
+		config.GRPC.Timeout = 5 * time.Second
+	}
+	
+	// Validate DNS settings
+	if err := m.validateDNS(&config.DNS); err != nil {
+		return fmt.Errorf("invalid dns configuration: %w", err)
+	}
+	
+	// Validate traceroute settings
+	if config.Traceroute.MaxHops == 0 {
+		config.Traceroute.MaxHops = 30
+	}
+	if config.Traceroute.MaxHops < 1 || config.Traceroute.MaxHops > 255 {
+		return fmt.Errorf("traceroute.max_hops must be between 1 and 255")
+	}
+	if config.Traceroute.Timeout <= 0 {
+		config.Traceroute.Timeout = 2 * time.Second
+	}
+	
+	// Validate egress settings
+	if err := m.validateEgress(&config.Egress); err != nil {
+		return fmt.Errorf("invalid egress configuration: %w", err)
+	}
+	if config.Egress.ProxyURL == "direct" {
+		return fmt.Errorf("invalid egress configuration: proxy_url \"direct\" only applies to targets")
+	}
+	
+	// Validate control API settings
+	if config.Control.Address == "" {
+		config.Control.Address = defaultControlAddress()
+	}
+	if err := m.validateControlAddress(config.Control.Address); err != nil {
+		return fmt.Errorf("invalid control configuration: %w", err)
+	}
+	if !strings.HasPrefix(config.Control.Address, "unix:") && config.Control.Token == "" {
+		return fmt.Errorf("invalid control configuration: token is required with a TCP address")
+	}
+	
+	// Validate health probe settings; thresholds default to a few missed cycles
+	if config.Health.Address != "" {
+		if _, _, err := net.SplitHostPort(config.Health.Address); err != nil {
+			return fmt.Errorf("invalid health configuration: address must be host:port: %w", err)
+		}
+	}
+	if config.Health.MaxCollectAge < 0 || config.Health.MaxSendAge < 0 {
+		return fmt.Errorf("invalid health configuration: thresholds cannot be negative")
+	}
+	if config.Health.MaxCollectAge == 0 {
+		config.Health.MaxCollectAge = 3 * config.CollectInterval
+	}
+	if config.Health.MaxSendAge == 0 {
+		// Partial batches are flushed every 30 seconds
+		config.Health.MaxSendAge = 3 * max(config.CollectInterval, 30*time.Second)
+	}
+	
+	return nil
+}
+
+// validateBackend checks the backend credentials and TLS settings against backendURL
+func (m *Manager) validateBackend(backendURL string, backend *metrics.BackendConfig) error {
+	if (backend.CertFile == "") != (backend.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if backend.MinTLSVersion == "" {
+		backend.MinTLSVersion = "1.2"
+	}
+	if backend.MinTLSVersion != "1.2" && backend.MinTLSVersion != "1.3" {
+		return fmt.Errorf("min_tls_version must be 1.2 or 1.3")
+	}
+	for _, pin := range backend.PinnedSHA256 {
+		if _, err := metrics.ParsePin(pin); err != nil {
+			return err
+		}
+	}
+	if strings.ContainsAny(backend.Token, "\r\n") {
+		return fmt.Errorf("token cannot contain line breaks")
+	}
+	
+	// TLS settings would be silently ignored on a plaintext connection
+	if u, err := url.Parse(backendURL); err == nil && (u.Scheme == "ws" || u.Scheme == "http") {
+		if backend.CAFile != "" || backend.CertFile != "" || backend.ServerName != "" || len(backend.PinnedSHA256) > 0 || backend.InsecureSkipVerify {
+			return fmt.Errorf("ca_file, cert_file, server_name, pinned_sha256 and insecure_skip_verify require a wss:// or https:// backend_url")
+		}
+	}
+	
+	return nil
+}
+
+// defaultControlAddress returns the Unix socket the control API listens on
+// unless configured; the socket is only accessible to the agent's user
+func defaultControlAddress() string {
+	switch runtime.GOOS {
+	case "windows":
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return "unix:" + filepath.Join(programData, "network-monitor", "agent.sock")
+	case "linux":
+		return "unix:/run/network-monitor/agent.sock"
+	default:
+		return "unix:/var/run/network-monitor/agent.sock"
+	}
+}
+
+// validateControlAddress checks that the control API is only reachable locally
+func (m *Manager) validateControlAddress(address string) error {
+	if path, found := strings.CutPrefix(address, "unix:"); found {
+		if strings.TrimPrefix(path, "//") == "" {
+			return fmt.Errorf("address %q has no socket path", address)
+		}
+		return nil
+	}
+	
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("address must be host:port or unix:/path: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("address %q must be a loopback address", address)
+	}
+	
 	return nil
 }
 
 // autoDetectLocation attempts to auto-detect cloud provider and location
 func (m *Manager) autoDetectLocation(location *metrics.CloudLocation) error {
-	if location.Provider == "" || location.Provider == "auto-detect" {
+	if isAutoDetect(location.Provider) {
 		provider := m.detectCloudProvider()
 		location.Provider = provider
 		
@@ -228,6 +614,11 @@ func (m *Manager) autoDetectLocation(location *metrics.CloudLocation) error {
 	return nil
 }
 
+// isAutoDetect reports whether provider asks for the location to be detected
+func isAutoDetect(provider string) bool {
+	return provider == "" || provider == "auto-detect"
+}
+
 // detectCloudProvider attempts to detect the cloud provider
 func (m *Manager) detectCloudProvider() string {
 	// Check for GCP metadata server
@@ -302,6 +693,82 @@ func (m *Manager) validateCustomTargets(targets *metrics.CustomTargets) error {
 		if target.Timeout == 0 {
 			target.Timeout = 10 * time.Second
 		}
+		if err := m.validateHTTPAssertions(&target.Assert); err != nil {
+			return fmt.Errorf("invalid HTTP target %q: %w", target.URL, err)
+		}
+		if err := m.validateEgress(&target.Egress); err != nil {
+			return fmt.Errorf("invalid HTTP target %q: %w", target.URL, err)
+		}
+	}
+	
+	// Validate TCP targets
+	for i := range targets.TCPTargets {
+		target := &targets.TCPTargets[i]
+		if _, _, err := net.SplitHostPort(target.Address); err != nil {
+			return fmt.Errorf("invalid TCP target %q: address must be host:port", target.Address)
+		}
+		if target.Timeout == 0 {
+			target.Timeout = 5 * time.Second
+		}
+		if err := m.validateEgress(&target.Egress); err != nil {
+			return fmt.Errorf("invalid TCP target %q: %w", target.Address, err)
+		}
+	}
+	
+	// Validate ping egress overrides; ICMP cannot be proxied
+	pingTargets := make(map[string]bool)
+	for _, target := range targets.PingTargets {
+		pingTargets[target] = true
+	}
+	for i := range targets.PingEgress {
+		override := &targets.PingEgress[i]
+		if !pingTargets[override.Target] {
+			return fmt.Errorf("ping egress target %q is not in ping_targets", override.Target)
+		}
+		if err := m.validateEgress(&override.Egress); err != nil {
+			return fmt.Errorf("invalid ping egress for %q: %w", override.Target, err)
+		}
+		if override.Egress.ProxyURL != "" && override.Egress.ProxyURL != "direct" {
+			return fmt.Errorf("invalid ping egress for %q: proxy_url is not supported for ping", override.Target)
+		}
+	}
+	
+	// Validate TLS targets
+	for _, target := range targets.TLSTargets {
+		if target.Address == "" {
+			return fmt.Errorf("TLS target address cannot be empty")
+		}
+	}
+	
+	// Validate UDP targets
+	for i := range targets.UDPTargets {
+		if err := m.validateUDPTarget(&targets.UDPTargets[i]); err != nil {
+			return fmt.Errorf("invalid UDP target %q: %w", targets.UDPTargets[i].Address, err)
+		}
+	}
+	
+	// Validate gRPC targets
+	for i := range targets.GRPCTargets {
+		if err := m.validateGRPCTarget(&targets.GRPCTargets[i]); err != nil {
+			return fmt.Errorf("invalid gRPC target %q: %w", targets.GRPCTargets[i].Address, err)
+		}
+	}
+	
+	// Validate HTTP transactions
+	names := make(map[string]bool)
+	for i := range targets.HTTPTransactions {
+		transaction := &targets.HTTPTransactions[i]
+		if transaction.Name == "" {
+			return fmt.Errorf("HTTP transaction name cannot be empty")
+		}
+		if names[transaction.Name] {
+			return fmt.Errorf("duplicate HTTP transaction name %q", transaction.Name)
+		}
+		names[transaction.Name] = true
+		
+		if err := m.validateHTTPTransaction(transaction); err != nil {
+			return fmt.Errorf("invalid HTTP transaction %q: %w", transaction.Name, err)
+		}
 	}
 	
 	// Validate TCP ports
@@ -314,6 +781,257 @@ func (m *Manager) validateCustomTargets(targets *metrics.CustomTargets) error {
 		targets.DNSServers = []string{"8.8.8.8", "1.1.1.1"}
 	}
 	
+	// Validate traceroute targets
+	for _, target := range targets.TracerouteTargets {
+		if target == "" {
+			return fmt.Errorf("traceroute target cannot be empty")
+		}
+	}
+	
+	return nil
+}
+
+// dnsRecordTypes are the record types the dns collector can query
+var dnsRecordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"TXT":   true,
+}
+
+// validateDNS validates the dns collector settings and normalizes the record type
+func (m *Manager) validateDNS(dns *metrics.DNSConfig) error {
+	for _, name := range dns.Names {
+		if name == "" {
+			return fmt.Errorf("names cannot contain an empty name")
+		}
+	}
+	
+	dns.RecordType = strings.ToUpper(dns.RecordType)
+	if dns.RecordType == "" {
+		dns.RecordType = "A"
+	}
+	if !dnsRecordTypes[dns.RecordType] {
+		return fmt.Errorf("unsupported record_type %q (A, AAAA, CNAME, MX, NS or TXT)", dns.RecordType)
+	}
+	
+	if dns.Timeout <= 0 {
+		dns.Timeout = 2 * time.Second
+	}
+	return nil
+}
+
+// validateUDPTarget validates a UDP probe and fills in protocol defaults
+func (m *Manager) validateUDPTarget(target *metrics.UDPTarget) error {
+	if target.Address == "" {
+		return fmt.Errorf("address cannot be empty")
+	}
+	
+	switch target.Protocol {
+	case "ntp":
+	case "snmp":
+		if target.Community == "" {
+			target.Community = "public"
+		}
+	case "raw":
+		if _, _, err := net.SplitHostPort(target.Address); err != nil {
+			return fmt.Errorf("raw probes need a host:port address")
+		}
+		if target.Payload == "" && target.PayloadHex == "" {
+			return fmt.Errorf("raw probes need a payload or payload_hex")
+		}
+		if target.Payload != "" && target.PayloadHex != "" {
+			return fmt.Errorf("payload and payload_hex are mutually exclusive")
+		}
+		if _, err := hex.DecodeString(target.PayloadHex); err != nil {
+			return fmt.Errorf("invalid payload_hex: %w", err)
+		}
+		if _, err := regexp.Compile(target.ExpectRegex); err != nil {
+			return fmt.Errorf("invalid expect_regex: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q, expected ntp, snmp or raw", target.Protocol)
+	}
+	
+	return nil
+}
+
+// validateGRPCTarget validates a gRPC probe and its unary methods
+func (m *Manager) validateGRPCTarget(target *metrics.GRPCTarget) error {
+	if _, _, err := net.SplitHostPort(target.Address); err != nil {
+		return fmt.Errorf("address must be host:port")
+	}
+	if (target.CertFile == "") != (target.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if !target.TLS && (target.CAFile != "" || target.CertFile != "" || target.ServerName != "") {
+		return fmt.Errorf("ca_file, cert_file and server_name require tls")
+	}
+	
+	for i := range target.Methods {
+		method := &target.Methods[i]
+		method.Method = strings.TrimPrefix(method.Method, "/")
+		if service, name, found := strings.Cut(method.Method, "/"); !found || service == "" || name == "" {
+			return fmt.Errorf("method %q must be package.Service/Method", method.Method)
+		}
+		if method.Request == "" {
+			method.Request = "{}"
+		}
+	}
+	
+	return nil
+}
+
+// validateHTTPTransaction validates the steps of a scripted HTTP transaction
+func (m *Manager) validateHTTPTransaction(transaction *metrics.HTTPTransaction) error {
+	if len(transaction.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	
+	for i := range transaction.Steps {
+		step := &transaction.Steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step%d", i+1)
+		}
+		if step.URL == "" {
+			return fmt.Errorf("step %q: url cannot be empty", step.Name)
+		}
+		if step.Method == "" {
+			step.Method = "GET"
+		}
+		if step.Timeout == 0 {
+			step.Timeout = 10 * time.Second
+		}
+		
+		for _, extract := range step.Extract {
+			if extract.Variable == "" || extract.Path == "" {
+				return fmt.Errorf("step %q: extract needs a variable and a path", step.Name)
+			}
+			switch extract.From {
+			case "json", "header":
+			case "regex":
+				if _, err := regexp.Compile(extract.Path); err != nil {
+					return fmt.Errorf("step %q: invalid extract regex: %w", step.Name, err)
+				}
+			default:
+				return fmt.Errorf("step %q: extract from must be json, regex or header", step.Name)
+			}
+		}
+		
+		if err := m.validateHTTPAssertions(&step.Assert); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+	}
+	
+	return nil
+}
+
+// validateHTTPAssertions validates response assertions and normalizes fingerprints
+func (m *Manager) validateHTTPAssertions(assert *metrics.HTTPAssertions) error {
+	if _, err := regexp.Compile(assert.BodyRegex); err != nil {
+		return fmt.Errorf("invalid body_regex: %w", err)
+	}
+	if assert.MaxBodyBytes < 0 {
+		return fmt.Errorf("max_body_bytes cannot be negative")
+	}
+	// Responses are read up to 10 MiB, so a larger limit could not be checked
+	if assert.MaxBodyBytes > 10<<20 {
+		return fmt.Errorf("max_body_bytes cannot exceed %d, the most of a response that is read", 10<<20)
+	}
+	
+	for _, hash := range []*string{&assert.BodySHA256, &assert.CertSHA256} {
+		if *hash == "" {
+			continue
+		}
+		*hash = strings.ToLower(strings.ReplaceAll(*hash, ":", ""))
+		if decoded, err := hex.DecodeString(*hash); err != nil || len(decoded) != 32 {
+			return fmt.Errorf("%q is not a hex SHA-256 digest", *hash)
+		}
+	}
+	
+	return nil
+}
+
+// proxySchemes are the proxy_url schemes the egress dialer supports; socks5h
+// resolves target names on the proxy
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// validateEgress validates an egress path and normalizes the IP family
+func (m *Manager) validateEgress(egress *metrics.EgressConfig) error {
+	if egress.ProxyURL != "" && egress.ProxyURL != "direct" {
+		proxyURL, err := url.Parse(egress.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		if !slices.Contains(proxySchemes, proxyURL.Scheme) {
+			return fmt.Errorf("proxy_url scheme must be one of %s", strings.Join(proxySchemes, ", "))
+		}
+		if proxyURL.Host == "" {
+			return fmt.Errorf("proxy_url must include a host")
+		}
+	}
+	
+	egress.IPFamily = strings.ToLower(egress.IPFamily)
+	switch egress.IPFamily {
+	case "", "ipv4", "ipv6":
+	default:
+		return fmt.Errorf("ip_family must be ipv4 or ipv6")
+	}
+	
+	if egress.SourceAddress != "" {
+		ip := net.ParseIP(egress.SourceAddress)
+		if ip == nil {
+			return fmt.Errorf("source_address %q is not an IP address", egress.SourceAddress)
+		}
+		if (egress.IPFamily == "ipv4" && ip.To4() == nil) || (egress.IPFamily == "ipv6" && ip.To4() != nil) {
+			return fmt.Errorf("source_address %s does not match ip_family %s", egress.SourceAddress, egress.IPFamily)
+		}
+	}
+	
+	return nil
+}
+
+// validateSTAMP validates STAMP session-sender and session-reflector settings
+func (m *Manager) validateSTAMP(stamp *metrics.STAMPConfig) error {
+	if stamp.PacketCount <= 0 {
+		stamp.PacketCount = 10
+	}
+	if stamp.PacketCount > 1000 {
+		return fmt.Errorf("packet_count cannot exceed 1000")
+	}
+	if stamp.PacketInterval <= 0 {
+		stamp.PacketInterval = 100 * time.Millisecond
+	}
+	if stamp.Timeout <= 0 {
+		stamp.Timeout = 2 * time.Second
+	}
+	if stamp.ReflectorAddress == "" {
+		stamp.ReflectorAddress = ":862"
+	}
+	
+	return nil
+}
+
+// validateTCPStats validates the socket aggregation settings of the tcp_stats collector
+func (m *Manager) validateTCPStats(tcpStats *metrics.TCPStatsConfig) error {
+	if tcpStats.RemotePrefixV4 == 0 {
+		tcpStats.RemotePrefixV4 = 24
+	}
+	if tcpStats.RemotePrefixV4 < 0 || tcpStats.RemotePrefixV4 > 32 {
+		return fmt.Errorf("remote_prefix_v4 must be between 0 and 32")
+	}
+	if tcpStats.RemotePrefixV6 == 0 {
+		tcpStats.RemotePrefixV6 = 64
+	}
+	if tcpStats.RemotePrefixV6 < 0 || tcpStats.RemotePrefixV6 > 128 {
+		return fmt.Errorf("remote_prefix_v6 must be between 0 and 128")
+	}
+	if tcpStats.MaxGroups <= 0 {
+		tcpStats.MaxGroups = 50
+	}
+	
 	return nil
 }
 
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalConfig renders a configuration as YAML with the keys in field order
+// and durations written as "30s" rather than nanoseconds, so the output can
+// be used as a configuration file
+func MarshalConfig(config *metrics.AgentConfig) ([]byte, error) {
+	node, err := configNode(reflect.ValueOf(*config))
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// configNode converts a configuration value to a YAML node
+func configNode(value reflect.Value) (*yaml.Node, error) {
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		return scalarNode(time.Duration(value.Int()).String())
+
+	case value.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < value.NumField(); i++ {
+			key := yamlKey(value.Type().Field(i))
+			if key == "" {
+				continue
+			}
+			child, err := configNode(value.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		}
+		return node, nil
+
+	case value.Kind() == reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		if value.Len() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			child, err := configNode(value.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key.String()}, child)
+		}
+		return node, nil
+
+	case value.Kind() == reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if value.Len() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for i := 0; i < value.Len(); i++ {
+			child, err := configNode(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+
+	return scalarNode(value.Interface())
+}
+
+// scalarNode encodes a single value as a YAML node
+func scalarNode(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// redacted replaces secret values in configurations shared for diagnostics
+const redacted = "REDACTED"
+
+// urlPattern matches URLs embedded in free text such as log lines
+var urlPattern = regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^\s"']+`)
+
+// sensitiveNameParts mark header and query parameter names whose values are secrets
+var sensitiveNameParts = []string{"auth", "token", "secret", "password", "passwd", "key", "cookie", "session", "credential", "signature", "sig"}
+
+// Redact returns a copy of config with credentials replaced, for support
+// bundles and other output that may leave the host. Passwords in URLs, the
+// backend and control API tokens, sensitive headers and query parameters,
+// SNMP communities and request bodies are removed; targets and other settings
+// are kept so the copy still explains what the agent does.
+func Redact(config *metrics.AgentConfig) (*metrics.AgentConfig, error) {
+	copied, err := copyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	copied.BackendURL = redactURL(copied.BackendURL)
+	if copied.Backend.Token != "" {
+		copied.Backend.Token = redacted
+	}
+	if copied.Control.Token != "" {
+		copied.Control.Token = redacted
+	}
+	redactEgress(&copied.Egress)
+
+	targets := &copied.CustomTargets
+	for i := range targets.HTTPTargets {
+		target := &targets.HTTPTargets[i]
+		target.URL = redactURL(target.URL)
+		redactHeaders(target.Headers)
+		redactEgress(&target.Egress)
+	}
+	for i := range targets.TCPTargets {
+		redactEgress(&targets.TCPTargets[i].Egress)
+	}
+	for i := range targets.PingEgress {
+		redactEgress(&targets.PingEgress[i].Egress)
+	}
+	for i := range targets.UDPTargets {
+		target := &targets.UDPTargets[i]
+		if target.Community != "" {
+			target.Community = redacted
+		}
+	}
+	for i := range targets.HTTPTransactions {
+		for j := range targets.HTTPTransactions[i].Steps {
+			step := &targets.HTTPTransactions[i].Steps[j]
+			step.URL = redactURL(step.URL)
+			redactHeaders(step.Headers)
+			if step.Body != "" {
+				step.Body = fmt.Sprintf("%s (%d bytes)", redacted, len(step.Body))
+			}
+		}
+	}
+	for i := range targets.GRPCTargets {
+		for j := range targets.GRPCTargets[i].Methods {
+			method := &targets.GRPCTargets[i].Methods[j]
+			if method.Request != "" && method.Request != "{}" {
+				method.Request = fmt.Sprintf("%s (%d bytes)", redacted, len(method.Request))
+			}
+		}
+	}
+
+	return copied, nil
+}
+
+// RedactText removes passwords and sensitive query parameters from the URLs
+// in text, such as log lines, command output and error messages
+func RedactText(text string) string {
+	return urlPattern.ReplaceAllStringFunc(text, redactURL)
+}
+
+// redactURL removes the password and sensitive query parameters from a URL,
+// returning URLs without either unchanged
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	changed := false
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+		changed = true
+	}
+	query := parsed.Query()
+	queryChanged := false
+	for name := range query {
+		if sensitiveName(name) {
+			query.Set(name, redacted)
+			queryChanged = true
+		}
+	}
+	if queryChanged {
+		parsed.RawQuery = query.Encode()
+		changed = true
+	}
+	if !changed {
+		return rawURL
+	}
+	return parsed.String()
+}
+
+// redactEgress removes proxy credentials
+func redactEgress(egress *metrics.EgressConfig) {
+	egress.ProxyURL = redactURL(egress.ProxyURL)
+}
+
+// redactHeaders replaces the values of sensitive headers in place
+func redactHeaders(headers map[string]string) {
+	for name := range headers {
+		if sensitiveName(name) {
+			headers[name] = redacted
+		}
+	}
+}
+
+// sensitiveName reports whether a header or parameter name suggests a secret value
+func sensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
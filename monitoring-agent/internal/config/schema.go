@@ -0,0 +1,137 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// durationPattern matches the values time.ParseDuration accepts
+const durationPattern = `^(0|[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// Schema is the subset of JSON Schema used to describe the configuration file
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false, or the *Schema of map values
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// schemaConstraints narrows the generated schema for individual settings,
+// keyed by path with [] standing for any array element
+var schemaConstraints = map[string]func(schema *Schema){
+	"log_level":                             enumOf(logLevels()...),
+	"batch_size":                            rangeOf(1, 1000),
+	"backend.min_tls_version":               enumOf("", "1.2", "1.3"),
+	"custom_targets.tcp_ports[]":            rangeOf(1, 65535),
+	"custom_targets.udp_targets[].protocol": enumOf("ntp", "snmp", "raw"),
+	"egress.ip_family":                      enumOf("", "ipv4", "ipv6"),
+	"traceroute.max_hops":                   rangeOf(1, 255),
+	"tcp_stats.remote_prefix_v4":            rangeOf(0, 32),
+	"tcp_stats.remote_prefix_v6":            rangeOf(0, 128),
+}
+
+// GenerateSchema returns the JSON Schema of the configuration file, derived
+// from the yaml tags of metrics.AgentConfig. collectors lists the names
+// accepted in the collectors setting.
+func GenerateSchema(collectors []string) *Schema {
+	schema := schemaForType(reflect.TypeOf(metrics.AgentConfig{}), "")
+	schema.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "Network Monitor Agent configuration"
+	schema.Properties["collectors"].Items.Enum = collectors
+	return schema
+}
+
+// schemaForType describes values of type t found at path
+func schemaForType(t reflect.Type, path string) *Schema {
+	var schema *Schema
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		schema = &Schema{
+			Type:        "string",
+			Pattern:     durationPattern,
+			Description: "Duration such as 500ms, 30s or 1h30m",
+		}
+
+	case t.Kind() == reflect.Struct:
+		schema = &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := yamlKey(field)
+			if key == "" {
+				continue
+			}
+			schema.Properties[key] = schemaForType(field.Type, joinPath(path, key))
+		}
+
+	case t.Kind() == reflect.Map:
+		schema = &Schema{
+			Type:                 "object",
+			AdditionalProperties: schemaForType(t.Elem(), path+".*"),
+		}
+
+	case t.Kind() == reflect.Slice:
+		schema = &Schema{
+			Type:  "array",
+			Items: schemaForType(t.Elem(), path+"[]"),
+		}
+
+	case t.Kind() == reflect.Bool:
+		schema = &Schema{Type: "boolean"}
+
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = &Schema{Type: "integer"}
+
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = &Schema{Type: "number"}
+
+	default:
+		schema = &Schema{Type: "string"}
+	}
+
+	if constrain, exists := schemaConstraints[path]; exists {
+		constrain(schema)
+	}
+	return schema
+}
+
+// yamlKey returns the configuration key of a struct field, or "" if it has none
+func yamlKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// joinPath appends key to a dotted configuration path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func enumOf(values ...string) func(*Schema) {
+	return func(schema *Schema) { schema.Enum = values }
+}
+
+func rangeOf(minimum, maximum float64) func(*Schema) {
+	return func(schema *Schema) {
+		schema.Minimum = &minimum
+		schema.Maximum = &maximum
+	}
+}
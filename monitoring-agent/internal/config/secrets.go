@@ -0,0 +1,337 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// secretRefPattern matches ${env:NAME} and ${file:/path} secret references.
+// The prefix keeps them apart from the ${name} variables of HTTP transactions.
+var secretRefPattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// minRedactedSecretLength is the shortest secret value redacted from free
+// text; shorter values such as "http" or "1" would match unrelated words
+const minRedactedSecretLength = 6
+
+// secretSet records the secrets resolved for one configuration
+type secretSet struct {
+	references map[string]string          // Resolved value to the reference it came from
+	settings   map[string]resolvedSetting // Configuration path to each setting holding a reference
+	files      []string                   // Files read by ${file:} references
+}
+
+// resolvedSetting is a setting as written in the configuration and with its
+// secret references resolved
+type resolvedSetting struct {
+	written  string
+	resolved string
+}
+
+// secretProblem is a reference that could not be resolved
+type secretProblem struct {
+	path string
+	err  error
+}
+
+// resolveSecrets replaces the secret references in every string of config
+// with the environment variable or file content they name. File content has
+// its trailing newline removed.
+func resolveSecrets(config *metrics.AgentConfig) (*secretSet, []secretProblem) {
+	secrets := &secretSet{references: make(map[string]string), settings: make(map[string]resolvedSetting)}
+	var problems []secretProblem
+
+	walkStrings(reflect.ValueOf(config).Elem(), "", func(path, value string) string {
+		resolvedValue := secretRefPattern.ReplaceAllStringFunc(value, func(reference string) string {
+			match := secretRefPattern.FindStringSubmatch(reference)
+			source, name := match[1], strings.TrimSpace(match[2])
+
+			var resolved string
+			switch source {
+			case "env":
+				value, ok := os.LookupEnv(name)
+				if !ok {
+					problems = append(problems, secretProblem{path, fmt.Errorf("environment variable %s is not set", name)})
+					return reference
+				}
+				resolved = value
+			case "file":
+				if name == "" {
+					problems = append(problems, secretProblem{path, fmt.Errorf("secret reference %s has no file", reference)})
+					return reference
+				}
+				content, err := os.ReadFile(name)
+				if err != nil {
+					problems = append(problems, secretProblem{path, fmt.Errorf("failed to read secret file: %w", err)})
+					return reference
+				}
+				resolved = strings.TrimRight(string(content), "\r\n")
+				secrets.files = appendUnique(secrets.files, filepath.Clean(name))
+			}
+
+			if resolved != "" {
+				secrets.references[resolved] = reference
+			}
+			return resolved
+		})
+		if resolvedValue != value {
+			secrets.settings[path] = resolvedSetting{written: value, resolved: resolvedValue}
+		}
+		return resolvedValue
+	})
+
+	return secrets, problems
+}
+
+// restore returns a copy of config with every setting that held a secret
+// reference written as it was, provided it still has the resolved value.
+// Other settings are redacted like free text.
+func (s *secretSet) restore(config *metrics.AgentConfig) (*metrics.AgentConfig, error) {
+	copied, err := copyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	walkStrings(reflect.ValueOf(copied).Elem(), "", func(path, value string) string {
+		if setting, ok := s.settings[path]; ok && setting.resolved == value {
+			return setting.written
+		}
+		return s.redact(value)
+	})
+	return copied, nil
+}
+
+// redact replaces resolved secret values in value with their references,
+// longest first so that a secret containing another is replaced whole.
+// Values shorter than minRedactedSecretLength are left alone, and a secret is
+// only replaced where it is not part of a longer word.
+func (s *secretSet) redact(value string) string {
+	if s == nil || len(s.references) == 0 || value == "" {
+		return value
+	}
+	secrets := make([]string, 0, len(s.references))
+	for secret := range s.references {
+		if len(secret) >= minRedactedSecretLength {
+			secrets = append(secrets, secret)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	for _, secret := range secrets {
+		value = replaceWord(value, secret, s.references[secret])
+	}
+	return value
+}
+
+// replaceWord replaces the occurrences of word in value that are not
+// directly preceded or followed by another letter, digit or underscore
+func replaceWord(value, word, replacement string) string {
+	var result strings.Builder
+	start := 0
+	for offset := 0; ; {
+		i := strings.Index(value[offset:], word)
+		if i < 0 {
+			break
+		}
+		i += offset
+		end := i + len(word)
+		bounded := (i == 0 || !isWordByte(value[i-1]) || !isWordByte(word[0])) &&
+			(end == len(value) || !isWordByte(value[end]) || !isWordByte(word[len(word)-1]))
+		if !bounded {
+			offset = i + 1
+			continue
+		}
+		result.WriteString(value[start:i])
+		result.WriteString(replacement)
+		start, offset = end, end
+	}
+	if start == 0 {
+		return value
+	}
+	result.WriteString(value[start:])
+	return result.String()
+}
+
+// isWordByte reports whether b is an ASCII letter, digit or underscore
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// secretError redacts resolved secret values from an error message
+func secretError(secrets *secretSet, err error) error {
+	if message := secrets.redact(err.Error()); message != err.Error() {
+		return errors.New(message)
+	}
+	return err
+}
+
+// RedactString replaces the secret values resolved for the loaded
+// configuration, and for one returned by Reload but not yet committed, with
+// their ${env:} or ${file:} references, for any text that may show
+// configuration values, such as log lines and status output
+func (m *Manager) RedactString(value string) string {
+	m.secretsMutex.RLock()
+	defer m.secretsMutex.RUnlock()
+	return m.pendingSecrets.redact(m.secrets.redact(value))
+}
+
+// RedactSecrets returns a copy of config showing secret references in place of
+// the values they were resolved to
+func (m *Manager) RedactSecrets(config *metrics.AgentConfig) (*metrics.AgentConfig, error) {
+	m.secretsMutex.RLock()
+	defer m.secretsMutex.RUnlock()
+	if m.secrets == nil {
+		return copyConfig(config)
+	}
+	return m.secrets.restore(config)
+}
+
+// SecretFiles returns the files read by ${file:} references in the loaded configuration
+func (m *Manager) SecretFiles() []string {
+	m.secretsMutex.RLock()
+	defer m.secretsMutex.RUnlock()
+	if m.secrets == nil {
+		return nil
+	}
+	return append([]string(nil), m.secrets.files...)
+}
+
+// setSecrets records the secrets of the current configuration and tells
+// the file watcher when the set of secret files changed
+func (m *Manager) setSecrets(secrets *secretSet) {
+	m.secretsMutex.Lock()
+	changed := m.secrets == nil || !reflect.DeepEqual(m.secrets.files, secrets.files)
+	m.secrets = secrets
+	m.secretsMutex.Unlock()
+
+	if changed {
+		select {
+		case m.secretFilesChanged <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// stageSecrets holds the secrets of a reloaded configuration apart from the
+// current ones until Commit installs them or Discard drops them. Meanwhile
+// both are redacted, since applying the configuration may log its values.
+// A nil config drops the secrets staged by an earlier Reload.
+func (m *Manager) stageSecrets(config *metrics.AgentConfig, secrets *secretSet) {
+	m.secretsMutex.Lock()
+	defer m.secretsMutex.Unlock()
+	m.pendingConfig, m.pendingSecrets = config, secrets
+}
+
+// commitSecrets installs the secrets staged for config by Reload
+func (m *Manager) commitSecrets(config *metrics.AgentConfig) {
+	m.secretsMutex.Lock()
+	if m.pendingConfig != config {
+		m.secretsMutex.Unlock()
+		return
+	}
+	secrets := m.pendingSecrets
+	m.pendingConfig, m.pendingSecrets = nil, nil
+	m.secretsMutex.Unlock()
+
+	m.setSecrets(secrets)
+}
+
+// LogHook returns a logrus hook that redacts resolved secret values from log
+// messages and string fields, such as a backend URL carrying a token
+func (m *Manager) LogHook() logrus.Hook {
+	return &secretLogHook{manager: m}
+}
+
+// secretLogHook rewrites log entries before they are formatted
+type secretLogHook struct {
+	manager *Manager
+}
+
+// Levels returns every level, so that no entry is written unredacted
+func (h *secretLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the entry in place
+func (h *secretLogHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.manager.RedactString(entry.Message)
+	for key, value := range entry.Data {
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = h.manager.RedactString(value)
+		case error:
+			if redacted := h.manager.RedactString(value.Error()); redacted != value.Error() {
+				entry.Data[key] = redacted
+			}
+		}
+	}
+	return nil
+}
+
+// walkStrings calls fn for every string in a configuration value, including
+// slice elements and map values, and stores what it returns. path is the
+// configuration path of value, in the form validate-config reports.
+func walkStrings(value reflect.Value, path string, fn func(path, value string) string) {
+	switch value.Kind() {
+	case reflect.String:
+		if updated := fn(path, value.String()); updated != value.String() {
+			value.SetString(updated)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			key := yamlKey(value.Type().Field(i))
+			if key == "" || !value.Field(i).CanSet() {
+				continue
+			}
+			walkStrings(value.Field(i), joinPath(path, key), fn)
+		}
+
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			walkStrings(value.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+
+	case reflect.Map:
+		if value.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range value.MapKeys() {
+			current := value.MapIndex(key).String()
+			if updated := fn(joinPath(path, key.String()), current); updated != current {
+				value.SetMapIndex(key, reflect.ValueOf(updated).Convert(value.Type().Elem()))
+			}
+		}
+	}
+}
+
+// copyConfig deep-copies a configuration through a JSON round trip
+func copyConfig(config *metrics.AgentConfig) (*metrics.AgentConfig, error) {
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy configuration: %w", err)
+	}
+	copied := &metrics.AgentConfig{}
+	if err := json.Unmarshal(encoded, copied); err != nil {
+		return nil, fmt.Errorf("failed to copy configuration: %w", err)
+	}
+	return copied, nil
+}
+
+// appendUnique appends value unless values already holds it
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
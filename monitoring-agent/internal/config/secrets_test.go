@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token-3c1d\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NM_TEST_BACKEND_TOKEN", "env-token-8a2b")
+	t.Setenv("NM_TEST_SCHEME", "http")
+
+	config := &metrics.AgentConfig{
+		Backend: metrics.BackendConfig{Token: "${env:NM_TEST_BACKEND_TOKEN}"},
+		Control: metrics.ControlConfig{Token: "${file:" + tokenFile + "}"},
+		CustomTargets: metrics.CustomTargets{
+			HTTPTargets: []metrics.HTTPTarget{{
+				URL:     "https://example.com/",
+				Headers: map[string]string{"X-Scheme": "${env:NM_TEST_SCHEME}", "X-Request": "${name}"},
+			}},
+		},
+	}
+	secrets, problems := resolveSecrets(config)
+	if len(problems) != 0 {
+		t.Fatalf("resolveSecrets() problems = %v", problems)
+	}
+
+	if config.Backend.Token != "env-token-8a2b" {
+		t.Errorf("backend.token = %q, want the environment variable", config.Backend.Token)
+	}
+	if config.Control.Token != "file-token-3c1d" {
+		t.Errorf("control.token = %q, want the file content without its newline", config.Control.Token)
+	}
+	headers := config.CustomTargets.HTTPTargets[0].Headers
+	if headers["X-Scheme"] != "http" || headers["X-Request"] != "${name}" {
+		t.Errorf("headers = %v, want the secret resolved and the transaction variable kept", headers)
+	}
+	if len(secrets.files) != 1 || secrets.files[0] != tokenFile {
+		t.Errorf("secret files = %v, want [%s]", secrets.files, tokenFile)
+	}
+
+	// Every setting is written back as it was, including short values
+	restored, err := secrets.restore(config)
+	if err != nil {
+		t.Fatalf("restore() error = %v", err)
+	}
+	if restored.Backend.Token != "${env:NM_TEST_BACKEND_TOKEN}" || restored.Control.Token != "${file:"+tokenFile+"}" {
+		t.Errorf("restore() tokens = %q, %q, want their references", restored.Backend.Token, restored.Control.Token)
+	}
+	if got := restored.CustomTargets.HTTPTargets[0].Headers["X-Scheme"]; got != "${env:NM_TEST_SCHEME}" {
+		t.Errorf("restore() X-Scheme = %q, want its reference", got)
+	}
+	if restored.CustomTargets.HTTPTargets[0].URL != "https://example.com/" {
+		t.Errorf("restore() URL = %q, want it unchanged", restored.CustomTargets.HTTPTargets[0].URL)
+	}
+}
+
+func TestResolveSecretsProblems(t *testing.T) {
+	config := &metrics.AgentConfig{
+		Backend: metrics.BackendConfig{Token: "${env:NM_TEST_UNSET_VARIABLE}"},
+		Control: metrics.ControlConfig{Token: "${file:" + filepath.Join(t.TempDir(), "missing") + "}"},
+		AgentID: "${file:}",
+	}
+	_, problems := resolveSecrets(config)
+
+	paths := make(map[string]string)
+	for _, problem := range problems {
+		paths[problem.path] = problem.err.Error()
+	}
+	want := map[string]string{
+		"backend.token": "NM_TEST_UNSET_VARIABLE is not set",
+		"control.token": "failed to read secret file",
+		"agent_id":      "has no file",
+	}
+	for path, message := range want {
+		if !strings.Contains(paths[path], message) {
+			t.Errorf("problem for %s = %q, want it to contain %q", path, paths[path], message)
+		}
+	}
+	if len(problems) != len(want) {
+		t.Errorf("resolveSecrets() problems = %v, want %d", problems, len(want))
+	}
+	if config.Backend.Token != "${env:NM_TEST_UNSET_VARIABLE}" {
+		t.Errorf("backend.token = %q, want the unresolved reference kept", config.Backend.Token)
+	}
+}
+
+func TestSecretSetRedact(t *testing.T) {
+	secrets := &secretSet{references: map[string]string{
+		"http":            "${env:SCHEME}",
+		"s3cr3t":          "${env:SHORT_TOKEN}",
+		"s3cr3t-extended": "${env:LONG_TOKEN}",
+		"p@ss:w0rd!":      "${file:/run/secrets/password}",
+	}}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"http_up 1", "http_up 1"},
+		{"GET http://example.com/", "GET http://example.com/"},
+		{"Authorization: Bearer s3cr3t", "Authorization: Bearer ${env:SHORT_TOKEN}"},
+		{"token=s3cr3t&user=agent", "token=${env:SHORT_TOKEN}&user=agent"},
+		{"s3cr3t-extended", "${env:LONG_TOKEN}"},
+		{"xs3cr3t and s3cr3ts", "xs3cr3t and s3cr3ts"},
+		{"s3cr3ts3cr3t s3cr3t", "s3cr3ts3cr3t ${env:SHORT_TOKEN}"},
+		{"password p@ss:w0rd!x", "password ${file:/run/secrets/password}x"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := secrets.redact(tt.value); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	var none *secretSet
+	if got := none.redact("s3cr3t"); got != "s3cr3t" {
+		t.Errorf("nil redact() = %q, want the value unchanged", got)
+	}
+}
+
+// writeSecretsTestConfig writes an agent configuration whose backend token
+// is read from the environment variable NM_TEST_STAGED_TOKEN
+func writeSecretsTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agent.yaml")
+	content := `backend_url: "ws://127.0.0.1:8080"
+collectors: ["network_interface"]
+backend:
+  token: "${env:NM_TEST_STAGED_TOKEN}"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestManagerStagesSecretsUntilCommit(t *testing.T) {
+	const reference = "${env:NM_TEST_STAGED_TOKEN}"
+	t.Setenv("NM_TEST_STAGED_TOKEN", "first-token-1111")
+	manager := NewManager()
+	manager.SetConfigFile(writeSecretsTestConfig(t))
+	if err := manager.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// A reloaded configuration's secrets are redacted while it is applied
+	t.Setenv("NM_TEST_STAGED_TOKEN", "second-token-2222")
+	second, err := manager.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := manager.RedactString("first-token-1111 second-token-2222"); got != reference+" "+reference {
+		t.Errorf("RedactString() while staged = %q, want both tokens redacted", got)
+	}
+	if manager.GetConfig().Backend.Token != "first-token-1111" {
+		t.Errorf("GetConfig() before Commit has token %q, want the first one", manager.GetConfig().Backend.Token)
+	}
+
+	// A rejected configuration's secrets are dropped again
+	manager.Discard(second)
+	if got := manager.RedactString("second-token-2222"); got != "second-token-2222" {
+		t.Errorf("RedactString() after Discard = %q, want the rejected token left alone", got)
+	}
+
+	t.Setenv("NM_TEST_STAGED_TOKEN", "third-token-3333")
+	third, err := manager.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	manager.Commit(third)
+	if got := manager.RedactString("first-token-1111 third-token-3333"); got != "first-token-1111 "+reference {
+		t.Errorf("RedactString() after Commit = %q, want only the committed token redacted", got)
+	}
+	if manager.GetConfig().Backend.Token != "third-token-3333" {
+		t.Errorf("GetConfig() after Commit has token %q, want the third one", manager.GetConfig().Backend.Token)
+	}
+
+	// A reload that fails to resolve drops whatever an earlier one staged
+	t.Setenv("NM_TEST_STAGED_TOKEN", "fourth-token-4444")
+	if _, err := manager.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	os.Unsetenv("NM_TEST_STAGED_TOKEN")
+	if _, err := manager.Reload(); err == nil {
+		t.Fatal("Reload() with an unset variable succeeded, want an error")
+	}
+	if got := manager.RedactString("fourth-token-4444 third-token-3333"); got != "fourth-token-4444 "+reference {
+		t.Errorf("RedactString() after a failed Reload = %q, want only the current token redacted", got)
+	}
+}
@@ -0,0 +1,678 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Collection intervals outside this range are rejected by validate-config
+const (
+	minCollectInterval = 5 * time.Second
+	maxCollectInterval = time.Hour
+)
+
+var (
+	// yamlErrorLine extracts the line number from a yaml.v3 syntax error
+	yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+	// hostnamePattern matches DNS names, including single-label names such as localhost
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]{0,62})?(\.[A-Za-z0-9_]([A-Za-z0-9_-]{0,62})?)*\.?$`)
+	// pathIndexSuffix matches the array index at the end of a configuration path
+	pathIndexSuffix = regexp.MustCompile(`\[\d+\]$`)
+)
+
+// Problem is one issue found in a configuration
+type Problem struct {
+	Path    string `json:"path"`           // Setting concerned, e.g. custom_targets.tcp_targets[1].address
+	Line    int    `json:"line,omitempty"` // 0 when the value comes from a default or the environment
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationResult is the outcome of validating a configuration file
+type ValidationResult struct {
+	File      string
+	Problems  []Problem
+	Effective *metrics.AgentConfig // Fully resolved configuration, nil when problems were found
+}
+
+// Validate checks the configuration file against the schema generated from
+// metrics.AgentConfig and against semantic rules, collecting every problem
+// instead of stopping at the first one. Unlike Load it does not rewrite bad
+// values. collectors lists the names accepted in the collectors setting.
+func (m *Manager) Validate(collectors []string) (*ValidationResult, error) {
+	m.setDefaults()
+
+	readErr := m.viper.ReadInConfig()
+	var notFound viper.ConfigFileNotFoundError
+	if errors.As(readErr, &notFound) {
+		return nil, fmt.Errorf("no configuration file found: %w", readErr)
+	}
+	file := m.viper.ConfigFileUsed()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	result := &ValidationResult{File: file}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		problem := Problem{Message: err.Error()}
+		if matches := yamlErrorLine.FindStringSubmatch(err.Error()); matches != nil {
+			problem.Line, _ = strconv.Atoi(matches[1])
+		}
+		result.Problems = append(result.Problems, problem)
+		return result, nil
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", readErr)
+	}
+
+	v := &validator{manager: m, nodes: make(map[string]*yaml.Node)}
+	if len(document.Content) > 0 {
+		v.checkSchema(GenerateSchema(collectors), document.Content[0], "")
+	}
+
+	// Values of the wrong type are left unset by the decoder and already
+	// reported by the schema; the rest of the config is still checked
+	config, err := m.decode()
+	if err != nil && len(v.problems) == 0 {
+		v.problems = append(v.problems, Problem{Message: err.Error()})
+	}
+	secrets, secretProblems := resolveSecrets(config)
+	for _, problem := range secretProblems {
+		v.report(problem.path, "%v", problem.err)
+	}
+	v.checkSemantics(config, collectors)
+	for i := range v.problems {
+		v.problems[i].Message = secrets.redact(v.problems[i].Message)
+	}
+	if len(v.problems) > 0 {
+		// File order, with problems in defaults or the environment last
+		sort.SliceStable(v.problems, func(i, j int) bool {
+			a, b := v.problems[i], v.problems[j]
+			if (a.Line == 0) != (b.Line == 0) {
+				return b.Line == 0
+			}
+			return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+		})
+		result.Problems = v.problems
+		return result, nil
+	}
+
+	// Anything the rules above missed is caught by the agent's own validation
+	if err := m.validateAndEnrich(config); err != nil {
+		result.Problems = append(result.Problems, Problem{Message: secretError(secrets, err).Error()})
+		return result, nil
+	}
+
+	// The effective configuration shows secret references, not their values
+	result.Effective, err = secrets.restore(config)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validator accumulates the problems found in one configuration file
+type validator struct {
+	manager  *Manager
+	nodes    map[string]*yaml.Node // Value node of every path present in the file
+	problems []Problem
+}
+
+// report records a problem at the position of path in the file, or of its
+// closest parent that is present, unless path already has a problem
+func (v *validator) report(path, format string, args ...interface{}) {
+	for _, existing := range v.problems {
+		if existing.Path == path {
+			return
+		}
+	}
+	problem := Problem{Path: path, Message: fmt.Sprintf(format, args...)}
+	if node := v.node(path); node != nil {
+		problem.Line, problem.Column = node.Line, node.Column
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// reportAt records a problem at the position of node
+func (v *validator) reportAt(node *yaml.Node, path, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Path:    path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// node returns the node of path or of its closest parent present in the file
+func (v *validator) node(path string) *yaml.Node {
+	for path != "" {
+		if node, exists := v.nodes[path]; exists {
+			return node
+		}
+		if trimmed := pathIndexSuffix.ReplaceAllString(path, ""); trimmed != path {
+			path = trimmed
+		} else if dot := strings.LastIndex(path, "."); dot >= 0 {
+			path = path[:dot]
+		} else {
+			path = ""
+		}
+	}
+	return nil
+}
+
+// location describes where path is set, for messages that refer to another value
+func (v *validator) location(path string) string {
+	if node, exists := v.nodes[path]; exists {
+		return fmt.Sprintf("line %d", node.Line)
+	}
+	return path
+}
+
+// checkSchema validates node against schema, recording the nodes it visits
+func (v *validator) checkSchema(schema *Schema, node *yaml.Node, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	v.nodes[path] = node
+
+	// An empty value leaves the default in place
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			v.reportAt(node, path, "expected a mapping, got %s", describeNode(node))
+			return
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := keyNode.Value
+			keyPath := joinPath(path, key)
+			if seen[key] {
+				v.reportAt(keyNode, keyPath, "duplicate key %q", key)
+				continue
+			}
+			seen[key] = true
+
+			if property, exists := schema.Properties[key]; exists {
+				v.checkSchema(property, valueNode, keyPath)
+				continue
+			}
+			if values, ok := schema.AdditionalProperties.(*Schema); ok {
+				v.checkSchema(values, valueNode, keyPath)
+				continue
+			}
+			if suggestion := closestKey(key, schema.Properties); suggestion != "" {
+				v.reportAt(keyNode, keyPath, "unknown key %q, did you mean %q?", key, suggestion)
+			} else {
+				v.reportAt(keyNode, keyPath, "unknown key %q", key)
+			}
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.reportAt(node, path, "expected a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.checkSchema(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.reportAt(node, path, "expected %s, got %s", schemaTypeName(schema), describeNode(node))
+			return
+		}
+		v.checkScalar(schema, node, path)
+	}
+}
+
+// checkScalar validates a scalar value against its type, pattern, enum and range
+func (v *validator) checkScalar(schema *Schema, node *yaml.Node, path string) {
+	switch schema.Type {
+	case "boolean":
+		if _, err := strconv.ParseBool(node.Value); err != nil && node.Tag != "!!bool" {
+			v.reportAt(node, path, "expected true or false, got %q", node.Value)
+			return
+		}
+
+	case "integer", "number":
+		// Quoted numbers are accepted, as the agent itself accepts them
+		value, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil || schema.Type == "integer" && value != float64(int64(value)) {
+			v.reportAt(node, path, "expected %s, got %q", schemaTypeName(schema), node.Value)
+			return
+		}
+		if schema.Minimum != nil && value < *schema.Minimum || schema.Maximum != nil && value > *schema.Maximum {
+			v.reportAt(node, path, "%s is out of range %g-%g", node.Value, *schema.Minimum, *schema.Maximum)
+			return
+		}
+
+	case "string":
+		if schema.Pattern == durationPattern {
+			if _, err := time.ParseDuration(node.Value); err != nil {
+				v.reportAt(node, path, "invalid duration %q, expected e.g. 500ms, 30s or 1h30m", node.Value)
+				return
+			}
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, value := range schema.Enum {
+			// The agent accepts these settings in any case
+			if strings.EqualFold(value, node.Value) {
+				return
+			}
+		}
+		v.reportAt(node, path, "invalid value %q, expected one of %s", node.Value, quoteAll(schema.Enum))
+	}
+}
+
+// checkSemantics checks the decoded configuration, including defaults and
+// environment overrides, for values that are well-typed but unusable
+func (v *validator) checkSemantics(config *metrics.AgentConfig, collectors []string) {
+	known := make(map[string]bool, len(collectors))
+	for _, name := range collectors {
+		known[name] = true
+	}
+	for i, name := range config.Collectors {
+		if !known[name] {
+			v.report(fmt.Sprintf("collectors[%d]", i), "unknown collector %q, expected one of %s", name, quoteAll(collectors))
+		}
+	}
+	v.checkDuplicates("collectors", config.Collectors)
+
+	if config.LogLevel != "" && !validLogLevel(config.LogLevel) {
+		v.report("log_level", "invalid log level %q", config.LogLevel)
+	}
+
+	v.checkURL("backend_url", config.BackendURL, "ws", "wss", "http", "https")
+	v.checkBackend(config.BackendURL, config.Backend)
+	if config.CollectInterval < minCollectInterval || config.CollectInterval > maxCollectInterval {
+		v.report("collect_interval", "collect_interval %s is out of range %s-%s", config.CollectInterval, minCollectInterval, maxCollectInterval)
+	}
+	if config.BatchSize < 1 || config.BatchSize > 1000 {
+		v.report("batch_size", "batch_size %d is out of range 1-1000", config.BatchSize)
+	}
+
+	v.checkTargets(&config.CustomTargets, config.CollectInterval)
+	v.checkEgress("egress", config.Egress, false)
+
+	timeouts := map[string]time.Duration{
+		"stamp.timeout": config.STAMP.Timeout,
+		"tls.timeout":   config.TLS.Timeout,
+		"udp.timeout":   config.UDP.Timeout,
+		"ntp.timeout":   config.NTP.Timeout,
+		"grpc.timeout":  config.GRPC.Timeout,
+		"dns.timeout":   config.DNS.Timeout,
+	}
+	for path, timeout := range timeouts {
+		v.checkTimeout(path, timeout, config.CollectInterval)
+	}
+	if config.Traceroute.Timeout < 0 {
+		v.report("traceroute.timeout", "timeout cannot be negative")
+	}
+
+	for i, server := range config.NTP.Servers {
+		v.checkHost(fmt.Sprintf("ntp.servers[%d]", i), server, false)
+	}
+	v.checkDuplicates("ntp.servers", config.NTP.Servers)
+
+	for i, name := range config.DNS.Names {
+		if !hostnamePattern.MatchString(name) {
+			v.report(fmt.Sprintf("dns.names[%d]", i), "invalid DNS name %q", name)
+		}
+	}
+	v.checkDuplicates("dns.names", config.DNS.Names)
+	if config.DNS.RecordType != "" && !dnsRecordTypes[strings.ToUpper(config.DNS.RecordType)] {
+		v.report("dns.record_type", "unsupported record_type %q, expected one of A, AAAA, CNAME, MX, NS or TXT", config.DNS.RecordType)
+	}
+
+	if config.STAMP.ReflectorAddress != "" {
+		if _, _, err := net.SplitHostPort(config.STAMP.ReflectorAddress); err != nil {
+			v.report("stamp.reflector_address", "address %q must be host:port", config.STAMP.ReflectorAddress)
+		}
+	}
+	if config.Control.Address != "" {
+		if err := v.manager.validateControlAddress(config.Control.Address); err != nil {
+			v.report("control.address", "%v", err)
+		} else if !strings.HasPrefix(config.Control.Address, "unix:") && config.Control.Token == "" {
+			v.report("control.token", "token is required with a TCP control address")
+		}
+	}
+	if config.Health.Address != "" {
+		if _, _, err := net.SplitHostPort(config.Health.Address); err != nil {
+			v.report("health.address", "address %q must be host:port", config.Health.Address)
+		}
+	}
+	if config.Health.MaxCollectAge < 0 {
+		v.report("health.max_collect_age", "threshold cannot be negative")
+	}
+	if config.Health.MaxSendAge < 0 {
+		v.report("health.max_send_age", "threshold cannot be negative")
+	}
+}
+
+// checkBackend checks the backend credentials and TLS settings, including
+// that the files they name can be read
+func (v *validator) checkBackend(backendURL string, backend metrics.BackendConfig) {
+	if (backend.CertFile == "") != (backend.KeyFile == "") {
+		v.report("backend.cert_file", "cert_file and key_file must be set together")
+	}
+	files := []struct{ path, file string }{
+		{"backend.ca_file", backend.CAFile},
+		{"backend.cert_file", backend.CertFile},
+		{"backend.key_file", backend.KeyFile},
+	}
+	for _, file := range files {
+		if file.file == "" {
+			continue
+		}
+		if _, err := os.Stat(file.file); err != nil {
+			v.report(file.path, "cannot read %s: %v", file.file, errors.Unwrap(err))
+		}
+	}
+	for i, pin := range backend.PinnedSHA256 {
+		if _, err := metrics.ParsePin(pin); err != nil {
+			v.report(fmt.Sprintf("backend.pinned_sha256[%d]", i), "%v", err)
+		}
+	}
+	if strings.ContainsAny(backend.Token, "\r\n") {
+		v.report("backend.token", "token cannot contain line breaks")
+	}
+
+	u, err := url.Parse(backendURL)
+	if err != nil || u.Scheme == "wss" || u.Scheme == "https" {
+		return
+	}
+	tlsSettings := []struct {
+		path string
+		set  bool
+	}{
+		{"backend.ca_file", backend.CAFile != ""},
+		{"backend.cert_file", backend.CertFile != ""},
+		{"backend.server_name", backend.ServerName != ""},
+		{"backend.pinned_sha256", len(backend.PinnedSHA256) > 0},
+		{"backend.insecure_skip_verify", backend.InsecureSkipVerify},
+	}
+	for _, setting := range tlsSettings {
+		if setting.set {
+			v.report(setting.path, "TLS settings require a wss:// or https:// backend_url")
+		}
+	}
+}
+
+// checkTargets checks the custom targets for malformed addresses and duplicates
+func (v *validator) checkTargets(targets *metrics.CustomTargets, interval time.Duration) {
+	for i, target := range targets.PingTargets {
+		v.checkHost(fmt.Sprintf("custom_targets.ping_targets[%d]", i), target, false)
+	}
+	v.checkDuplicates("custom_targets.ping_targets", targets.PingTargets)
+
+	for i, target := range targets.TracerouteTargets {
+		v.checkHost(fmt.Sprintf("custom_targets.traceroute_targets[%d]", i), target, false)
+	}
+	v.checkDuplicates("custom_targets.traceroute_targets", targets.TracerouteTargets)
+
+	for i, server := range targets.DNSServers {
+		v.checkHost(fmt.Sprintf("custom_targets.dns_servers[%d]", i), server, false)
+	}
+	v.checkDuplicates("custom_targets.dns_servers", targets.DNSServers)
+
+	for i, target := range targets.STAMPTargets {
+		v.checkHost(fmt.Sprintf("custom_targets.stamp_targets[%d]", i), target, false)
+	}
+	v.checkDuplicates("custom_targets.stamp_targets", targets.STAMPTargets)
+
+	ports := make([]string, len(targets.TCPPorts))
+	for i, port := range targets.TCPPorts {
+		ports[i] = strconv.Itoa(port)
+	}
+	v.checkDuplicates("custom_targets.tcp_ports", ports)
+
+	urls := make([]string, len(targets.HTTPTargets))
+	for i, target := range targets.HTTPTargets {
+		path := fmt.Sprintf("custom_targets.http_targets[%d]", i)
+		urls[i] = target.URL
+		v.checkURL(path+".url", target.URL, "http", "https")
+		v.checkTimeout(path+".timeout", target.Timeout, interval)
+		v.checkEgress(path+".egress", target.Egress, true)
+	}
+	v.checkDuplicates("custom_targets.http_targets", urls)
+
+	addresses := make([]string, len(targets.TCPTargets))
+	for i, target := range targets.TCPTargets {
+		path := fmt.Sprintf("custom_targets.tcp_targets[%d]", i)
+		addresses[i] = target.Address
+		v.checkHost(path+".address", target.Address, true)
+		v.checkTimeout(path+".timeout", target.Timeout, interval)
+		v.checkEgress(path+".egress", target.Egress, true)
+	}
+	v.checkDuplicates("custom_targets.tcp_targets", addresses)
+
+	addresses = make([]string, len(targets.TLSTargets))
+	for i, target := range targets.TLSTargets {
+		addresses[i] = target.Address
+		v.checkHost(fmt.Sprintf("custom_targets.tls_targets[%d].address", i), target.Address, false)
+	}
+	v.checkDuplicates("custom_targets.tls_targets", addresses)
+
+	addresses = make([]string, len(targets.UDPTargets))
+	for i, target := range targets.UDPTargets {
+		addresses[i] = target.Address
+		v.checkHost(fmt.Sprintf("custom_targets.udp_targets[%d].address", i), target.Address, false)
+	}
+	v.checkDuplicates("custom_targets.udp_targets", addresses)
+
+	addresses = make([]string, len(targets.GRPCTargets))
+	for i, target := range targets.GRPCTargets {
+		addresses[i] = target.Address + "/" + target.Service
+		v.checkHost(fmt.Sprintf("custom_targets.grpc_targets[%d].address", i), target.Address, true)
+	}
+	v.checkDuplicates("custom_targets.grpc_targets", addresses)
+
+	names := make([]string, len(targets.HTTPTransactions))
+	for i, transaction := range targets.HTTPTransactions {
+		names[i] = transaction.Name
+		if transaction.Name == "" {
+			v.report(fmt.Sprintf("custom_targets.http_transactions[%d]", i), "HTTP transaction name cannot be empty")
+		}
+		for j, step := range transaction.Steps {
+			path := fmt.Sprintf("custom_targets.http_transactions[%d].steps[%d]", i, j)
+			// URLs built from extracted variables are only known at run time
+			if !strings.Contains(step.URL, "${") {
+				v.checkURL(path+".url", step.URL, "http", "https")
+			}
+			v.checkTimeout(path+".timeout", step.Timeout, interval)
+		}
+	}
+	v.checkDuplicates("custom_targets.http_transactions", names)
+
+	for i, override := range targets.PingEgress {
+		v.checkEgress(fmt.Sprintf("custom_targets.ping_egress[%d].egress", i), override.Egress, true)
+	}
+}
+
+// checkEgress checks the proxy URL and source address of an egress path
+func (v *validator) checkEgress(path string, egress metrics.EgressConfig, target bool) {
+	if egress.ProxyURL == "direct" {
+		if !target {
+			v.report(path+".proxy_url", "proxy_url \"direct\" only applies to targets")
+		}
+	} else if egress.ProxyURL != "" {
+		v.checkURL(path+".proxy_url", egress.ProxyURL, proxySchemes...)
+	}
+	if egress.SourceAddress != "" && net.ParseIP(egress.SourceAddress) == nil {
+		v.report(path+".source_address", "invalid IP address %q", egress.SourceAddress)
+	}
+}
+
+// checkURL reports a URL that does not parse, has another scheme or no host
+func (v *validator) checkURL(path, rawURL string, schemes ...string) {
+	if rawURL == "" {
+		v.report(path, "URL cannot be empty")
+		return
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		v.report(path, "invalid URL %q: %v", rawURL, err)
+		return
+	}
+	valid := false
+	for _, scheme := range schemes {
+		valid = valid || parsed.Scheme == scheme
+	}
+	if !valid {
+		v.report(path, "URL %q must use %s", rawURL, strings.Join(schemes, ", "))
+		return
+	}
+	if parsed.Hostname() == "" {
+		v.report(path, "URL %q has no host", rawURL)
+	}
+}
+
+// checkHost reports an address that is not a host name or IP address, with
+// an optional port that is required when requirePort is set
+func (v *validator) checkHost(path, address string, requirePort bool) {
+	host := address
+	if h, port, err := net.SplitHostPort(address); err == nil {
+		number, err := strconv.Atoi(port)
+		if err != nil || number < 1 || number > 65535 {
+			v.report(path, "invalid port in %q", address)
+			return
+		}
+		host = h
+	} else if requirePort {
+		v.report(path, "address %q must be host:port", address)
+		return
+	}
+	if net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		v.report(path, "invalid host %q", address)
+	}
+}
+
+// checkTimeout reports a negative timeout, or one set in the file that is
+// longer than the collection interval
+func (v *validator) checkTimeout(path string, timeout, interval time.Duration) {
+	_, inFile := v.nodes[path]
+	switch {
+	case timeout < 0:
+		v.report(path, "timeout cannot be negative")
+	case timeout > interval && inFile:
+		v.report(path, "timeout %s exceeds collect_interval %s", timeout, interval)
+	}
+}
+
+// checkDuplicates reports every value of a list that repeats an earlier one
+func (v *validator) checkDuplicates(path string, values []string) {
+	first := make(map[string]int, len(values))
+	for i, value := range values {
+		if earlier, seen := first[value]; seen {
+			v.report(fmt.Sprintf("%s[%d]", path, i), "duplicate %q, also at %s", value, v.location(fmt.Sprintf("%s[%d]", path, earlier)))
+			continue
+		}
+		first[value] = i
+	}
+}
+
+// logLevels returns the values accepted by the log_level setting: the logrus
+// level names and "warn", which logrus accepts for "warning"
+func logLevels() []string {
+	levels := make([]string, 0, len(logrus.AllLevels)+1)
+	for _, level := range logrus.AllLevels {
+		levels = append(levels, level.String())
+	}
+	return append(levels, "warn")
+}
+
+// validLogLevel reports whether level is accepted by the log_level setting
+func validLogLevel(level string) bool {
+	return slices.Contains(logLevels(), strings.ToLower(level))
+}
+
+// closestKey returns the property within two edits of key, if any
+func closestKey(key string, properties map[string]*Schema) string {
+	best, bestDistance := "", 3
+	for property := range properties {
+		if distance := editDistance(key, property); distance < bestDistance || distance == bestDistance && property < best {
+			best, bestDistance = property, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// describeNode names the kind of a YAML node for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// schemaTypeName names a schema type for error messages
+func schemaTypeName(schema *Schema) string {
+	switch schema.Type {
+	case "integer":
+		return "an integer"
+	case "number":
+		return "a number"
+	case "boolean":
+		return "true or false"
+	}
+	if schema.Pattern == durationPattern {
+		return "a duration"
+	}
+	return "a string"
+}
+
+// quoteAll formats values as a comma separated list of quoted strings
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// validateTestCollectors are the collector names accepted by the fixtures
+var validateTestCollectors = []string{"network_interface", "tcp", "ping"}
+
+func TestValidateProblems(t *testing.T) {
+	tests := []struct {
+		file    string
+		path    string
+		line    int
+		column  int
+		message string
+	}{
+		{"unknown_key.yaml", "collect_intervall", 2, 1, `unknown key "collect_intervall", did you mean "collect_interval"?`},
+		{"bad_enum.yaml", "log_level", 3, 12, `invalid value "loud", expected one of`},
+		{"duplicate_target.yaml", "custom_targets.tcp_targets[2]", 7, 7, `duplicate "example.com:443", also at line 5`},
+		{"interval_range.yaml", "collect_interval", 2, 19, "collect_interval 1s is out of range 5s-1h0m0s"},
+		{"type_error.yaml", "batch_size", 3, 13, `expected an integer, got "many"`},
+		{"missing_secret.yaml", "backend.token", 4, 10, "failed to read secret file"},
+		{"missing_env.yaml", "control.token", 5, 10, "NM_VALIDATE_TEST_UNSET"},
+	}
+
+	for _, tt := range tests {
+		manager := NewManager()
+		manager.SetConfigFile("testdata/validate/" + tt.file)
+		result, err := manager.Validate(validateTestCollectors)
+		if err != nil {
+			t.Errorf("%s: Validate() error = %v", tt.file, err)
+			continue
+		}
+		if result.Effective != nil {
+			t.Errorf("%s: Validate() returned an effective configuration despite problems", tt.file)
+		}
+		if len(result.Problems) != 1 {
+			t.Errorf("%s: Validate() problems = %+v, want exactly one", tt.file, result.Problems)
+			continue
+		}
+
+		problem := result.Problems[0]
+		if problem.Path != tt.path || problem.Line != tt.line || problem.Column != tt.column {
+			t.Errorf("%s: problem at %s %d:%d, want %s %d:%d", tt.file, problem.Path, problem.Line, problem.Column, tt.path, tt.line, tt.column)
+		}
+		if !strings.Contains(problem.Message, tt.message) {
+			t.Errorf("%s: problem message = %q, want it to contain %q", tt.file, problem.Message, tt.message)
+		}
+	}
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	manager := NewManager()
+	manager.SetConfigFile("testdata/validate/valid.yaml")
+	result, err := manager.Validate(validateTestCollectors)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(result.Problems) != 0 {
+		t.Fatalf("Validate() problems = %+v, want none", result.Problems)
+	}
+	if result.Effective == nil || len(result.Effective.CustomTargets.TCPTargets) != 1 {
+		t.Errorf("Validate() effective configuration = %+v, want the fixture's TCP target", result.Effective)
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce coalesces the burst of events an editor or ConfigMap update produces
+const watchDebounce = 500 * time.Millisecond
+
+// Watch calls onChange whenever the configuration file or a file read by a
+// ${file:} secret reference changes, until ctx is done. Without a
+// configuration file only the secret files are watched. Directories are
+// watched rather than the files so that editors that replace the file and
+// Kubernetes ConfigMap and Secret symlink swaps are noticed.
+func (m *Manager) Watch(ctx context.Context, onChange func()) error {
+	return m.watch(ctx, m.viper.ConfigFileUsed(), onChange)
+}
+
+// WatchSecrets calls onChange whenever a file read by a ${file:} secret
+// reference changes, until ctx is done, so that rotated secrets are picked
+// up even when the configuration file itself is not watched. Files
+// referenced by later reloads are watched as they appear.
+func (m *Manager) WatchSecrets(ctx context.Context, onChange func()) error {
+	return m.watch(ctx, "", onChange)
+}
+
+// watch implements Watch and WatchSecrets; configFile is empty when only the
+// secret files are watched
+func (m *Manager) watch(ctx context.Context, configFile string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	// Real path of every watched file, to notice symlink swaps
+	files := make(map[string]string)
+	watchFile := func(file string) error {
+		file = filepath.Clean(file)
+		if _, ok := files[file]; ok {
+			return nil
+		}
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", file, err)
+		}
+		files[file], _ = filepath.EvalSymlinks(file)
+		return nil
+	}
+	if configFile != "" {
+		if err := watchFile(configFile); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+	watchSecretFiles := func() {
+		for _, file := range m.SecretFiles() {
+			// A secret directory that cannot be watched only loses reloads
+			watchFile(file)
+		}
+	}
+	watchSecretFiles()
+
+	go func() {
+		defer watcher.Close()
+
+		var debounce *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if debounce != nil {
+					debounce.Stop()
+				}
+				return
+
+			case <-m.secretFilesChanged:
+				// A reload referenced new secret files
+				watchSecretFiles()
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				changed := false
+				for file, realFile := range files {
+					currentFile, _ := filepath.EvalSymlinks(file)
+					written := filepath.Clean(event.Name) == file && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+					relinked := currentFile != "" && currentFile != realFile
+					if written || relinked {
+						files[file] = currentFile
+						changed = true
+					}
+				}
+				if !changed {
+					continue
+				}
+
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(watchDebounce, onChange)
+
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
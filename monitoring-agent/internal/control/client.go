@@ -0,0 +1,109 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// Status is the runtime status reported by a running agent
+type Status struct {
+	Running         bool                   `json:"running"`
+	AgentID         string                 `json:"agent_id"`
+	Location        metrics.CloudLocation  `json:"location"`
+	BackendURL      string                 `json:"backend_url"`
+	CollectInterval string                 `json:"collect_interval"`
+	Collectors      []string               `json:"collectors"`
+	Connected       bool                   `json:"connected"`
+	CollectorStats  []agent.CollectorStats `json:"collector_stats"`
+	QueueDepth      int                    `json:"queue_depth"`
+	QueueCapacity   int                    `json:"queue_capacity"`
+	MetricsQueued   uint64                 `json:"metrics_queued"`
+	MetricsSent     uint64                 `json:"metrics_sent"`
+	MetricsDropped  uint64                 `json:"metrics_dropped"`
+	BatchesSent     uint64                 `json:"batches_sent"`
+	BatchesFailed   uint64                 `json:"batches_failed"`
+	LastSend        *time.Time             `json:"last_send"`
+	LastSendError   string                 `json:"last_send_error"`
+}
+
+// Client queries the control API of a running agent
+type Client struct {
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the agent listening on address, sending
+// token as a bearer token when set
+func NewClient(address, token string, timeout time.Duration) *Client {
+	network, path := splitAddress(address)
+	dialer := &net.Dialer{}
+
+	return &Client{
+		token: token,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// Every request goes to the agent regardless of the URL host
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, path)
+				},
+			},
+		},
+	}
+}
+
+// Status fetches the agent's runtime status
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	status := &Status{}
+	if err := c.get(ctx, StatusPath, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Logs fetches the agent's recent log lines, oldest first
+func (c *Client) Logs(ctx context.Context) ([]string, error) {
+	var logs struct {
+		Lines []string `json:"lines"`
+	}
+	if err := c.get(ctx, LogsPath, &logs); err != nil {
+		return nil, err
+	}
+	return logs.Lines, nil
+}
+
+// get requests path from the agent and decodes the JSON response into value
+func (c *Client) get(ctx context.Context, path string, value interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://agent"+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("unexpected response: %s, check control.token", response.Status)
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", response.Status)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", path, err)
+	}
+	return nil
+}
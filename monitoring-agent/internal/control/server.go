@@ -0,0 +1,234 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/internal/agent"
+	"github.com/sirupsen/logrus"
+)
+
+// Control API endpoints
+const (
+	StatusPath = "/v1/status" // Runtime status of the agent and its collectors
+	LogsPath   = "/v1/logs"   // Recent log lines, for the diagnose command
+)
+
+// Liveness and readiness endpoints for orchestrator probes
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// HealthProvider reports whether a running agent is alive and ready
+type HealthProvider interface {
+	Liveness() agent.HealthReport
+	Readiness() agent.HealthReport
+}
+
+// StatusProvider reports the state of a running agent
+type StatusProvider interface {
+	HealthProvider
+	GetStatus() map[string]interface{}
+	RecentLogs() []string
+}
+
+// Server exposes the agent's runtime status on a local endpoint, either a
+// Unix socket ("unix:/path/to/agent.sock") only the agent's user can connect
+// to, or a loopback TCP address guarded by a bearer token
+type Server struct {
+	address string
+	status  StatusProvider
+	health  HealthProvider
+	server  *http.Server
+	logger  *logrus.Logger
+}
+
+// NewServer creates a control API server for a running agent. When token is
+// set, every request must carry it as a bearer token.
+func NewServer(address, token string, provider StatusProvider, logger *logrus.Logger) *Server {
+	server := &Server{
+		address: address,
+		status:  provider,
+		health:  provider,
+		logger:  logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(StatusPath, server.handleStatus)
+	mux.HandleFunc(LogsPath, server.handleLogs)
+	server.handleHealth(mux)
+	server.server = &http.Server{
+		Handler:           requireToken(token, mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return server
+}
+
+// NewHealthServer creates a server exposing only the liveness and readiness
+// endpoints, for probes that cannot reach the loopback-only control API
+func NewHealthServer(address string, provider HealthProvider, logger *logrus.Logger) *Server {
+	server := &Server{
+		address: address,
+		health:  provider,
+		logger:  logger,
+	}
+
+	mux := http.NewServeMux()
+	server.handleHealth(mux)
+	server.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return server
+}
+
+// Start listens on the control address and serves requests in the background
+func (s *Server) Start() error {
+	listener, err := listen(s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.WithError(err).Error("Control API server failed")
+		}
+	}()
+
+	if s.status == nil {
+		s.logger.WithField("address", s.address).Info("Health probe endpoints listening")
+	} else {
+		s.logger.WithField("address", s.address).Info("Control API listening")
+	}
+	return nil
+}
+
+// Stop shuts the server down, waiting for in-flight requests until ctx ends
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// handleStatus writes the agent status as JSON
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.status.GetStatus())
+}
+
+// handleLogs writes the agent's recent log lines as JSON
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string][]string{"lines": s.status.RecentLogs()})
+}
+
+// handleHealth registers the liveness and readiness endpoints on mux; they
+// answer 200 when every check passes and 503 otherwise
+func (s *Server) handleHealth(mux *http.ServeMux) {
+	probes := map[string]func() agent.HealthReport{
+		LivenessPath:  s.health.Liveness,
+		ReadinessPath: s.health.Readiness,
+	}
+	for path, probe := range probes {
+		probe := probe
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if !allowGet(w, r) {
+				return
+			}
+
+			report := probe()
+			code := http.StatusOK
+			if !report.OK() {
+				code = http.StatusServiceUnavailable
+			}
+			s.writeJSON(w, code, report)
+		})
+	}
+}
+
+// writeJSON writes value as a JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		s.logger.WithError(err).Debug("Failed to write control API response")
+	}
+}
+
+// requireToken rejects requests without the bearer token, unless token is empty
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowGet rejects requests other than GET and HEAD
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// listen opens a TCP or Unix socket listener, replacing a stale socket file
+// left behind by an agent that did not shut down cleanly
+func listen(address string) (net.Listener, error) {
+	network, path := splitAddress(address)
+	if network != "unix" {
+		return net.Listen(network, path)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another agent is serving %s", path)
+		}
+		os.Remove(path)
+	}
+
+	// The default socket lives in a directory of its own, e.g. /run/network-monitor.
+	// MkdirAll leaves an existing directory's mode alone, so check it.
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := checkSocketDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	// Only the agent's user may query it
+	return listenUnix(path)
+}
+
+// splitAddress returns the network and address to dial for a control address
+func splitAddress(address string) (string, string) {
+	if path, found := strings.CutPrefix(address, "unix:"); found {
+		return "unix", strings.TrimPrefix(path, "//")
+	}
+	return "tcp", address
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package control
+
+import "net"
+
+// listenUnix creates the socket; access is left to the directory's ACL
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
+
+// checkSocketDir accepts any directory, as file mode bits do not apply here
+func checkSocketDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package control
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// socketMutex serializes the umask change in listenUnix
+var socketMutex sync.Mutex
+
+// listenUnix creates the socket with mode 0600 from the start, so that no
+// other user can connect before its permissions are set
+func listenUnix(path string) (net.Listener, error) {
+	socketMutex.Lock()
+	defer socketMutex.Unlock()
+
+	oldMask := syscall.Umask(0177)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", path)
+}
+
+// checkSocketDir rejects a socket directory that other users can write to,
+// where they could replace the socket, unless the sticky bit prevents it
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0022 != 0 && info.Mode()&os.ModeSticky == 0 {
+		return fmt.Errorf("socket directory %s is writable by other users (mode %04o)", dir, info.Mode().Perm())
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package transmitter
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/przemyslawsroka/CloudConsoleVibe/monitoring-agent/pkg/metrics"
+)
+
+// tlsVersions maps backend.min_tls_version to crypto/tls versions
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// BackendTLSConfig builds the TLS configuration for connections to the
+// backend at serverURL: the CA bundle, client certificate, minimum version
+// and public key pins of backend. The client certificate is read from disk
+// on every handshake, so renewed certificates are used on reconnect.
+func BackendTLSConfig(backend metrics.BackendConfig, serverURL string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         backend.ServerName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: backend.InsecureSkipVerify,
+	}
+	if config.ServerName == "" {
+		if u, err := url.Parse(serverURL); err == nil {
+			config.ServerName = u.Hostname()
+		}
+	}
+
+	if backend.MinTLSVersion != "" {
+		version, ok := tlsVersions[backend.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported min_tls_version %q, expected 1.2 or 1.3", backend.MinTLSVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if backend.CAFile != "" {
+		data, err := os.ReadFile(backend.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", backend.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", backend.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if backend.CertFile != "" {
+		// Fail early on a bad pair rather than on the first handshake
+		if _, err := tls.LoadX509KeyPair(backend.CertFile, backend.KeyFile); err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, err := tls.LoadX509KeyPair(backend.CertFile, backend.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			return &certificate, nil
+		}
+	}
+
+	if len(backend.PinnedSHA256) > 0 {
+		pins := make(map[[sha256.Size]byte]bool, len(backend.PinnedSHA256))
+		for _, pin := range backend.PinnedSHA256 {
+			sum, err := metrics.ParsePin(pin)
+			if err != nil {
+				return nil, err
+			}
+			pins[sum] = true
+		}
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(state, pins, backend.InsecureSkipVerify)
+		}
+	}
+
+	return config, nil
+}
+
+// BackendHeader returns the headers sent with the connection request,
+// carrying the bearer token when one is configured
+func BackendHeader(backend metrics.BackendConfig) http.Header {
+	header := http.Header{}
+	if backend.Token != "" {
+		header.Set("Authorization", "Bearer "+backend.Token)
+	}
+	return header
+}
+
+// verifyPins accepts a connection when the public key of a certificate in a
+// verified chain matches a pin. Other certificates the backend presented are
+// not trusted; with verification skipped only its own certificate is checked.
+func verifyPins(state tls.ConnectionState, pins map[[sha256.Size]byte]bool, skipVerify bool) error {
+	var certificates []*x509.Certificate
+	if skipVerify {
+		if len(state.PeerCertificates) > 0 {
+			certificates = state.PeerCertificates[:1]
+		}
+	} else {
+		for _, chain := range state.VerifiedChains {
+			certificates = append(certificates, chain...)
+		}
+	}
+	for _, certificate := range certificates {
+		if pins[sha256.Sum256(certificate.RawSubjectPublicKeyInfo)] {
+			return nil
+		}
+	}
+	return fmt.Errorf("backend certificate chain matches none of the pinned public keys")
+}
@@ -0,0 +1,92 @@
+package transmitter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestCertificate creates a certificate for name, signed by parent or
+// self-signed when parent is nil
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	return certificate, key
+}
+
+// pinOf returns the pin of a certificate's public key
+func pinOf(certificate *x509.Certificate) [sha256.Size]byte {
+	return sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+}
+
+func TestVerifyPins(t *testing.T) {
+	ca, caKey := newTestCertificate(t, "ca", nil, nil)
+	leaf, _ := newTestCertificate(t, "backend", ca, caKey)
+	extra, _ := newTestCertificate(t, "extra", nil, nil)
+
+	// The backend presents an extra certificate that is not part of the
+	// chain verified against the trusted roots
+	verified := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf, extra},
+		VerifiedChains:   [][]*x509.Certificate{{leaf, ca}},
+	}
+	unverified := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf, ca, extra},
+	}
+
+	tests := []struct {
+		name       string
+		state      tls.ConnectionState
+		pin        *x509.Certificate
+		skipVerify bool
+		wantErr    bool
+	}{
+		{"leaf in verified chain", verified, leaf, false, false},
+		{"CA in verified chain", verified, ca, false, false},
+		{"extra certificate only", verified, extra, false, true},
+		{"skip verify leaf", unverified, leaf, true, false},
+		{"skip verify CA", unverified, ca, true, true},
+		{"skip verify extra certificate", unverified, extra, true, true},
+		{"no verified chains", unverified, leaf, false, true},
+	}
+
+	for _, tt := range tests {
+		pins := map[[sha256.Size]byte]bool{pinOf(tt.pin): true}
+		err := verifyPins(tt.state, pins, tt.skipVerify)
+		if tt.wantErr && err == nil {
+			t.Errorf("%s: verifyPins() succeeded, want an error", tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: verifyPins() error = %v", tt.name, err)
+		}
+	}
+}
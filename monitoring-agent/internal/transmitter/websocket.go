@@ -3,9 +3,11 @@ package transmitter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,6 +18,7 @@ import (
 // WebSocketTransmitter sends metrics via WebSocket connection
 type WebSocketTransmitter struct {
 	serverURL    string
+	backend      metrics.BackendConfig
 	agentID      string
 	location     metrics.CloudLocation
 	conn         *websocket.Conn
@@ -28,12 +31,16 @@ type WebSocketTransmitter struct {
 	pingInterval     time.Duration
 	stopChan         chan bool
 	doneChan         chan bool
+	clockOffset      metrics.ClockOffsetSource
+	reconnects       atomic.Uint64
+	reconnectFailures atomic.Uint64
 }
 
 // NewWebSocketTransmitter creates a new WebSocket-based metric transmitter
-func NewWebSocketTransmitter(serverURL, agentID string, location metrics.CloudLocation, logger *logrus.Logger) *WebSocketTransmitter {
+func NewWebSocketTransmitter(serverURL string, backend metrics.BackendConfig, agentID string, location metrics.CloudLocation, logger *logrus.Logger) *WebSocketTransmitter {
 	return &WebSocketTransmitter{
 		serverURL:         serverURL,
+		backend:          backend,
 		agentID:          agentID,
 		location:         location,
 		logger:           logger,
@@ -81,12 +88,25 @@ func (wst *WebSocketTransmitter) Connect() error {
 
 	wst.logger.WithField("url", u.String()).Info("Connecting to backend")
 
-	// Establish WebSocket connection
-	dialer := websocket.DefaultDialer
+	// Establish WebSocket connection, authenticated by the bearer token
+	// and client certificate when configured
+	dialer := *websocket.DefaultDialer
 	dialer.HandshakeTimeout = 30 * time.Second
+	if u.Scheme == "wss" {
+		tlsConfig, err := BackendTLSConfig(wst.backend, wst.serverURL)
+		if err != nil {
+			return fmt.Errorf("invalid backend TLS configuration: %w", err)
+		}
+		dialer.TLSClientConfig = tlsConfig
+	} else if wst.backend.Token != "" {
+		wst.logger.Warn("Sending the backend token over an unencrypted connection, use a wss:// backend_url")
+	}
 
-	conn, _, err := dialer.Dial(u.String(), nil)
+	conn, response, err := dialer.Dial(u.String(), BackendHeader(wst.backend))
 	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && response != nil {
+			return fmt.Errorf("backend rejected the connection: %s", response.Status)
+		}
 		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 
@@ -149,6 +169,13 @@ func (wst *WebSocketTransmitter) IsConnected() bool {
 	return wst.connected
 }
 
+// SetClockOffsetSource tags outgoing batches with the clock offset measured by source
+func (wst *WebSocketTransmitter) SetClockOffsetSource(source metrics.ClockOffsetSource) {
+	wst.mutex.Lock()
+	defer wst.mutex.Unlock()
+	wst.clockOffset = source
+}
+
 // Send transmits a batch of metrics to the backend
 func (wst *WebSocketTransmitter) Send(ctx context.Context, batchMetrics []metrics.Metric) error {
 	if !wst.IsConnected() {
@@ -162,6 +189,16 @@ func (wst *WebSocketTransmitter) Send(ctx context.Context, batchMetrics []metric
 		Location:  wst.location,
 	}
 
+	wst.mutex.RLock()
+	clockOffset := wst.clockOffset
+	wst.mutex.RUnlock()
+	if clockOffset != nil {
+		if offset, ok := clockOffset.ClockOffset(); ok {
+			offsetMs := float64(offset) / float64(time.Millisecond)
+			batch.ClockOffsetMs = &offsetMs
+		}
+	}
+
 	message := map[string]interface{}{
 		"type": "metrics",
 		"data": batch,
@@ -182,7 +219,8 @@ func (wst *WebSocketTransmitter) sendRegistration() error {
 		},
 	}
 
-	return wst.sendMessage(registration)
+	// Called from Connect, which already holds the lock
+	return wst.writeMessage(registration)
 }
 
 // sendMessage sends a JSON message over WebSocket
@@ -190,6 +228,11 @@ func (wst *WebSocketTransmitter) sendMessage(message interface{}) error {
 	wst.mutex.Lock()
 	defer wst.mutex.Unlock()
 
+	return wst.writeMessage(message)
+}
+
+// writeMessage writes a JSON message; the caller holds the lock
+func (wst *WebSocketTransmitter) writeMessage(message interface{}) error {
 	if !wst.connected || wst.conn == nil {
 		return fmt.Errorf("connection not available")
 	}
@@ -252,15 +295,17 @@ func (wst *WebSocketTransmitter) readHandler() {
 				continue
 			}
 
-			wst.mutex.Lock()
-			if wst.conn == nil {
-				wst.mutex.Unlock()
+			wst.mutex.RLock()
+			conn := wst.conn
+			wst.mutex.RUnlock()
+			if conn == nil {
 				continue
 			}
 
-			wst.conn.SetReadDeadline(time.Now().Add(wst.readTimeout))
-			messageType, message, err := wst.conn.ReadMessage()
-			wst.mutex.Unlock()
+			// Read without the lock so writers and status queries are not blocked;
+			// the connection supports one concurrent reader alongside a writer
+			conn.SetReadDeadline(time.Now().Add(wst.readTimeout))
+			messageType, message, err := conn.ReadMessage()
 
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
@@ -326,6 +371,11 @@ func (wst *WebSocketTransmitter) handleMessage(messageType int, message []byte)
 	}
 }
 
+// ReconnectStats returns the number of successful and failed reconnection attempts
+func (wst *WebSocketTransmitter) ReconnectStats() (uint64, uint64) {
+	return wst.reconnects.Load(), wst.reconnectFailures.Load()
+}
+
 // StartReconnectLoop starts automatic reconnection in case of connection loss
 func (wst *WebSocketTransmitter) StartReconnectLoop(ctx context.Context) {
 	go func() {
@@ -340,8 +390,10 @@ func (wst *WebSocketTransmitter) StartReconnectLoop(ctx context.Context) {
 				if !wst.IsConnected() {
 					wst.logger.Info("Attempting to reconnect to backend")
 					if err := wst.Connect(); err != nil {
+						wst.reconnectFailures.Add(1)
 						wst.logger.WithError(err).Error("Failed to reconnect")
 					} else {
+						wst.reconnects.Add(1)
 						wst.logger.Info("Successfully reconnected to backend")
 					}
 				}
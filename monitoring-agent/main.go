@@ -229,8 +229,6 @@ func collectPingMetrics() []PingResult {
 }
 
 func pingTarget(target string) PingResult {
-	start := time.Now()
-	
 	// Use ping command
 	cmd := exec.Command("ping", "-c", "3", "-W", "5", target)
 	output, err := cmd.Output()
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ParsePin decodes a public key pin from BackendConfig.PinnedSHA256: the
+// SHA-256 of a certificate's SubjectPublicKeyInfo as base64, optionally
+// prefixed with "sha256/", or as hex with optional colons
+func ParsePin(pin string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	trimmed := strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+
+	decoded, err := base64.StdEncoding.DecodeString(trimmed)
+	if err != nil || len(decoded) != sha256.Size {
+		decoded, err = hex.DecodeString(strings.ReplaceAll(trimmed, ":", ""))
+	}
+	if err != nil || len(decoded) != sha256.Size {
+		return sum, fmt.Errorf("invalid pin %q, expected a base64 or hex SHA-256 digest", pin)
+	}
+	copy(sum[:], decoded)
+	return sum, nil
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestParsePin(t *testing.T) {
+	sum := sha256.Sum256([]byte("public key"))
+	encoded := base64.StdEncoding.EncodeToString(sum[:])
+	hexEncoded := hex.EncodeToString(sum[:])
+
+	var colons []string
+	for i := 0; i < len(hexEncoded); i += 2 {
+		colons = append(colons, strings.ToUpper(hexEncoded[i:i+2]))
+	}
+
+	tests := []struct {
+		pin     string
+		wantErr bool
+	}{
+		{encoded, false},
+		{"sha256/" + encoded, false},
+		{" " + encoded + "\n", false},
+		{hexEncoded, false},
+		{strings.Join(colons, ":"), false},
+		{"", true},
+		{"not a pin", true},
+		{base64.StdEncoding.EncodeToString(sum[:16]), true},
+		{hexEncoded[:62], true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePin(tt.pin)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePin(%q) succeeded, want an error", tt.pin)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePin(%q) error = %v", tt.pin, err)
+			continue
+		}
+		if got != sum {
+			t.Errorf("ParsePin(%q) = %x, want %x", tt.pin, got, sum)
+		}
+	}
+}
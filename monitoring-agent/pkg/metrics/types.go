@@ -34,6 +34,20 @@ type MetricCollector interface {
 	Stop() error
 }
 
+// EventSource is implemented by collectors that report changes as they happen
+// rather than waiting for the next collection interval
+type EventSource interface {
+	SetEventSink(sink func(Metric))
+}
+
+// ClockOffsetSource is implemented by collectors that measure the host clock
+// against a reference, so outgoing batches can carry the offset
+type ClockOffsetSource interface {
+	// ClockOffset returns the reference clock minus the host clock, and false
+	// when no recent measurement is available
+	ClockOffset() (time.Duration, bool)
+}
+
 // MetricTransmitter interface for sending metrics to backend
 type MetricTransmitter interface {
 	Send(ctx context.Context, metrics []Metric) error
@@ -59,11 +73,30 @@ type AgentConfig struct {
 	AgentID        string        `json:"agent_id" yaml:"agent_id"`
 	Location       CloudLocation `json:"location" yaml:"location"`
 	BackendURL     string        `json:"backend_url" yaml:"backend_url"`
+	Backend        BackendConfig `json:"backend" yaml:"backend"` // Authentication and TLS for the connection to backend_url
 	CollectInterval time.Duration `json:"collect_interval" yaml:"collect_interval"`
 	BatchSize      int           `json:"batch_size" yaml:"batch_size"`
 	LogLevel       string        `json:"log_level" yaml:"log_level"`
 	Collectors     []string      `json:"collectors" yaml:"collectors"`
 	CustomTargets  CustomTargets `json:"custom_targets" yaml:"custom_targets"`
+	STAMP          STAMPConfig   `json:"stamp" yaml:"stamp"`
+	TCPStats       TCPStatsConfig `json:"tcp_stats" yaml:"tcp_stats"`
+	Netstat        NetstatConfig  `json:"netstat" yaml:"netstat"`
+	Conntrack      ConntrackConfig `json:"conntrack" yaml:"conntrack"`
+	Softnet        SoftnetConfig  `json:"softnet" yaml:"softnet"`
+	Routes         RoutesConfig   `json:"routes" yaml:"routes"`
+	TLS            TLSConfig      `json:"tls" yaml:"tls"`
+	UDP            UDPConfig      `json:"udp" yaml:"udp"`
+	NTP            NTPConfig      `json:"ntp" yaml:"ntp"`
+	GRPC           GRPCConfig     `json:"grpc" yaml:"grpc"`
+	DNS            DNSConfig      `json:"dns" yaml:"dns"`
+	Traceroute     TracerouteConfig `json:"traceroute" yaml:"traceroute"`
+	Egress         EgressConfig   `json:"egress" yaml:"egress"` // Default egress path for the ping, http and tcp collectors
+	Control        ControlConfig  `json:"control" yaml:"control"`
+	Health         HealthConfig   `json:"health" yaml:"health"`
+	SelfTelemetry  bool           `json:"self_telemetry" yaml:"self_telemetry"` // Emit agent_* metrics about the agent itself
+	WatchConfig    bool           `json:"watch_config" yaml:"watch_config"`     // Reload when the config file changes, in addition to SIGHUP
+	ProcRoot       string         `json:"proc_root" yaml:"proc_root"` // Mount point of procfs, e.g. /host/proc in a container
 }
 
 // CustomTargets represents user-defined monitoring targets
@@ -73,6 +106,14 @@ type CustomTargets struct {
 	TCPPorts    []int             `json:"tcp_ports" yaml:"tcp_ports"`
 	DNSServers  []string          `json:"dns_servers" yaml:"dns_servers"`
 	CustomHosts map[string]string `json:"custom_hosts" yaml:"custom_hosts"`
+	STAMPTargets []string         `json:"stamp_targets" yaml:"stamp_targets"`
+	TLSTargets  []TLSTarget       `json:"tls_targets" yaml:"tls_targets"`
+	UDPTargets  []UDPTarget       `json:"udp_targets" yaml:"udp_targets"`
+	GRPCTargets []GRPCTarget      `json:"grpc_targets" yaml:"grpc_targets"`
+	HTTPTransactions []HTTPTransaction `json:"http_transactions" yaml:"http_transactions"`
+	TCPTargets  []TCPTarget       `json:"tcp_targets" yaml:"tcp_targets"`
+	PingEgress  []PingEgress      `json:"ping_egress" yaml:"ping_egress"` // Egress overrides for individual ping targets
+	TracerouteTargets []string    `json:"traceroute_targets" yaml:"traceroute_targets"`
 }
 
 // HTTPTarget represents an HTTP endpoint to monitor
@@ -83,6 +124,216 @@ type HTTPTarget struct {
 	Timeout       time.Duration     `json:"timeout" yaml:"timeout"`
 	Headers       map[string]string `json:"headers" yaml:"headers"`
 	FollowRedirect bool             `json:"follow_redirect" yaml:"follow_redirect"`
+	Assert        HTTPAssertions    `json:"assert" yaml:"assert"` // Content checks; assert.status overrides expected_code
+	Egress        EgressConfig      `json:"egress" yaml:"egress"`   // Overrides the global egress settings
+}
+
+// TCPTarget represents a TCP endpoint whose connection setup is monitored
+type TCPTarget struct {
+	Address string        `json:"address" yaml:"address"` // host:port
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	Egress  EgressConfig  `json:"egress" yaml:"egress"`   // Overrides the global egress settings
+}
+
+// PingEgress overrides the egress path for one of the ping targets
+type PingEgress struct {
+	Target string       `json:"target" yaml:"target"` // Entry of ping_targets
+	Egress EgressConfig `json:"egress" yaml:"egress"`
+}
+
+// EgressConfig selects the path probes leave the host on. Per-target settings
+// override the global ones field by field.
+type EgressConfig struct {
+	ProxyURL      string `json:"proxy_url" yaml:"proxy_url"`           // http://, https://, socks5:// or socks5h:// proxy for HTTP and TCP probes; "direct" bypasses a global proxy
+	SourceAddress string `json:"source_address" yaml:"source_address"` // Local IP address to send from
+	Interface     string `json:"interface" yaml:"interface"`           // Interface to bind to with SO_BINDTODEVICE (Linux only)
+	IPFamily      string `json:"ip_family" yaml:"ip_family"`           // ipv4 or ipv6, empty for either
+}
+
+// HTTPTransaction is a scripted sequence of HTTP requests that share a cookie
+// jar and variables extracted from earlier responses
+type HTTPTransaction struct {
+	Name  string     `json:"name" yaml:"name"`
+	Steps []HTTPStep `json:"steps" yaml:"steps"`
+}
+
+// HTTPStep is one request of a transaction. URL, headers and body may refer
+// to extracted variables as ${name}.
+type HTTPStep struct {
+	Name           string            `json:"name" yaml:"name"`
+	Method         string            `json:"method" yaml:"method"`
+	URL            string            `json:"url" yaml:"url"`
+	Headers        map[string]string `json:"headers" yaml:"headers"`
+	Body           string            `json:"body" yaml:"body"`
+	Timeout        time.Duration     `json:"timeout" yaml:"timeout"`
+	FollowRedirect bool              `json:"follow_redirect" yaml:"follow_redirect"`
+	Extract        []HTTPExtract     `json:"extract" yaml:"extract"`
+	Assert         HTTPAssertions    `json:"assert" yaml:"assert"`
+}
+
+// HTTPExtract stores part of a response in a variable for later steps
+type HTTPExtract struct {
+	Variable string `json:"variable" yaml:"variable"`
+	From     string `json:"from" yaml:"from"` // json, regex or header
+	Path     string `json:"path" yaml:"path"` // JSON path such as data.items[0].id, a regex whose first group is used, or a header name
+}
+
+// HTTPAssertions are checks applied to an HTTP response
+type HTTPAssertions struct {
+	Status       int             `json:"status" yaml:"status"`               // Expected status code, 0 accepts any status below 400
+	BodyContains string          `json:"body_contains" yaml:"body_contains"`
+	BodyRegex    string          `json:"body_regex" yaml:"body_regex"`
+	JSON         []JSONAssertion `json:"json" yaml:"json"`
+	MaxLatency   time.Duration   `json:"max_latency" yaml:"max_latency"`
+	HeadersPresent []string      `json:"headers_present" yaml:"headers_present"`
+	MaxBodyBytes int64           `json:"max_body_bytes" yaml:"max_body_bytes"`
+	BodySHA256   string          `json:"body_sha256" yaml:"body_sha256"` // Hex SHA-256 of the whole body
+	CertSHA256   string          `json:"cert_sha256" yaml:"cert_sha256"` // Hex SHA-256 fingerprint of the server's leaf certificate, colons optional
+}
+
+// JSONAssertion compares the value at a JSON path with an expected value
+type JSONAssertion struct {
+	Path   string `json:"path" yaml:"path"`
+	Equals string `json:"equals" yaml:"equals"` // Compared with the value rendered as text, e.g. "42", "true" or "ok"
+}
+
+// TLSTarget represents a TLS endpoint whose handshake and certificate chain are monitored
+type TLSTarget struct {
+	Address    string   `json:"address" yaml:"address"`         // host:port, port 443 when omitted
+	ServerName string   `json:"server_name" yaml:"server_name"` // SNI and name to verify, defaults to the host of Address
+	CAFile     string   `json:"ca_file" yaml:"ca_file"`         // PEM bundle to verify against instead of tls.ca_file or the system roots
+	ALPN       []string `json:"alpn" yaml:"alpn"`               // Protocols to offer, defaults to tls.alpn
+}
+
+// UDPTarget represents a UDP service probed with a protocol-aware request
+type UDPTarget struct {
+	Address     string `json:"address" yaml:"address"`           // host:port, the protocol's well-known port when omitted
+	Protocol    string `json:"protocol" yaml:"protocol"`         // ntp, snmp or raw
+	Community   string `json:"community" yaml:"community"`       // SNMP v2c community, defaults to "public"
+	Payload     string `json:"payload" yaml:"payload"`           // Raw request as text
+	PayloadHex  string `json:"payload_hex" yaml:"payload_hex"`   // Raw request as hex, for binary protocols such as DNS
+	ExpectRegex string `json:"expect_regex" yaml:"expect_regex"` // Raw response must match this regular expression
+}
+
+// GRPCTarget represents a gRPC server probed with health checks and optional unary calls
+type GRPCTarget struct {
+	Address            string       `json:"address" yaml:"address"`                           // host:port
+	Service            string       `json:"service" yaml:"service"`                           // Health service name, empty for the server as a whole
+	Watch              bool         `json:"watch" yaml:"watch"`                               // Use the streaming Watch RPC instead of Check
+	TLS                bool         `json:"tls" yaml:"tls"`                                   // Plaintext when false
+	ServerName         string       `json:"server_name" yaml:"server_name"`                   // Name to verify, defaults to the host of Address
+	CAFile             string       `json:"ca_file" yaml:"ca_file"`                           // PEM bundle to verify against instead of the system roots
+	CertFile           string       `json:"cert_file" yaml:"cert_file"`                       // Client certificate for mTLS
+	KeyFile            string       `json:"key_file" yaml:"key_file"`                         // Client key for mTLS
+	InsecureSkipVerify bool         `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	Methods            []GRPCMethod `json:"methods" yaml:"methods"`                           // Unary methods resolved via server reflection
+}
+
+// GRPCMethod is a unary method called with a JSON encoded request
+type GRPCMethod struct {
+	Method  string `json:"method" yaml:"method"`   // e.g. "helloworld.Greeter/SayHello"
+	Request string `json:"request" yaml:"request"` // Request message in protobuf JSON, "{}" when empty
+}
+
+// STAMPConfig configures RFC 8762 STAMP (TWAMP-light compatible) measurements
+type STAMPConfig struct {
+	PacketCount      int           `json:"packet_count" yaml:"packet_count"`
+	PacketInterval   time.Duration `json:"packet_interval" yaml:"packet_interval"`
+	Timeout          time.Duration `json:"timeout" yaml:"timeout"`
+	ClockSynced      bool          `json:"clock_synced" yaml:"clock_synced"`         // Advertise a synchronized clock (S bit) so one-way delays are reported
+	ReflectorAddress string        `json:"reflector_address" yaml:"reflector_address"` // Listen address for the stamp_reflector collector
+}
+
+// TCPStatsConfig configures how the tcp_stats collector aggregates per-socket details
+type TCPStatsConfig struct {
+	RemotePrefixV4 int `json:"remote_prefix_v4" yaml:"remote_prefix_v4"` // Prefix length used to group IPv4 peers into subnets
+	RemotePrefixV6 int `json:"remote_prefix_v6" yaml:"remote_prefix_v6"` // Prefix length used to group IPv6 peers into subnets
+	MaxGroups      int `json:"max_groups" yaml:"max_groups"`             // Maximum number of local port/remote subnet groups reported
+}
+
+// NetstatConfig configures which kernel protocol counter sections the netstat collector reports
+type NetstatConfig struct {
+	Protocols []string `json:"protocols" yaml:"protocols"` // e.g. ip, icmp, tcp, udp, udplite, tcpext, ip6, udp6
+}
+
+// ConntrackConfig configures the conntrack collector
+type ConntrackConfig struct {
+	TopFlows int `json:"top_flows" yaml:"top_flows"` // Number of busiest protocol/destination flow groups to report; 0 (the default) skips flow enumeration
+}
+
+// SoftnetConfig configures the softnet collector
+type SoftnetConfig struct {
+	Interfaces  []string `json:"interfaces" yaml:"interfaces"`     // Interfaces to query via ethtool, empty means all non-loopback interfaces
+	DriverStats bool     `json:"driver_stats" yaml:"driver_stats"` // Report driver statistics that are not tied to a queue
+}
+
+// RoutesConfig configures the routes collector
+type RoutesConfig struct {
+	MaxEvents int `json:"max_events" yaml:"max_events"` // Maximum route change events per collection, further changes are summarized; 0 or less means the default of 100
+}
+
+// TLSConfig configures the tls collector
+type TLSConfig struct {
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	CAFile  string        `json:"ca_file" yaml:"ca_file"` // PEM bundle used for all targets, empty means the system roots
+	ALPN    []string      `json:"alpn" yaml:"alpn"`
+}
+
+// UDPConfig configures the udp collector
+type UDPConfig struct {
+	Timeout time.Duration `json:"timeout" yaml:"timeout"` // Time to wait for each response
+	Retries int           `json:"retries" yaml:"retries"` // Requests resent after a timeout before a probe fails
+}
+
+// NTPConfig configures the ntp collector
+type NTPConfig struct {
+	Servers []string      `json:"servers" yaml:"servers"` // host or host:port, empty means only the kernel clock state is reported
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	Retries int           `json:"retries" yaml:"retries"`
+}
+
+// GRPCConfig configures the grpc collector
+type GRPCConfig struct {
+	Timeout time.Duration `json:"timeout" yaml:"timeout"` // Deadline for connecting and for each RPC
+}
+
+// DNSConfig configures the dns collector, which queries every name against each of custom_targets.dns_servers
+type DNSConfig struct {
+	Names      []string      `json:"names" yaml:"names"`
+	RecordType string        `json:"record_type" yaml:"record_type"` // A, AAAA, CNAME, MX, NS or TXT
+	Timeout    time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// TracerouteConfig configures the traceroute collector
+type TracerouteConfig struct {
+	MaxHops int           `json:"max_hops" yaml:"max_hops"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"` // Time to wait for the reply from each hop
+}
+
+// BackendConfig configures how the agent authenticates to the backend and verifies it
+type BackendConfig struct {
+	Token              string   `json:"token" yaml:"token"`                               // Bearer token sent in the Authorization header, e.g. "${file:/run/secrets/agent-token}"
+	CAFile             string   `json:"ca_file" yaml:"ca_file"`                           // PEM bundle to verify the backend against instead of the system roots
+	CertFile           string   `json:"cert_file" yaml:"cert_file"`                       // Client certificate for mTLS, re-read on every connection
+	KeyFile            string   `json:"key_file" yaml:"key_file"`                         // Client key for mTLS
+	ServerName         string   `json:"server_name" yaml:"server_name"`                   // Name to verify, defaults to the host of backend_url
+	MinTLSVersion      string   `json:"min_tls_version" yaml:"min_tls_version"`           // 1.2 or 1.3
+	PinnedSHA256       []string `json:"pinned_sha256" yaml:"pinned_sha256"`               // SHA-256 of a public key (SPKI) in the backend's chain, base64 or hex
+	InsecureSkipVerify bool     `json:"insecure_skip_verify" yaml:"insecure_skip_verify"` // Skip chain verification; pins are still checked
+}
+
+// ControlConfig configures the local control API used by the status command
+type ControlConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Address string `json:"address" yaml:"address"` // unix:/path/to/agent.sock, or a loopback host:port
+	Token   string `json:"token" yaml:"token"`     // Bearer token required by the API, mandatory on a TCP address
+}
+
+// HealthConfig configures the /healthz and /readyz endpoints used by orchestrator probes
+type HealthConfig struct {
+	Address       string        `json:"address" yaml:"address"`                 // Extra listener for the probes, e.g. ":8086"; empty serves them on the control API only
+	MaxCollectAge time.Duration `json:"max_collect_age" yaml:"max_collect_age"` // Unhealthy when no collection cycle completed for this long
+	MaxSendAge    time.Duration `json:"max_send_age" yaml:"max_send_age"`       // Not ready when disconnected and no batch was sent for this long
 }
 
 // MetricBatch represents a batch of metrics to be transmitted
@@ -91,4 +342,5 @@ type MetricBatch struct {
 	Timestamp time.Time `json:"timestamp"`
 	Metrics   []Metric  `json:"metrics"`
 	Location  CloudLocation `json:"location"`
+	ClockOffsetMs *float64  `json:"clock_offset_ms,omitempty"` // NTP time minus agent time when measured, so the backend can correct timestamps
 } 
\ No newline at end of file